@@ -13,6 +13,7 @@ import { Log } from "../../util/log"
 import { FileWatcher } from "../../file/watch"
 import { Mode } from "../../session/mode"
 import { Ide } from "../../ide"
+import { Permission } from "../../permission"
 
 export const TuiCommand = cmd({
   command: "$0 [project]",
@@ -37,6 +38,26 @@ export const TuiCommand = cmd({
         type: "string",
         describe: "mode to use",
       })
+      .option("session", {
+        alias: ["s"],
+        type: "string",
+        describe: "session id to continue",
+      })
+      .option("continue", {
+        alias: ["c"],
+        type: "boolean",
+        describe: "continue the last session",
+      })
+      .option("non-interactive", {
+        type: "boolean",
+        describe: "run the prompt without the tui and exit when the session is idle",
+      })
+      .option("format", {
+        type: "string",
+        choices: ["text", "json"],
+        describe: "output format with --non-interactive",
+        default: "text",
+      })
       .option("port", {
         type: "number",
         describe: "port to listen on",
@@ -59,6 +80,9 @@ export const TuiCommand = cmd({
       }
       const result = await bootstrap({ cwd }, async (app) => {
         FileWatcher.init()
+        // the tui answers permission requests in a dialog, there is no one to
+        // answer them in a non-interactive run
+        if (!args["non-interactive"]) Permission.enable()
         const providers = await Provider.list()
         if (Object.keys(providers).length === 0) {
           return "needs_provider"
@@ -95,6 +119,9 @@ export const TuiCommand = cmd({
             ...(args.model ? ["--model", args.model] : []),
             ...(args.prompt ? ["--prompt", args.prompt] : []),
             ...(args.mode ? ["--mode", args.mode] : []),
+            ...(args["non-interactive"] ? ["--non-interactive", "--format", args.format] : []),
+            ...(args.session ? ["--session", args.session] : []),
+            ...(args.continue ? ["--continue"] : []),
           ],
           cwd,
           stdout: "inherit",
@@ -136,9 +136,11 @@ export namespace Config {
     .object({
       leader: z.string().optional().default("ctrl+x").describe("Leader key for keybind combinations"),
       app_help: z.string().optional().default("<leader>h").describe("Show help dialog"),
+      keybinds_list: z.string().optional().describe("List and edit keybinds"),
       switch_mode: z.string().optional().default("tab").describe("Next mode"),
       switch_mode_reverse: z.string().optional().default("shift+tab").describe("Previous Mode"),
       editor_open: z.string().optional().default("<leader>e").describe("Open external editor"),
+      prompt_template: z.string().optional().describe("Use a prompt template"),
       session_export: z.string().optional().default("<leader>x").describe("Export session to editor"),
       session_new: z.string().optional().default("<leader>n").describe("Create a new session"),
       session_list: z.string().optional().default("<leader>l").describe("List all sessions"),
@@ -146,18 +148,32 @@ export namespace Config {
       session_unshare: z.string().optional().default("none").describe("Unshare current session"),
       session_interrupt: z.string().optional().default("esc").describe("Interrupt current session"),
       session_compact: z.string().optional().default("<leader>c").describe("Compact the session"),
+      session_tab_next: z.string().optional().default("<leader>right").describe("Switch to the next session tab"),
+      session_tab_previous: z
+        .string()
+        .optional()
+        .default("<leader>left")
+        .describe("Switch to the previous session tab"),
+      session_tab_close: z.string().optional().default("<leader>k").describe("Close the current session tab"),
       tool_details: z.string().optional().default("<leader>d").describe("Toggle tool details"),
       model_list: z.string().optional().default("<leader>m").describe("List available models"),
       theme_list: z.string().optional().default("<leader>t").describe("List available themes"),
       file_list: z.string().optional().default("<leader>f").describe("List files"),
       file_close: z.string().optional().default("esc").describe("Close file"),
       file_search: z.string().optional().default("<leader>/").describe("Search file"),
+      file_grep: z.string().optional().default("<leader>g").describe("Search project files"),
+      changes_toggle: z.string().optional().default("<leader>b").describe("Toggle changed files"),
       file_diff_toggle: z.string().optional().default("<leader>v").describe("Split/unified diff"),
+      diagnostics_toggle: z.string().optional().default("<leader>w").describe("Toggle diagnostics"),
       project_init: z.string().optional().default("<leader>i").describe("Create/update AGENTS.md"),
       input_clear: z.string().optional().default("ctrl+c").describe("Clear input field"),
       input_paste: z.string().optional().default("ctrl+v").describe("Paste from clipboard"),
       input_submit: z.string().optional().default("enter").describe("Submit input"),
       input_newline: z.string().optional().default("shift+enter,ctrl+j").describe("Insert newline in input"),
+      input_undo: z.string().optional().default("ctrl+z").describe("Undo the last edit in input"),
+      input_redo: z.string().optional().default("ctrl+shift+z").describe("Redo the last undone edit in input"),
+      input_history: z.string().optional().default("ctrl+r").describe("Search prompt history"),
+      input_vim_toggle: z.string().optional().describe("Toggle vim mode in input"),
       messages_page_up: z.string().optional().default("pgup").describe("Scroll messages up by one page"),
       messages_page_down: z.string().optional().default("pgdown").describe("Scroll messages down by one page"),
       messages_half_page_up: z.string().optional().default("ctrl+alt+u").describe("Scroll messages up by half page"),
@@ -168,10 +184,13 @@ export namespace Config {
         .describe("Scroll messages down by half page"),
       messages_previous: z.string().optional().default("ctrl+up").describe("Navigate to previous message"),
       messages_next: z.string().optional().default("ctrl+down").describe("Navigate to next message"),
+      messages_block_toggle: z.string().optional().default("<leader>o").describe("Expand or collapse tool output"),
       messages_first: z.string().optional().default("ctrl+g").describe("Navigate to first message"),
       messages_last: z.string().optional().default("ctrl+alt+g").describe("Navigate to last message"),
       messages_layout_toggle: z.string().optional().default("<leader>p").describe("Toggle layout"),
       messages_copy: z.string().optional().default("<leader>y").describe("Copy message"),
+      messages_copy_code: z.string().optional().default("<leader>j").describe("Copy code block"),
+      messages_actions: z.string().optional().default("<leader>a").describe("Show message actions"),
       messages_revert: z.string().optional().default("none").describe("@deprecated use messages_undo. Revert message"),
       messages_undo: z.string().optional().default("<leader>u").describe("Undo message"),
       messages_redo: z.string().optional().default("<leader>r").describe("Redo message"),
@@ -248,6 +267,21 @@ export namespace Config {
         .optional()
         .describe("Custom provider configurations and model overrides"),
       mcp: z.record(z.string(), Mcp).optional().describe("MCP (Model Context Protocol) server configurations"),
+      commands: z
+        .record(
+          z.string(),
+          z
+            .object({
+              description: z.string().optional(),
+              keybind: z.string().optional(),
+              prompt: z.string().optional().describe("Prompt to send, {{args}} is replaced with the arguments"),
+              shell: z.string().optional().describe("Shell command to run, its output is attached to the prompt"),
+              commands: z.array(z.string()).optional().describe("Built-in commands to run one after another"),
+            })
+            .strict(),
+        )
+        .optional()
+        .describe("Custom slash commands, keyed by their trigger, see https://opencode.ai/docs/commands"),
       instructions: z.array(z.string()).optional().describe("Additional instruction files or patterns to include"),
       layout: Layout.optional().describe("@deprecated Always uses stretch layout."),
       experimental: z
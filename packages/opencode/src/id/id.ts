@@ -7,6 +7,7 @@ export namespace Identifier {
     message: "msg",
     user: "usr",
     part: "prt",
+    permission: "per",
   } as const
 
   export function schema(prefix: keyof typeof prefixes) {
@@ -30,6 +30,7 @@ export namespace LSPClient {
       z.object({
         serverID: z.string(),
         path: z.string(),
+        diagnostics: z.array(z.any()),
       }),
     ),
   }
@@ -53,7 +54,7 @@ export namespace LSPClient {
       const exists = diagnostics.has(path)
       diagnostics.set(path, params.diagnostics)
       if (!exists && input.serverID === "typescript") return
-      Bus.publish(Event.Diagnostics, { path, serverID: input.serverID })
+      Bus.publish(Event.Diagnostics, { path, serverID: input.serverID, diagnostics: params.diagnostics })
     })
     connection.onRequest("window/workDoneProgress/create", (params) => {
       l.info("window/workDoneProgress/create", params)
@@ -2,6 +2,7 @@ import { App } from "../app/app"
 import { z } from "zod"
 import { Bus } from "../bus"
 import { Log } from "../util/log"
+import { Identifier } from "../id/id"
 
 export namespace Permission {
   const log = Log.create({ service: "permission" })
@@ -9,6 +10,7 @@ export namespace Permission {
   export const Info = z
     .object({
       id: z.string(),
+      type: z.string(),
       sessionID: z.string(),
       title: z.string(),
       metadata: z.record(z.any()),
@@ -40,13 +42,16 @@ export namespace Permission {
 
       const approved: {
         [sessionID: string]: {
-          [permissionID: string]: Info
+          [type: string]: Info
         }
       } = {}
 
       return {
         pending,
         approved,
+        // only a client that can answer, like the tui, asks for permissions,
+        // everything is allowed otherwise
+        enabled: false,
       }
     },
     async (state) => {
@@ -58,27 +63,32 @@ export namespace Permission {
     },
   )
 
-  export function ask(input: {
-    id: Info["id"]
+  export function enable() {
+    state().enabled = true
+  }
+
+  export async function ask(input: {
+    type: Info["type"]
     sessionID: Info["sessionID"]
     title: Info["title"]
     metadata: Info["metadata"]
   }) {
-    return
-    const { pending, approved } = state()
+    const { pending, approved, enabled } = state()
+    if (!enabled) return
     log.info("asking", {
       sessionID: input.sessionID,
-      permissionID: input.id,
+      type: input.type,
     })
-    if (approved[input.sessionID]?.[input.id]) {
+    if (approved[input.sessionID]?.[input.type]) {
       log.info("previously approved", {
         sessionID: input.sessionID,
-        permissionID: input.id,
+        type: input.type,
       })
       return
     }
     const info: Info = {
-      id: input.id,
+      id: Identifier.ascending("permission"),
+      type: input.type,
       sessionID: input.sessionID,
       title: input.title,
       metadata: input.metadata,
@@ -88,18 +98,11 @@ export namespace Permission {
     }
     pending[input.sessionID] = pending[input.sessionID] || {}
     return new Promise<void>((resolve, reject) => {
-      pending[input.sessionID][input.id] = {
+      pending[input.sessionID][info.id] = {
         info,
         resolve,
         reject,
       }
-      setTimeout(() => {
-        respond({
-          sessionID: input.sessionID,
-          permissionID: input.id,
-          response: "always",
-        })
-      }, 1000)
       Bus.publish(Event.Updated, info)
     })
   }
@@ -112,17 +115,26 @@ export namespace Permission {
     log.info("response", input)
     const { pending, approved } = state()
     const match = pending[input.sessionID]?.[input.permissionID]
-    if (!match) return
+    if (!match) return false
     delete pending[input.sessionID][input.permissionID]
     if (input.response === "reject") {
       match.reject(new RejectedError(input.sessionID, input.permissionID))
-      return
+      return true
     }
     match.resolve()
     if (input.response === "always") {
       approved[input.sessionID] = approved[input.sessionID] || {}
-      approved[input.sessionID][input.permissionID] = match.info
+      approved[input.sessionID][match.info.type] = match.info
+    }
+    return true
+  }
+
+  export function clear(sessionID: string) {
+    const { pending } = state()
+    for (const item of Object.values(pending[sessionID] ?? {})) {
+      item.reject(new RejectedError(sessionID, item.info.id))
     }
+    delete pending[sessionID]
   }
 
   export class RejectedError extends Error {
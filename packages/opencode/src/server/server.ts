@@ -18,6 +18,7 @@ import { LSP } from "../lsp"
 import { MessageV2 } from "../session/message-v2"
 import { Mode } from "../session/mode"
 import { callTui, TuiRoute } from "./tui"
+import { Permission } from "../permission"
 
 const ERRORS = {
   400: {
@@ -259,6 +260,90 @@ export namespace Server {
           return c.json(true)
         },
       )
+      .patch(
+        "/session/:id",
+        describeRoute({
+          description: "Update session properties",
+          responses: {
+            200: {
+              description: "Successfully updated session",
+              content: {
+                "application/json": {
+                  schema: resolver(Session.Info),
+                },
+              },
+            },
+            404: {
+              description: "Session not found",
+              content: {
+                "application/json": {
+                  schema: resolver(NamedError.Unknown.Schema),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+          }),
+        ),
+        zValidator(
+          "json",
+          z.object({
+            title: z.string().optional(),
+          }),
+        ),
+        async (c) => {
+          const sessionID = c.req.valid("param").id
+          const updates = c.req.valid("json")
+          const existing = await Session.get(sessionID).catch(() => undefined)
+          if (!existing) {
+            return c.json(new NamedError.Unknown({ message: `Session ${sessionID} not found` }).toObject(), {
+              status: 404,
+            })
+          }
+          const session = await Session.update(sessionID, (draft) => {
+            if (updates.title !== undefined) draft.title = updates.title
+          })
+          return c.json(session)
+        },
+      )
+      .post(
+        "/session/:id/fork",
+        describeRoute({
+          description: "Fork a session at a message into a new session",
+          responses: {
+            200: {
+              description: "The forked session",
+              content: {
+                "application/json": {
+                  schema: resolver(Session.Info),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string().openapi({ description: "Session ID" }),
+          }),
+        ),
+        zValidator(
+          "json",
+          z.object({
+            messageID: z.string(),
+          }),
+        ),
+        async (c) => {
+          const sessionID = c.req.valid("param").id
+          const body = c.req.valid("json")
+          const session = await Session.fork({ ...body, sessionID })
+          return c.json(session)
+        },
+      )
       .post(
         "/session/:id/init",
         describeRoute({
@@ -529,6 +614,43 @@ export namespace Server {
           return c.json(session)
         },
       )
+      .post(
+        "/session/:id/permissions/:permissionID",
+        describeRoute({
+          description: "Respond to a permission request",
+          responses: {
+            200: {
+              description: "Permission processed successfully",
+              content: {
+                "application/json": {
+                  schema: resolver(z.boolean()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+            permissionID: z.string(),
+          }),
+        ),
+        zValidator("json", z.object({ response: z.enum(["once", "always", "reject"]) })),
+        async (c) => {
+          const params = c.req.valid("param")
+          const id = params.id
+          const permissionID = params.permissionID
+          const { response } = c.req.valid("json")
+          return c.json(
+            Permission.respond({
+              sessionID: id,
+              permissionID,
+              response,
+            }),
+          )
+        },
+      )
       .get(
         "/config/providers",
         describeRoute({
@@ -37,6 +37,7 @@ import { SystemPrompt } from "./system"
 import { FileTime } from "../file/time"
 import { MessageV2 } from "./message-v2"
 import { Mode } from "./mode"
+import { Permission } from "../permission"
 import { LSP } from "../lsp"
 import { ReadTool } from "../tool/read"
 import { mergeDeep, pipe, splitWhen } from "remeda"
@@ -287,10 +288,35 @@ export namespace Session {
     return result
   }
 
+  export async function fork(input: { sessionID: string; messageID: string }) {
+    const original = await get(input.sessionID)
+    await getMessage(input.sessionID, input.messageID)
+    const session = await create()
+    for (const msg of await messages(input.sessionID)) {
+      if (msg.info.id > input.messageID) break
+      const id = Identifier.ascending("message")
+      const info = { ...msg.info, id, sessionID: session.id }
+      await updateMessage(info)
+      for (const part of msg.parts) {
+        await updatePart({
+          ...part,
+          id: Identifier.ascending("part"),
+          messageID: id,
+          sessionID: session.id,
+        })
+      }
+    }
+    const result = await update(session.id, (draft) => {
+      draft.title = original.title + " (fork)"
+    })
+    return result!
+  }
+
   export function abort(sessionID: string) {
     const controller = state().pending.get(sessionID)
     if (!controller) return false
     controller.abort()
+    Permission.clear(sessionID)
     state().pending.delete(sessionID)
     return true
   }
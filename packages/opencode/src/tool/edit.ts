@@ -35,7 +35,7 @@ export const EditTool = Tool.define("edit", {
     const filepath = path.isAbsolute(params.filePath) ? params.filePath : path.join(app.path.cwd, params.filePath)
 
     await Permission.ask({
-      id: "edit",
+      type: "edit",
       sessionID: ctx.sessionID,
       title: "Edit this file: " + filepath,
       metadata: {
@@ -24,7 +24,7 @@ export const WriteTool = Tool.define("write", {
     if (exists) await FileTime.assert(ctx.sessionID, filepath)
 
     await Permission.ask({
-      id: "write",
+      type: "write",
       sessionID: ctx.sessionID,
       title: exists ? "Overwrite this file: " + filepath : "Create new file: " + filepath,
       metadata: {
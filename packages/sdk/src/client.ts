@@ -61,14 +61,18 @@ import {
   SessionAbortResponse,
   SessionChatParams,
   SessionDeleteResponse,
+  SessionForkParams,
   SessionInitParams,
   SessionInitResponse,
   SessionListResponse,
   SessionMessagesResponse,
   SessionResource,
+  SessionRespondPermissionParams,
+  SessionRespondPermissionResponse,
   SessionRevertParams,
   SessionSummarizeParams,
   SessionSummarizeResponse,
+  SessionUpdateParams,
   SnapshotPart,
   StepFinishPart,
   StepStartPart,
@@ -842,11 +846,15 @@ export declare namespace Opencode {
     type SessionAbortResponse as SessionAbortResponse,
     type SessionInitResponse as SessionInitResponse,
     type SessionMessagesResponse as SessionMessagesResponse,
+    type SessionRespondPermissionResponse as SessionRespondPermissionResponse,
     type SessionSummarizeResponse as SessionSummarizeResponse,
     type SessionChatParams as SessionChatParams,
+    type SessionForkParams as SessionForkParams,
     type SessionInitParams as SessionInitParams,
+    type SessionRespondPermissionParams as SessionRespondPermissionParams,
     type SessionRevertParams as SessionRevertParams,
     type SessionSummarizeParams as SessionSummarizeParams,
+    type SessionUpdateParams as SessionUpdateParams,
   };
 
   export {
@@ -36,6 +36,12 @@ export interface Config {
    */
   autoupdate?: boolean;
 
+  /**
+   * Custom slash commands, keyed by their trigger, see
+   * https://opencode.ai/docs/commands
+   */
+  commands?: { [key: string]: Config.Command };
+
   /**
    * Disable providers that are loaded automatically
    */
@@ -129,6 +135,27 @@ export namespace Config {
     description: string;
   }
 
+  export interface Command {
+    /**
+     * Built-in commands to run one after another
+     */
+    commands?: Array<string>;
+
+    description?: string;
+
+    keybind?: string;
+
+    /**
+     * Prompt to send, {{args}} is replaced with the arguments
+     */
+    prompt?: string;
+
+    /**
+     * Shell command to run, its output is attached to the prompt
+     */
+    shell?: string;
+  }
+
   export interface Experimental {
     hook?: Experimental.Hook;
   }
@@ -244,6 +271,16 @@ export interface KeybindsConfig {
    */
   app_help: string;
 
+  /**
+   * Toggle changed files
+   */
+  changes_toggle: string;
+
+  /**
+   * Toggle diagnostics
+   */
+  diagnostics_toggle: string;
+
   /**
    * Open external editor
    */
@@ -259,6 +296,11 @@ export interface KeybindsConfig {
    */
   file_diff_toggle: string;
 
+  /**
+   * Search project files
+   */
+  file_grep: string;
+
   /**
    * List files
    */
@@ -274,6 +316,11 @@ export interface KeybindsConfig {
    */
   input_clear: string;
 
+  /**
+   * Search prompt history
+   */
+  input_history: string;
+
   /**
    * Insert newline in input
    */
@@ -284,21 +331,46 @@ export interface KeybindsConfig {
    */
   input_paste: string;
 
+  /**
+   * Redo the last undone edit in input
+   */
+  input_redo: string;
+
   /**
    * Submit input
    */
   input_submit: string;
 
+  /**
+   * Undo the last edit in input
+   */
+  input_undo: string;
+
   /**
    * Leader key for keybind combinations
    */
   leader: string;
 
+  /**
+   * Show message actions
+   */
+  messages_actions: string;
+
+  /**
+   * Expand or collapse tool output
+   */
+  messages_block_toggle: string;
+
   /**
    * Copy message
    */
   messages_copy: string;
 
+  /**
+   * Copy code block
+   */
+  messages_copy_code: string;
+
   /**
    * Navigate to first message
    */
@@ -399,6 +471,21 @@ export interface KeybindsConfig {
    */
   session_share: string;
 
+  /**
+   * Close the current session tab
+   */
+  session_tab_close: string;
+
+  /**
+   * Switch to the next session tab
+   */
+  session_tab_next: string;
+
+  /**
+   * Switch to the previous session tab
+   */
+  session_tab_previous: string;
+
   /**
    * Unshare current session
    */
@@ -423,6 +510,21 @@ export interface KeybindsConfig {
    * Toggle tool details
    */
   tool_details: string;
+
+  /**
+   * Toggle vim mode in input
+   */
+  input_vim_toggle?: string;
+
+  /**
+   * List and edit keybinds
+   */
+  keybinds_list?: string;
+
+  /**
+   * Use a prompt template
+   */
+  prompt_template?: string;
 }
 
 export interface McpLocalConfig {
@@ -55,6 +55,8 @@ export namespace EventListResponse {
 
   export namespace EventLspClientDiagnostics {
     export interface Properties {
+      diagnostics: Array<unknown>;
+
       path: string;
 
       serverID: string;
@@ -146,6 +148,8 @@ export namespace EventListResponse {
       time: Properties.Time;
 
       title: string;
+
+      type: string;
     }
 
     export namespace Properties {
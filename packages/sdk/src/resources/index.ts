@@ -67,11 +67,15 @@ export {
   type SessionAbortResponse,
   type SessionInitResponse,
   type SessionMessagesResponse,
+  type SessionRespondPermissionResponse,
   type SessionSummarizeResponse,
   type SessionChatParams,
+  type SessionForkParams,
   type SessionInitParams,
+  type SessionRespondPermissionParams,
   type SessionRevertParams,
   type SessionSummarizeParams,
+  type SessionUpdateParams,
 } from './session';
 export {
   Tui,
@@ -15,6 +15,13 @@ export class SessionResource extends APIResource {
     return this._client.post('/session', options);
   }
 
+  /**
+   * Update session properties
+   */
+  update(id: string, body: SessionUpdateParams, options?: RequestOptions): APIPromise<Session> {
+    return this._client.patch(path`/session/${id}`, { body, ...options });
+  }
+
   /**
    * List all sessions
    */
@@ -43,6 +50,13 @@ export class SessionResource extends APIResource {
     return this._client.post(path`/session/${id}/message`, { body, ...options });
   }
 
+  /**
+   * Fork a session at a message into a new session
+   */
+  fork(id: string, body: SessionForkParams, options?: RequestOptions): APIPromise<Session> {
+    return this._client.post(path`/session/${id}/fork`, { body, ...options });
+  }
+
   /**
    * Analyze the app and create an AGENTS.md file
    */
@@ -57,6 +71,18 @@ export class SessionResource extends APIResource {
     return this._client.get(path`/session/${id}/message`, options);
   }
 
+  /**
+   * Respond to a permission request
+   */
+  respondPermission(
+    permissionID: string,
+    params: SessionRespondPermissionParams,
+    options?: RequestOptions,
+  ): APIPromise<SessionRespondPermissionResponse> {
+    const { id, ...body } = params;
+    return this._client.post(path`/session/${id}/permissions/${permissionID}`, { body, ...options });
+  }
+
   /**
    * Revert a message
    */
@@ -530,6 +556,8 @@ export namespace SessionMessagesResponse {
   }
 }
 
+export type SessionRespondPermissionResponse = boolean;
+
 export type SessionSummarizeResponse = boolean;
 
 export interface SessionChatParams {
@@ -548,6 +576,10 @@ export interface SessionChatParams {
   tools?: { [key: string]: boolean };
 }
 
+export interface SessionForkParams {
+  messageID: string;
+}
+
 export interface SessionInitParams {
   messageID: string;
 
@@ -556,6 +588,18 @@ export interface SessionInitParams {
   providerID: string;
 }
 
+export interface SessionRespondPermissionParams {
+  /**
+   * Path param:
+   */
+  id: string;
+
+  /**
+   * Body param:
+   */
+  response: 'once' | 'always' | 'reject';
+}
+
 export interface SessionRevertParams {
   messageID: string;
 
@@ -568,6 +612,10 @@ export interface SessionSummarizeParams {
   providerID: string;
 }
 
+export interface SessionUpdateParams {
+  title?: string;
+}
+
 export declare namespace SessionResource {
   export {
     type AssistantMessage as AssistantMessage,
@@ -596,10 +644,14 @@ export declare namespace SessionResource {
     type SessionAbortResponse as SessionAbortResponse,
     type SessionInitResponse as SessionInitResponse,
     type SessionMessagesResponse as SessionMessagesResponse,
+    type SessionRespondPermissionResponse as SessionRespondPermissionResponse,
     type SessionSummarizeResponse as SessionSummarizeResponse,
     type SessionChatParams as SessionChatParams,
+    type SessionForkParams as SessionForkParams,
     type SessionInitParams as SessionInitParams,
+    type SessionRespondPermissionParams as SessionRespondPermissionParams,
     type SessionRevertParams as SessionRevertParams,
     type SessionSummarizeParams as SessionSummarizeParams,
+    type SessionUpdateParams as SessionUpdateParams,
   };
 }
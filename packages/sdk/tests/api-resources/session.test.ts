@@ -17,6 +17,18 @@ describe('resource session', () => {
     expect(dataAndResponse.response).toBe(rawResponse);
   });
 
+  // skipped: tests are disabled for the time being
+  test.skip('update', async () => {
+    const responsePromise = client.session.update('id', {});
+    const rawResponse = await responsePromise.asResponse();
+    expect(rawResponse).toBeInstanceOf(Response);
+    const response = await responsePromise;
+    expect(response).not.toBeInstanceOf(Response);
+    const dataAndResponse = await responsePromise.withResponse();
+    expect(dataAndResponse.data).toBe(response);
+    expect(dataAndResponse.response).toBe(rawResponse);
+  });
+
   // skipped: tests are disabled for the time being
   test.skip('list', async () => {
     const responsePromise = client.session.list();
@@ -82,6 +94,23 @@ describe('resource session', () => {
     });
   });
 
+  // skipped: tests are disabled for the time being
+  test.skip('fork: only required params', async () => {
+    const responsePromise = client.session.fork('id', { messageID: 'messageID' });
+    const rawResponse = await responsePromise.asResponse();
+    expect(rawResponse).toBeInstanceOf(Response);
+    const response = await responsePromise;
+    expect(response).not.toBeInstanceOf(Response);
+    const dataAndResponse = await responsePromise.withResponse();
+    expect(dataAndResponse.data).toBe(response);
+    expect(dataAndResponse.response).toBe(rawResponse);
+  });
+
+  // skipped: tests are disabled for the time being
+  test.skip('fork: required and optional params', async () => {
+    const response = await client.session.fork('id', { messageID: 'messageID' });
+  });
+
   // skipped: tests are disabled for the time being
   test.skip('init: only required params', async () => {
     const responsePromise = client.session.init('id', {
@@ -119,6 +148,23 @@ describe('resource session', () => {
     expect(dataAndResponse.response).toBe(rawResponse);
   });
 
+  // skipped: tests are disabled for the time being
+  test.skip('respondPermission: only required params', async () => {
+    const responsePromise = client.session.respondPermission('permissionID', { id: 'id', response: 'once' });
+    const rawResponse = await responsePromise.asResponse();
+    expect(rawResponse).toBeInstanceOf(Response);
+    const response = await responsePromise;
+    expect(response).not.toBeInstanceOf(Response);
+    const dataAndResponse = await responsePromise.withResponse();
+    expect(dataAndResponse.data).toBe(response);
+    expect(dataAndResponse.response).toBe(rawResponse);
+  });
+
+  // skipped: tests are disabled for the time being
+  test.skip('respondPermission: required and optional params', async () => {
+    const response = await client.session.respondPermission('permissionID', { id: 'id', response: 'once' });
+  });
+
   // skipped: tests are disabled for the time being
   test.skip('revert: only required params', async () => {
     const responsePromise = client.session.revert('id', { messageID: 'msg' });
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	flag "github.com/spf13/pflag"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+	"github.com/sst/opencode-sdk-go/packages/ssestream"
+	"github.com/sst/opencode/internal/api"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/clipboard"
+	"github.com/sst/opencode/internal/headless"
+	"github.com/sst/opencode/internal/tui"
+	"github.com/sst/opencode/internal/util"
+)
+
+var Version = "dev"
+
+func main() {
+	version := Version
+	if version != "dev" && !strings.HasPrefix(Version, "v") {
+		version = "v" + Version
+	}
+
+	var model *string = flag.String("model", "", "model to begin with")
+	var prompt *string = flag.String("prompt", "", "prompt to begin with")
+	var mode *string = flag.String("mode", "", "mode to begin with")
+	var nonInteractive *bool = flag.Bool("non-interactive", false, "run the prompt without the TUI and exit when the session is idle")
+	var format *string = flag.String("format", string(headless.FormatText), "output format for --non-interactive, text or json")
+	var session *string = flag.String("session", "", "session to continue, by ID")
+	var continueLast *bool = flag.Bool("continue", false, "continue the most recently updated session")
+	flag.Parse()
+
+	if !headless.Format(*format).IsKnown() {
+		fmt.Fprintf(os.Stderr, "unknown format %q, expected text or json\n", *format)
+		os.Exit(1)
+	}
+	if *session != "" && *continueLast {
+		fmt.Fprintln(os.Stderr, "--session and --continue can't be used together")
+		os.Exit(1)
+	}
+	resume := *session != "" || *continueLast
+
+	url := os.Getenv("OPENCODE_SERVER")
+
+	appInfoStr := os.Getenv("OPENCODE_APP_INFO")
+	var appInfo opencode.App
+	err := json.Unmarshal([]byte(appInfoStr), &appInfo)
+	if err != nil {
+		slog.Error("Failed to unmarshal app info", "error", err)
+		os.Exit(1)
+	}
+
+	modesStr := os.Getenv("OPENCODE_MODES")
+	var modes []opencode.Mode
+	err = json.Unmarshal([]byte(modesStr), &modes)
+	if err != nil {
+		slog.Error("Failed to unmarshal modes", "error", err)
+		os.Exit(1)
+	}
+
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		slog.Error("Failed to stat stdin", "error", err)
+		os.Exit(1)
+	}
+
+	// Check if there's data piped to stdin
+	if (stat.Mode() & os.ModeCharDevice) == 0 {
+		stdin, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			slog.Error("Failed to read stdin", "error", err)
+			os.Exit(1)
+		}
+		stdinContent := strings.TrimSpace(string(stdin))
+		if stdinContent != "" {
+			if prompt == nil || *prompt == "" {
+				prompt = &stdinContent
+			} else {
+				combined := *prompt + "\n" + stdinContent
+				prompt = &combined
+			}
+		}
+	}
+
+	httpClient := opencode.NewClient(
+		option.WithBaseURL(url),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	apiHandler := util.NewAPILogHandler(ctx, httpClient, "tui", slog.LevelDebug)
+	logger := slog.New(apiHandler)
+	slog.SetDefault(logger)
+
+	slog.Debug("TUI launched", "app", appInfoStr, "modes", modesStr)
+
+	if *nonInteractive {
+		os.Exit(runHeadless(ctx, version, appInfo, modes, httpClient, model, prompt, mode, *format, resume, *session))
+	}
+
+	go func() {
+		err = clipboard.Init()
+		if err != nil {
+			slog.Error("Failed to initialize clipboard", "error", err)
+		}
+	}()
+
+	// Create main context for the application
+	app_, err := app.New(ctx, version, appInfo, modes, httpClient, model, prompt, mode)
+	if err != nil {
+		panic(err)
+	}
+	if resume {
+		if err := app_.ResumeSession(ctx, *session); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+	}
+
+	tuiModel := tui.NewModel(app_).(*tui.Model)
+	program := tea.NewProgram(
+		tuiModel,
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+
+	// Set up signal handling for graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+
+	go app.SubscribeEvents(
+		ctx,
+		func(ctx context.Context) *ssestream.Stream[opencode.EventListResponse] {
+			return httpClient.Event.ListStreaming(ctx)
+		},
+		program.Send,
+	)
+
+	go api.Start(ctx, program, httpClient)
+
+	// Handle signals in a separate goroutine
+	go func() {
+		sig := <-sigChan
+		slog.Info("Received signal, shutting down gracefully", "signal", sig)
+		tuiModel.Cleanup()
+		program.Quit()
+	}()
+
+	// Run the TUI
+	result, err := program.Run()
+	if err != nil {
+		slog.Error("TUI error", "error", err)
+	}
+
+	tuiModel.Cleanup()
+	slog.Info("TUI exited", "result", result)
+}
+
+// runHeadless sends the prompt without starting the TUI and returns the exit
+// code for the process
+func runHeadless(
+	ctx context.Context,
+	version string,
+	appInfo opencode.App,
+	modes []opencode.Mode,
+	httpClient *opencode.Client,
+	model *string,
+	prompt *string,
+	mode *string,
+	format string,
+	resume bool,
+	sessionID string,
+) int {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	app_, err := app.New(ctx, version, appInfo, modes, httpClient, model, prompt, mode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if resume {
+		if err := app_.ResumeSession(ctx, sessionID); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+	}
+
+	err = headless.Run(ctx, app_, *prompt, headless.Format(format), os.Stdout)
+	if err != nil {
+		slog.Error("Headless run failed", "error", err)
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
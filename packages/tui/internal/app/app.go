@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,12 +11,14 @@ import (
 	"log/slog"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/fsnotify/fsnotify"
 	"github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/clipboard"
 	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/components/toast"
 	"github.com/sst/opencode/internal/id"
 	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/templates"
 	"github.com/sst/opencode/internal/theme"
 	"github.com/sst/opencode/internal/util"
 )
@@ -40,12 +43,25 @@ type App struct {
 	Model            *opencode.Model
 	Session          *opencode.Session
 	Messages         []Message
+	Tabs             []*Tab
+	ActiveTab        int
+	Diagnostics      *Diagnostics
+	Templates        []templates.Template
 	Commands         commands.CommandRegistry
 	InitialModel     *string
 	InitialPrompt    *string
 	IntitialMode     *string
 	compactCancel    context.CancelFunc
+	budget           budgetTracker
 	IsLeaderSequence bool
+	// KeySequence holds the keys pressed so far of a keybinding with several
+	// keys, after the leader key if IsLeaderSequence is set
+	KeySequence []string
+	// customWatcher watches the directories of the custom commands
+	customWatcher *fsnotify.Watcher
+	// summaries caches the summaries of the sessions listed in the session
+	// dialog
+	summaries summaryCache
 }
 
 type SessionCreatedMsg = struct {
@@ -65,6 +81,10 @@ type ModelSelectedMsg struct {
 	Model    opencode.Model
 }
 type SessionClearedMsg struct{}
+type SessionResyncedMsg struct {
+	SessionID string
+	Messages  []Message
+}
 type CompactSessionMsg struct{}
 type SendPrompt = Prompt
 type SetEditorContentMsg struct {
@@ -106,6 +126,7 @@ func New(
 	if appState.ModeModel == nil {
 		appState.ModeModel = make(map[string]ModeModel)
 	}
+	appState.seedHistory(appInfo.Path.Root)
 
 	if configInfo.Theme != "" {
 		appState.Theme = configInfo.Theme
@@ -160,6 +181,14 @@ func New(
 
 	slog.Debug("Loaded config", "config", configInfo)
 
+	promptTemplates := templates.LoadFromDirectories(
+		appInfo.Path.Config,
+		appInfo.Path.Root,
+		appInfo.Path.Cwd,
+	)
+
+	session := &opencode.Session{}
+	messages := []Message{}
 	app := &App{
 		Info:          appInfo,
 		Modes:         modes,
@@ -170,8 +199,11 @@ func New(
 		Client:        httpClient,
 		ModeIndex:     modeIndex,
 		Mode:          mode,
-		Session:       &opencode.Session{},
-		Messages:      []Message{},
+		Session:       session,
+		Messages:      messages,
+		Tabs:          []*Tab{{Session: session, Messages: messages}},
+		Diagnostics:   NewDiagnostics(),
+		Templates:     promptTemplates,
 		Commands:      commands.LoadFromConfig(configInfo),
 		InitialModel:  initialModel,
 		InitialPrompt: initialPrompt,
@@ -182,12 +214,16 @@ func New(
 		delete(app.Commands, commands.MessagesUndoCommand)
 		delete(app.Commands, commands.MessagesRedoCommand)
 	}
+	app.LoadCustomCommands()
 
 	return app, nil
 }
 
 func (a *App) Keybind(commandName commands.CommandName) string {
 	command := a.Commands[commandName]
+	if len(command.Keybindings) == 0 {
+		return ""
+	}
 	kb := command.Keybindings[0]
 	key := kb.Key
 	if kb.RequiresLeader {
@@ -271,12 +307,32 @@ func (a *App) SwitchModeReverse() (*App, tea.Cmd) {
 }
 
 func (a *App) InitializeProvider() tea.Cmd {
-	providersResponse, err := a.Client.App.Providers(context.Background())
+	currentProvider, currentModel, err := a.ResolveModel(context.Background())
 	if err != nil {
-		slog.Error("Failed to list providers", "error", err)
+		slog.Error("Failed to initialize provider", "error", err)
 		// TODO: notify user
 		return nil
 	}
+
+	var cmds []tea.Cmd
+	cmds = append(cmds, util.CmdHandler(ModelSelectedMsg{
+		Provider: *currentProvider,
+		Model:    *currentModel,
+	}))
+	if a.InitialPrompt != nil && *a.InitialPrompt != "" {
+		cmds = append(cmds, util.CmdHandler(SendPrompt{Text: *a.InitialPrompt}))
+	}
+	return tea.Sequence(cmds...)
+}
+
+// ResolveModel loads the configured providers and picks the provider and model
+// to start with, preferring the --model flag, then the saved state, then the
+// server defaults
+func (a *App) ResolveModel(ctx context.Context) (*opencode.Provider, *opencode.Model, error) {
+	providersResponse, err := a.Client.App.Providers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 	providers := providersResponse.Providers
 	var defaultProvider *opencode.Provider
 	var defaultModel *opencode.Model
@@ -302,8 +358,7 @@ func (a *App) InitializeProvider() tea.Cmd {
 		providers = append(providers, provider)
 	}
 	if len(providers) == 0 {
-		slog.Error("No providers configured")
-		return nil
+		return nil, nil, errors.New("no providers configured")
 	}
 
 	a.Providers = providers
@@ -353,16 +408,11 @@ func (a *App) InitializeProvider() tea.Cmd {
 		currentProvider = initialProvider
 		currentModel = initialModel
 	}
-
-	var cmds []tea.Cmd
-	cmds = append(cmds, util.CmdHandler(ModelSelectedMsg{
-		Provider: *currentProvider,
-		Model:    *currentModel,
-	}))
-	if a.InitialPrompt != nil && *a.InitialPrompt != "" {
-		cmds = append(cmds, util.CmdHandler(SendPrompt{Text: *a.InitialPrompt}))
+	if currentProvider == nil || currentModel == nil {
+		return nil, nil, errors.New("no models available")
 	}
-	return tea.Sequence(cmds...)
+
+	return currentProvider, currentModel, nil
 }
 
 func getDefaultModel(
@@ -381,10 +431,14 @@ func getDefaultModel(
 }
 
 func (a *App) IsBusy() bool {
-	if len(a.Messages) == 0 {
+	return isBusy(a.Messages)
+}
+
+func isBusy(messages []Message) bool {
+	if len(messages) == 0 {
 		return false
 	}
-	lastMessage := a.Messages[len(a.Messages)-1]
+	lastMessage := messages[len(messages)-1]
 	if casted, ok := lastMessage.Info.(opencode.AssistantMessage); ok {
 		return casted.Time.Completed == 0
 	}
@@ -475,12 +529,19 @@ func (a *App) CreateSession(ctx context.Context) (*opencode.Session, error) {
 	return session, nil
 }
 
-func (a *App) SendPrompt(ctx context.Context, prompt Prompt) (*App, tea.Cmd) {
+// SendPrompt adds the prompt to the session, creating the session when there
+// is none, and returns the command sending it. Nothing is sent and an error
+// is returned when the budget is exceeded or the session can't be created.
+func (a *App) SendPrompt(ctx context.Context, prompt Prompt) (*App, tea.Cmd, error) {
+	if err := a.BudgetExceeded(); err != nil {
+		return a, nil, err
+	}
+
 	var cmds []tea.Cmd
 	if a.Session.ID == "" {
 		session, err := a.CreateSession(ctx)
 		if err != nil {
-			return a, toast.NewErrorToast(err.Error())
+			return a, nil, err
 		}
 		a.Session = session
 		cmds = append(cmds, util.CmdHandler(SessionCreatedMsg{Session: session}))
@@ -509,7 +570,7 @@ func (a *App) SendPrompt(ctx context.Context, prompt Prompt) (*App, tea.Cmd) {
 
 	// The actual response will come through SSE
 	// For now, just return success
-	return a, tea.Batch(cmds...)
+	return a, tea.Batch(cmds...), nil
 }
 
 func (a *App) Cancel(ctx context.Context, sessionID string) error {
@@ -548,6 +609,27 @@ func (a *App) DeleteSession(ctx context.Context, sessionID string) error {
 	return nil
 }
 
+func (a *App) RespondPermission(
+	ctx context.Context,
+	sessionID string,
+	permissionID string,
+	response opencode.SessionRespondPermissionParamsResponse,
+) error {
+	_, err := a.Client.Session.RespondPermission(
+		ctx,
+		sessionID,
+		permissionID,
+		opencode.SessionRespondPermissionParams{
+			Response: opencode.F(response),
+		},
+	)
+	if err != nil {
+		slog.Error("Failed to respond to permission", "error", err)
+		return err
+	}
+	return nil
+}
+
 func (a *App) ListMessages(ctx context.Context, sessionId string) ([]Message, error) {
 	response, err := a.Client.Session.Messages(ctx, sessionId)
 	if err != nil {
@@ -570,6 +652,27 @@ func (a *App) ListMessages(ctx context.Context, sessionId string) ([]Message, er
 	return messages, nil
 }
 
+// ResyncSessions refetches the messages of every open session, used to catch
+// up on updates missed while the event stream was down
+func (a *App) ResyncSessions(ctx context.Context) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, tab := range a.ListTabs() {
+		sessionID := tab.Session.ID
+		if sessionID == "" {
+			continue
+		}
+		cmds = append(cmds, func() tea.Msg {
+			messages, err := a.ListMessages(ctx, sessionID)
+			if err != nil {
+				slog.Error("Failed to resync session", "session", sessionID, "error", err)
+				return nil
+			}
+			return SessionResyncedMsg{SessionID: sessionID, Messages: messages}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
 func (a *App) ListProviders(ctx context.Context) ([]opencode.Provider, error) {
 	response, err := a.Client.App.Providers(ctx)
 	if err != nil {
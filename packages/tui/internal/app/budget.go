@@ -0,0 +1,264 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+const usageDayFormat = "2006-01-02"
+
+var defaultBudgetThresholds = []float64{0.8, 1}
+
+// Budget limits the cost and tokens spent per session and per day, a zero
+// limit is unlimited. Thresholds are fractions of a limit at which a warning
+// is shown, with a hard stop prompts are refused once a limit is reached.
+type Budget struct {
+	SessionCost   float64   `toml:"session_cost"`
+	SessionTokens float64   `toml:"session_tokens"`
+	DailyCost     float64   `toml:"daily_cost"`
+	DailyTokens   float64   `toml:"daily_tokens"`
+	Thresholds    []float64 `toml:"thresholds"`
+	HardStop      bool      `toml:"hard_stop"`
+}
+
+// Usage is the running tally of the cost and tokens spent on a day across all
+// sessions, along with the daily warnings already shown
+type Usage struct {
+	Day    string   `toml:"day"`
+	Cost   float64  `toml:"cost"`
+	Tokens float64  `toml:"tokens"`
+	Warned []string `toml:"warned"`
+}
+
+// budgetTracker remembers what was already counted for each message, as
+// messages are reported again every time they are updated, and which session
+// warnings were shown
+type budgetTracker struct {
+	counted map[string]messageUsage
+	warned  map[string]bool
+}
+
+type messageUsage struct {
+	cost   float64
+	tokens float64
+}
+
+// budgetLimit is a limit of the budget with the usage counted against it
+type budgetLimit struct {
+	key   string
+	label string
+	used  float64
+	limit float64
+	cost  bool
+}
+
+func (l budgetLimit) format(value float64) string {
+	if l.cost {
+		return fmt.Sprintf("$%.2f", value)
+	}
+	return fmt.Sprintf("%d tokens", int(value))
+}
+
+// MessageTokens returns every token processed for a message, including cache
+// reads and writes
+func MessageTokens(tokens opencode.AssistantMessageTokens) float64 {
+	return tokens.Input + tokens.Output + tokens.Reasoning + tokens.Cache.Read + tokens.Cache.Write
+}
+
+// SessionUsage sums the cost and tokens of the assistant messages of a session
+func SessionUsage(messages []Message) (cost float64, tokens float64) {
+	for _, message := range messages {
+		if assistant, ok := message.Info.(opencode.AssistantMessage); ok {
+			cost += assistant.Cost
+			tokens += MessageTokens(assistant.Tokens)
+		}
+	}
+	return cost, tokens
+}
+
+// RecordUsage adds what an assistant message spent since it was last reported
+// to the tally for today. The returned command warns about any threshold of
+// the budget crossed and saves the state.
+func (a *App) RecordUsage(message opencode.AssistantMessage) tea.Cmd {
+	return a.recordUsage(message, time.Now())
+}
+
+func (a *App) recordUsage(message opencode.AssistantMessage, now time.Time) tea.Cmd {
+	if a.budget.counted == nil {
+		a.budget.counted = map[string]messageUsage{}
+	}
+	current := messageUsage{cost: message.Cost, tokens: MessageTokens(message.Tokens)}
+	previous := a.budget.counted[message.ID]
+	if current == previous {
+		return nil
+	}
+	a.budget.counted[message.ID] = current
+
+	a.resetUsage(now)
+	a.State.Usage.Cost += max(0, current.cost-previous.cost)
+	a.State.Usage.Tokens += max(0, current.tokens-previous.tokens)
+
+	cmds := []tea.Cmd{a.SaveState()}
+	for _, limit := range a.budgetLimits(message.SessionID) {
+		cmds = append(cmds, a.warnBudget(limit))
+	}
+	return tea.Batch(cmds...)
+}
+
+// countLoadedUsage marks the assistant messages of a session being opened as
+// counted, what they spent was tallied when they were sent, possibly before a
+// restart, so only their later updates are added
+func (a *App) countLoadedUsage(messages []Message) {
+	if a.budget.counted == nil {
+		a.budget.counted = map[string]messageUsage{}
+	}
+	for _, message := range messages {
+		assistant, ok := message.Info.(opencode.AssistantMessage)
+		if !ok {
+			continue
+		}
+		if _, ok := a.budget.counted[assistant.ID]; !ok {
+			a.budget.counted[assistant.ID] = messageUsage{
+				cost:   assistant.Cost,
+				tokens: MessageTokens(assistant.Tokens),
+			}
+		}
+	}
+}
+
+// resetUsage starts a new tally when the day changed since the last usage
+func (a *App) resetUsage(now time.Time) {
+	day := now.Format(usageDayFormat)
+	if a.State.Usage.Day != day {
+		a.State.Usage = Usage{Day: day}
+	}
+}
+
+// BudgetExceeded returns an error when the hard stop is enabled and a limit
+// for today or for the active session is reached
+func (a *App) BudgetExceeded() error {
+	if !a.State.Budget.HardStop {
+		return nil
+	}
+	a.resetUsage(time.Now())
+	for _, limit := range a.budgetLimits(a.Session.ID) {
+		if limit.limit > 0 && limit.used >= limit.limit {
+			return fmt.Errorf(
+				"%s budget of %s reached, raise it in %s to continue",
+				limit.label,
+				limit.format(limit.limit),
+				a.StatePath,
+			)
+		}
+	}
+	return nil
+}
+
+// budgetLimits lists the configured limits with the usage for today and for
+// the given session, when it is open
+func (a *App) budgetLimits(sessionID string) []budgetLimit {
+	budget := a.State.Budget
+	limits := []budgetLimit{}
+	if budget.DailyCost > 0 {
+		limits = append(limits, budgetLimit{
+			key:   "daily_cost",
+			label: "daily cost",
+			used:  a.State.Usage.Cost,
+			limit: budget.DailyCost,
+			cost:  true,
+		})
+	}
+	if budget.DailyTokens > 0 {
+		limits = append(limits, budgetLimit{
+			key:   "daily_tokens",
+			label: "daily token",
+			used:  a.State.Usage.Tokens,
+			limit: budget.DailyTokens,
+		})
+	}
+
+	index := a.TabIndex(sessionID)
+	if sessionID == "" || index == -1 || (budget.SessionCost <= 0 && budget.SessionTokens <= 0) {
+		return limits
+	}
+	messages := a.Messages
+	if index != a.ActiveTab {
+		messages = a.Tabs[index].Messages
+	}
+	cost, tokens := SessionUsage(messages)
+	if budget.SessionCost > 0 {
+		limits = append(limits, budgetLimit{
+			key:   "session:" + sessionID + ":cost",
+			label: "session cost",
+			used:  cost,
+			limit: budget.SessionCost,
+			cost:  true,
+		})
+	}
+	if budget.SessionTokens > 0 {
+		limits = append(limits, budgetLimit{
+			key:   "session:" + sessionID + ":tokens",
+			label: "session token",
+			used:  tokens,
+			limit: budget.SessionTokens,
+		})
+	}
+	return limits
+}
+
+// warnBudget shows a toast for the highest threshold of the limit crossed
+// that wasn't warned about yet. Daily warnings are kept in the state so they
+// are only shown once a day.
+func (a *App) warnBudget(limit budgetLimit) tea.Cmd {
+	if a.budget.warned == nil {
+		a.budget.warned = map[string]bool{}
+	}
+	thresholds := a.State.Budget.Thresholds
+	if len(thresholds) == 0 {
+		thresholds = defaultBudgetThresholds
+	}
+
+	crossed := 0.0
+	for _, threshold := range thresholds {
+		if threshold <= 0 || limit.used < limit.limit*threshold {
+			continue
+		}
+		key := fmt.Sprintf("%s:%g", limit.key, threshold)
+		if strings.HasPrefix(limit.key, "session:") {
+			if a.budget.warned[key] {
+				continue
+			}
+			a.budget.warned[key] = true
+		} else {
+			if slices.Contains(a.State.Usage.Warned, key) {
+				continue
+			}
+			a.State.Usage.Warned = append(a.State.Usage.Warned, key)
+		}
+		crossed = max(crossed, threshold)
+	}
+	if crossed == 0 {
+		return nil
+	}
+
+	usage := fmt.Sprintf("%s of %s", limit.format(limit.used), limit.format(limit.limit))
+	if limit.used >= limit.limit {
+		message := fmt.Sprintf("The %s budget is used up (%s)", limit.label, usage)
+		if a.State.Budget.HardStop {
+			message += ", new prompts are blocked"
+		}
+		return toast.NewErrorToast(message)
+	}
+	return toast.NewWarningToast(fmt.Sprintf(
+		"%d%% of the %s budget used (%s)",
+		int(limit.used/limit.limit*100),
+		limit.label,
+		usage,
+	))
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/theme"
+)
+
+func newBudgetApp(t *testing.T, budget Budget) *App {
+	// warnings are shown as toasts, which are styled with the current theme
+	if err := theme.LoadThemesFromJSON(); err != nil {
+		t.Fatal(err)
+	}
+	theme.SetTheme("opencode")
+
+	a := newTabsApp()
+	a.StatePath = filepath.Join(t.TempDir(), "tui")
+	a.State = NewState()
+	a.State.Budget = budget
+	return a
+}
+
+func assistantMessage(id string, cost float64, input float64) opencode.AssistantMessage {
+	return opencode.AssistantMessage{
+		ID:        id,
+		SessionID: "ses_1",
+		Cost:      cost,
+		Tokens:    opencode.AssistantMessageTokens{Input: input},
+	}
+}
+
+func TestRecordUsageCountsUpdatesOnce(t *testing.T) {
+	a := newBudgetApp(t, Budget{})
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	a.recordUsage(assistantMessage("msg_1", 0.1, 100), now)
+	a.recordUsage(assistantMessage("msg_1", 0.3, 250), now)
+	if cmd := a.recordUsage(assistantMessage("msg_1", 0.3, 250), now); cmd != nil {
+		t.Errorf("expected an unchanged message to be ignored")
+	}
+	a.recordUsage(assistantMessage("msg_2", 0.2, 50), now)
+
+	if a.State.Usage.Day != "2026-01-02" {
+		t.Errorf("expected the tally to be for 2026-01-02, got %q", a.State.Usage.Day)
+	}
+	if a.State.Usage.Tokens != 300 {
+		t.Errorf("expected 300 tokens, got %f", a.State.Usage.Tokens)
+	}
+	if a.State.Usage.Cost < 0.499 || a.State.Usage.Cost > 0.501 {
+		t.Errorf("expected a cost of 0.5, got %f", a.State.Usage.Cost)
+	}
+
+	a.recordUsage(assistantMessage("msg_3", 0.2, 10), now.Add(24*time.Hour))
+	if a.State.Usage.Day != "2026-01-03" || a.State.Usage.Tokens != 10 {
+		t.Errorf("expected the tally to reset on a new day, got %+v", a.State.Usage)
+	}
+}
+
+func TestWarnBudgetOncePerThreshold(t *testing.T) {
+	a := newBudgetApp(t, Budget{DailyCost: 1, Thresholds: []float64{0.5, 0.8}})
+	limit := budgetLimit{key: "daily_cost", label: "daily cost", limit: 1, cost: true}
+
+	limit.used = 0.4
+	if cmd := a.warnBudget(limit); cmd != nil {
+		t.Errorf("expected no warning below the thresholds")
+	}
+	limit.used = 0.9
+	if cmd := a.warnBudget(limit); cmd == nil {
+		t.Errorf("expected a warning once the thresholds are crossed")
+	}
+	if cmd := a.warnBudget(limit); cmd != nil {
+		t.Errorf("expected the warning to be shown only once")
+	}
+	if len(a.State.Usage.Warned) != 2 {
+		t.Errorf("expected both thresholds to be remembered, got %v", a.State.Usage.Warned)
+	}
+}
+
+func TestBudgetExceededHardStop(t *testing.T) {
+	a := newBudgetApp(t, Budget{SessionCost: 1})
+	a.OpenTab(&opencode.Session{ID: "ses_1"}, []Message{
+		{Info: assistantMessage("msg_1", 1.5, 10)},
+	})
+
+	if err := a.BudgetExceeded(); err != nil {
+		t.Errorf("expected prompts to be allowed without a hard stop, got %v", err)
+	}
+	a.State.Budget.HardStop = true
+	if err := a.BudgetExceeded(); err == nil {
+		t.Errorf("expected the session budget to block prompts")
+	}
+	if _, cmd, err := a.SendPrompt(context.Background(), Prompt{Text: "hi"}); err == nil || cmd != nil {
+		t.Errorf("expected the prompt not to be sent over the budget")
+	}
+	if len(a.Messages) != 1 {
+		t.Errorf("expected the prompt not to be added, got %d messages", len(a.Messages))
+	}
+	a.State.Budget.SessionCost = 2
+	if err := a.BudgetExceeded(); err != nil {
+		t.Errorf("expected prompts to be allowed under the budget, got %v", err)
+	}
+}
+
+func TestRecordUsageSkipsLoadedMessages(t *testing.T) {
+	a := newBudgetApp(t, Budget{DailyCost: 1})
+	now := time.Now()
+	a.State.Usage = Usage{Day: now.Format(usageDayFormat), Cost: 0.9}
+
+	// a session reopened after a restart, its reply was tallied before
+	reply := assistantMessage("msg_1", 0.9, 100)
+	a.OpenTab(&opencode.Session{ID: "ses_1"}, []Message{{Info: reply}})
+	if cmd := a.recordUsage(reply, now); cmd != nil || a.State.Usage.Cost != 0.9 {
+		t.Fatalf("expected the loaded message not to be counted again, got %f", a.State.Usage.Cost)
+	}
+
+	reply.Cost = 1
+	a.recordUsage(reply, now)
+	if a.State.Usage.Cost < 0.999 || a.State.Usage.Cost > 1.001 {
+		t.Errorf("expected only the later update to be added, got %f", a.State.Usage.Cost)
+	}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/uuid"
+	"github.com/sst/opencode/internal/attachment"
+	"github.com/sst/opencode/internal/commands"
+)
+
+const (
+	customShellTimeout     = 2 * time.Minute
+	customCommandsDebounce = 100 * time.Millisecond
+)
+
+// CustomCommandsChangedMsg is sent when a file changed in the directories of
+// the custom commands
+type CustomCommandsChangedMsg struct{}
+
+// CustomCommandOutputMsg is sent when the shell command of a custom command
+// finished
+type CustomCommandOutputMsg struct {
+	Command commands.CustomCommand
+	Args    []string
+	Output  string
+	Err     error
+}
+
+// CustomCommandDirs returns the directories custom commands are loaded from
+func (a *App) CustomCommandDirs() []string {
+	return commands.CustomCommandDirs(a.Info.Path.Config, a.Info.Path.Root, a.Info.Path.Cwd)
+}
+
+// LoadCustomCommands registers the custom commands found in their
+// directories, replacing those registered before
+func (a *App) LoadCustomCommands() {
+	custom := commands.LoadCustomCommands(a.Config.Commands, a.CustomCommandDirs())
+	a.Commands.SetCustomCommands(custom)
+	slog.Debug("Loaded custom commands", "count", len(custom))
+}
+
+// WatchCustomCommands waits for a change in the directories of the custom
+// commands. A directory that doesn't exist yet is noticed once it's created
+// in a watched parent.
+func (a *App) WatchCustomCommands() tea.Cmd {
+	if a.customWatcher == nil {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			slog.Warn("Failed to watch custom commands", "error", err)
+			return nil
+		}
+		a.customWatcher = watcher
+	}
+	dirs := a.CustomCommandDirs()
+	for _, dir := range dirs {
+		for _, path := range []string{dir, filepath.Dir(dir)} {
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				// adding a directory twice is a no-op
+				a.customWatcher.Add(path)
+				break
+			}
+		}
+	}
+
+	watcher := a.customWatcher
+	return func() tea.Msg {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return nil
+				}
+				if !isCustomCommandPath(dirs, event.Name) {
+					continue
+				}
+				// editors write files in several steps, wait for them to settle
+				timer := time.NewTimer(customCommandsDebounce)
+				for settled := false; !settled; {
+					select {
+					case <-watcher.Events:
+					case <-timer.C:
+						settled = true
+					}
+				}
+				return CustomCommandsChangedMsg{}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return nil
+				}
+				slog.Warn("Failed to watch custom commands", "error", err)
+			}
+		}
+	}
+}
+
+// isCustomCommandPath returns whether the path is a directory of the custom
+// commands or a command file in one
+func isCustomCommandPath(dirs []string, path string) bool {
+	for _, dir := range dirs {
+		if path == dir {
+			return true
+		}
+		if filepath.Dir(path) == dir && strings.HasSuffix(path, ".toml") {
+			return true
+		}
+	}
+	return false
+}
+
+// RunCustomShell runs the shell command of a custom command in the working
+// directory
+func (a *App) RunCustomShell(command commands.CustomCommand, args []string) tea.Cmd {
+	dir := a.Info.Path.Cwd
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), customShellTimeout)
+		defer cancel()
+		output, err := command.RunShell(ctx, dir, args)
+		if err != nil {
+			slog.Error("Custom command failed", "command", command.Name, "error", err)
+		}
+		return CustomCommandOutputMsg{
+			Command: command,
+			Args:    args,
+			Output:  output,
+			Err:     err,
+		}
+	}
+}
+
+// Attachment returns the output as a text attachment shown as a summary
+func (m CustomCommandOutputMsg) Attachment() *attachment.Attachment {
+	lines := strings.Count(strings.TrimRight(m.Output, "\n"), "\n") + 1
+	return &attachment.Attachment{
+		ID:        uuid.NewString(),
+		Type:      "text",
+		MediaType: "text/plain",
+		Display:   fmt.Sprintf("[/%s output %d lines]", m.Command.Name, lines),
+		URL:       "data:text/plain;base64," + base64.StdEncoding.EncodeToString([]byte(m.Output)),
+		Filename:  m.Command.Name + "-output.txt",
+		Source: &attachment.TextSource{
+			Value: m.Output,
+		},
+	}
+}
+
+// Prompt returns the prompt of the command with the output attached before
+// it
+func (m CustomCommandOutputMsg) Prompt() Prompt {
+	output := m.Attachment()
+	output.StartIndex = 0
+	output.EndIndex = len(output.Display)
+	return Prompt{
+		Text:        output.Display + "\n\n" + m.Command.ExpandPrompt(m.Args),
+		Attachments: []*attachment.Attachment{output},
+	}
+}
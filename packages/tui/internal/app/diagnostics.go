@@ -0,0 +1,119 @@
+package app
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+)
+
+type DiagnosticSeverity int
+
+const (
+	DiagnosticSeverityError DiagnosticSeverity = iota + 1
+	DiagnosticSeverityWarning
+	DiagnosticSeverityInformation
+	DiagnosticSeverityHint
+)
+
+type DiagnosticPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Diagnostic represents an LSP diagnostic
+type Diagnostic struct {
+	Range struct {
+		Start DiagnosticPosition `json:"start"`
+		End   DiagnosticPosition `json:"end"`
+	} `json:"range"`
+	Severity DiagnosticSeverity `json:"severity"`
+	Message  string             `json:"message"`
+	Source   string             `json:"source"`
+}
+
+// ParseDiagnostics decodes a list of diagnostics from loosely typed JSON data,
+// skipping any entries that don't look like a diagnostic
+func ParseDiagnostics(value any) []Diagnostic {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+	diagnostics := make([]Diagnostic, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		var diagnostic Diagnostic
+		if err := json.Unmarshal(data, &diagnostic); err != nil {
+			continue
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	return diagnostics
+}
+
+// Diagnostics aggregates the most recent diagnostics reported for each file
+type Diagnostics struct {
+	mu    sync.RWMutex
+	files map[string][]Diagnostic
+}
+
+func NewDiagnostics() *Diagnostics {
+	return &Diagnostics{
+		files: make(map[string][]Diagnostic),
+	}
+}
+
+// Set replaces the diagnostics for a file, an empty list clears the file
+func (d *Diagnostics) Set(path string, diagnostics []Diagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(diagnostics) == 0 {
+		delete(d.files, path)
+		return
+	}
+	sorted := slices.Clone(diagnostics)
+	slices.SortStableFunc(sorted, func(a, b Diagnostic) int {
+		if a.Severity != b.Severity {
+			return int(a.Severity) - int(b.Severity)
+		}
+		return a.Range.Start.Line - b.Range.Start.Line
+	})
+	d.files[path] = sorted
+}
+
+// Files returns the paths that have diagnostics, sorted by name
+func (d *Diagnostics) Files() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	files := make([]string, 0, len(d.files))
+	for path := range d.files {
+		files = append(files, path)
+	}
+	slices.Sort(files)
+	return files
+}
+
+func (d *Diagnostics) Get(path string) []Diagnostic {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.files[path]
+}
+
+// Counts returns the number of errors and warnings across all files
+func (d *Diagnostics) Counts() (errors int, warnings int) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, diagnostics := range d.files {
+		for _, diagnostic := range diagnostics {
+			switch diagnostic.Severity {
+			case DiagnosticSeverityError:
+				errors++
+			case DiagnosticSeverityWarning:
+				warnings++
+			}
+		}
+	}
+	return errors, warnings
+}
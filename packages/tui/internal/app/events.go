@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/packages/ssestream"
+)
+
+const (
+	reconnectBaseDelay = 500 * time.Millisecond
+	reconnectMaxDelay  = 30 * time.Second
+)
+
+type ConnectionState int
+
+const (
+	ConnectionConnecting ConnectionState = iota
+	ConnectionConnected
+	ConnectionReconnecting
+)
+
+// ConnectionStateMsg is sent whenever the event stream connects or drops
+type ConnectionStateMsg struct {
+	State   ConnectionState
+	Attempt int
+	Delay   time.Duration
+	Err     error
+}
+
+// EventsReconnectedMsg is sent once the event stream is back after dropping,
+// any events sent in between are lost and open sessions need to be refetched
+type EventsReconnectedMsg struct{}
+
+type EventStreamFunc func(ctx context.Context) *ssestream.Stream[opencode.EventListResponse]
+
+// SubscribeEvents forwards server events to send until the context is done,
+// reconnecting with exponential backoff and jitter whenever the stream ends
+func SubscribeEvents(ctx context.Context, connect EventStreamFunc, send func(tea.Msg)) {
+	attempt := 0
+	dropped := false
+	send(ConnectionStateMsg{State: ConnectionConnecting})
+
+	for {
+		stream := connect(ctx)
+		connected := false
+		for stream.Next() {
+			evt := stream.Current().AsUnion()
+			if !connected {
+				connected = true
+				attempt = 0
+				send(ConnectionStateMsg{State: ConnectionConnected})
+				if dropped {
+					send(EventsReconnectedMsg{})
+				}
+			}
+			if _, ok := evt.(opencode.EventListResponseEventStorageWrite); ok {
+				continue
+			}
+			send(evt)
+		}
+		err := stream.Err()
+		stream.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("event stream closed")
+		}
+		dropped = dropped || connected
+		attempt++
+		delay := reconnectDelay(attempt)
+		slog.Error("Error streaming events", "error", err, "attempt", attempt, "retry", delay)
+		send(ConnectionStateMsg{
+			State:   ConnectionReconnecting,
+			Attempt: attempt,
+			Delay:   delay,
+			Err:     err,
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// reconnectDelay doubles the delay on every attempt up to a maximum, picking a
+// random duration in the upper half so clients don't reconnect in lockstep
+func reconnectDelay(attempt int) time.Duration {
+	delay := reconnectMaxDelay
+	if attempt < 16 {
+		delay = min(reconnectBaseDelay<<(attempt-1), reconnectMaxDelay)
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/packages/ssestream"
+)
+
+func TestReconnectDelay(t *testing.T) {
+	for attempt := 1; attempt < 100; attempt++ {
+		delay := reconnectDelay(attempt)
+		if delay < reconnectBaseDelay/2 || delay > reconnectMaxDelay {
+			t.Fatalf("attempt %d: delay %v out of bounds", attempt, delay)
+		}
+	}
+	if delay := reconnectDelay(1); delay > reconnectBaseDelay {
+		t.Errorf("expected first retry within %v, got %v", reconnectBaseDelay, delay)
+	}
+}
+
+func eventStream(events ...string) *ssestream.Stream[opencode.EventListResponse] {
+	body := ""
+	for _, event := range events {
+		body += "data: " + event + "\n\n"
+	}
+	res := &http.Response{
+		Header: http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:   io.NopCloser(strings.NewReader(body)),
+	}
+	return ssestream.NewStream[opencode.EventListResponse](ssestream.NewDecoder(res), nil)
+}
+
+func TestSubscribeEventsReconnects(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	connected := `{"type":"server.connected","properties":{}}`
+	idle := `{"type":"session.idle","properties":{"sessionID":"ses_1"}}`
+	connects := 0
+	connect := func(ctx context.Context) *ssestream.Stream[opencode.EventListResponse] {
+		connects++
+		switch connects {
+		case 1:
+			return eventStream(connected, idle)
+		case 2:
+			return ssestream.NewStream[opencode.EventListResponse](nil, errors.New("connection refused"))
+		}
+		return eventStream(connected)
+	}
+
+	var msgs []tea.Msg
+	SubscribeEvents(ctx, connect, func(msg tea.Msg) {
+		msgs = append(msgs, msg)
+		if _, ok := msg.(EventsReconnectedMsg); ok {
+			cancel()
+		}
+	})
+
+	if connects != 3 {
+		t.Fatalf("expected 3 connection attempts, got %d", connects)
+	}
+
+	var states []ConnectionState
+	idleEvents := 0
+	reconnected := false
+	for _, msg := range msgs {
+		switch msg := msg.(type) {
+		case ConnectionStateMsg:
+			states = append(states, msg.State)
+		case opencode.EventListResponseEventSessionIdle:
+			idleEvents++
+		case EventsReconnectedMsg:
+			reconnected = true
+		}
+	}
+
+	expected := []ConnectionState{
+		ConnectionConnecting,
+		ConnectionConnected,
+		ConnectionReconnecting,
+		ConnectionReconnecting,
+		ConnectionConnected,
+	}
+	if len(states) != len(expected) {
+		t.Fatalf("expected states %v, got %v", expected, states)
+	}
+	for i := range expected {
+		if states[i] != expected[i] {
+			t.Fatalf("expected states %v, got %v", expected, states)
+		}
+	}
+	if idleEvents != 1 {
+		t.Errorf("expected the idle event to be forwarded once, got %d", idleEvents)
+	}
+	if !reconnected {
+		t.Error("expected a reconnected message after the stream came back")
+	}
+}
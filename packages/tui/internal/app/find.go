@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+// FileMatches are the matches of a text search within a single file
+type FileMatches struct {
+	Path    string
+	Matches []opencode.FindTextResponse
+}
+
+// FindText searches the contents of the project files for a pattern
+func (a *App) FindText(ctx context.Context, pattern string) ([]opencode.FindTextResponse, error) {
+	matches, err := a.Client.Find.Text(ctx, opencode.FindTextParams{
+		Pattern: opencode.F(pattern),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if matches == nil {
+		return []opencode.FindTextResponse{}, nil
+	}
+	return *matches, nil
+}
+
+// GroupMatchesByFile groups the matches of a text search by file, in the
+// order the files were first reported. At most limit matches are kept, a
+// limit of zero keeps them all.
+func GroupMatchesByFile(matches []opencode.FindTextResponse, limit int) []FileMatches {
+	groups := []FileMatches{}
+	index := map[string]int{}
+	for i, match := range matches {
+		if limit > 0 && i >= limit {
+			break
+		}
+		path := strings.TrimPrefix(match.Path.Text, "./")
+		group, ok := index[path]
+		if !ok {
+			group = len(groups)
+			index[path] = group
+			groups = append(groups, FileMatches{Path: path})
+		}
+		groups[group].Matches = append(groups[group].Matches, match)
+	}
+	return groups
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func textMatch(path string, line float64) opencode.FindTextResponse {
+	return opencode.FindTextResponse{
+		LineNumber: line,
+		Path:       opencode.FindTextResponsePath{Text: path},
+	}
+}
+
+func TestGroupMatchesByFile(t *testing.T) {
+	matches := []opencode.FindTextResponse{
+		textMatch("b.go", 3),
+		textMatch("./a.go", 1),
+		textMatch("b.go", 9),
+		textMatch("a.go", 4),
+		textMatch("c.go", 2),
+	}
+
+	groups := GroupMatchesByFile(matches, 4)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 files within the limit, got %d", len(groups))
+	}
+	if groups[0].Path != "b.go" || len(groups[0].Matches) != 2 {
+		t.Errorf("expected b.go first with 2 matches, got %s with %d", groups[0].Path, len(groups[0].Matches))
+	}
+	if groups[1].Path != "a.go" || len(groups[1].Matches) != 2 {
+		t.Errorf("expected a.go second with 2 matches, got %s with %d", groups[1].Path, len(groups[1].Matches))
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import "slices"
+
+// defaultHistorySize is the number of prompts kept in the history of a
+// project when the state doesn't set a size
+const defaultHistorySize = 50
+
+// HistoryEntry is a prompt in the history of a project
+type HistoryEntry struct {
+	Prompt
+	// Pinned prompts never roll out of the history
+	Pinned bool `toml:"pinned,omitempty"`
+}
+
+// History returns the prompt history of the project, the latest first
+func (a *App) History() []HistoryEntry {
+	return a.State.History(a.Info.Path.Root)
+}
+
+// History returns the prompt history of a project, the latest first
+func (s *State) History(root string) []HistoryEntry {
+	return s.PromptHistory[root]
+}
+
+// AddPromptToHistory adds a prompt to the front of the history of a project.
+// A prompt already in the history moves to the front and keeps its pin, past
+// the history size the oldest prompts that aren't pinned roll out.
+func (s *State) AddPromptToHistory(root string, prompt Prompt) {
+	entry := HistoryEntry{Prompt: prompt}
+	history := slices.DeleteFunc(slices.Clone(s.PromptHistory[root]), func(e HistoryEntry) bool {
+		if e.Text != prompt.Text {
+			return false
+		}
+		entry.Pinned = entry.Pinned || e.Pinned
+		return true
+	})
+	history = append([]HistoryEntry{entry}, history...)
+
+	size := s.HistorySize
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	for i := len(history) - 1; i >= 0 && len(history) > size; i-- {
+		if !history[i].Pinned {
+			history = slices.Delete(history, i, i+1)
+		}
+	}
+
+	if s.PromptHistory == nil {
+		s.PromptHistory = make(map[string][]HistoryEntry)
+	}
+	s.PromptHistory[root] = history
+}
+
+// PinPrompt pins or unpins a prompt of the history of a project
+func (s *State) PinPrompt(root string, index int, pinned bool) {
+	history := s.PromptHistory[root]
+	if index < 0 || index >= len(history) {
+		return
+	}
+	history[index].Pinned = pinned
+}
+
+// RemovePromptFromHistory removes a prompt from the history of a project,
+// pinned or not
+func (s *State) RemovePromptFromHistory(root string, index int) {
+	history := s.PromptHistory[root]
+	if index < 0 || index >= len(history) {
+		return
+	}
+	s.PromptHistory[root] = slices.Delete(slices.Clone(history), index, index+1)
+}
+
+// seedHistory copies the history older versions shared between all projects
+// to a project that has no history of its own yet. The shared history is
+// kept for the projects opened later.
+func (s *State) seedHistory(root string) {
+	if len(s.MessageHistory) == 0 {
+		return
+	}
+	if _, ok := s.PromptHistory[root]; ok {
+		return
+	}
+	for _, prompt := range slices.Backward(s.MessageHistory) {
+		s.AddPromptToHistory(root, prompt)
+	}
+}
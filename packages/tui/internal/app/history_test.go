@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+)
+
+func historyTexts(history []HistoryEntry) []string {
+	texts := make([]string, len(history))
+	for i, entry := range history {
+		texts[i] = entry.Text
+	}
+	return texts
+}
+
+func TestAddPromptToHistory(t *testing.T) {
+	state := NewState()
+	state.AddPromptToHistory("/a", Prompt{Text: "first"})
+	state.AddPromptToHistory("/a", Prompt{Text: "second"})
+	state.AddPromptToHistory("/b", Prompt{Text: "other"})
+	state.PinPrompt("/a", 1, true)
+	state.AddPromptToHistory("/a", Prompt{Text: "first"})
+
+	history := state.History("/a")
+	if got := fmt.Sprint(historyTexts(history)); got != "[first second]" {
+		t.Fatalf("expected the repeated prompt to move to the front, got %s", got)
+	}
+	if !history[0].Pinned {
+		t.Errorf("expected the repeated prompt to stay pinned")
+	}
+	if got := fmt.Sprint(historyTexts(state.History("/b"))); got != "[other]" {
+		t.Errorf("expected the history of each project to be separate, got %s", got)
+	}
+}
+
+func TestHistorySizeKeepsPinned(t *testing.T) {
+	state := NewState()
+	state.HistorySize = 2
+	state.AddPromptToHistory("/a", Prompt{Text: "pinned"})
+	state.PinPrompt("/a", 0, true)
+	for _, text := range []string{"one", "two", "three"} {
+		state.AddPromptToHistory("/a", Prompt{Text: text})
+	}
+
+	if got := fmt.Sprint(historyTexts(state.History("/a"))); got != "[three pinned]" {
+		t.Errorf("expected the oldest unpinned prompts to roll out, got %s", got)
+	}
+}
+
+func TestSeedHistory(t *testing.T) {
+	state := NewState()
+	state.MessageHistory = []Prompt{{Text: "latest"}, {Text: "oldest"}}
+	state.seedHistory("/a")
+
+	if got := fmt.Sprint(historyTexts(state.History("/a"))); got != "[latest oldest]" {
+		t.Errorf("expected the shared history in the same order, got %s", got)
+	}
+
+	state.AddPromptToHistory("/a", Prompt{Text: "new"})
+	state.seedHistory("/b")
+	if got := fmt.Sprint(historyTexts(state.History("/b"))); got != "[latest oldest]" {
+		t.Errorf("expected the shared history to be kept for other projects, got %s", got)
+	}
+
+	state.RemovePromptFromHistory("/b", 0)
+	state.RemovePromptFromHistory("/b", 0)
+	state.seedHistory("/b")
+	if got := len(state.History("/b")); got != 0 {
+		t.Errorf("expected a cleared history not to be seeded again, got %d prompts", got)
+	}
+}
@@ -0,0 +1,238 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sst/opencode/internal/commands"
+)
+
+const defaultKeySequenceTimeout = 1500 * time.Millisecond
+
+// KeySequencePending returns whether the next key continues a key sequence,
+// after the leader key or the first keys of a keybinding with several keys
+func (a *App) KeySequencePending() bool {
+	return a.IsLeaderSequence || len(a.KeySequence) > 0
+}
+
+// ResetKeySequence forgets the keys pressed so far
+func (a *App) ResetKeySequence() {
+	a.IsLeaderSequence = false
+	a.KeySequence = nil
+}
+
+// KeySequenceText returns the keys pressed so far, with the leader key
+func (a *App) KeySequenceText() string {
+	keys := a.KeySequence
+	if a.IsLeaderSequence {
+		keys = append([]string{a.Config.Keybinds.Leader}, keys...)
+	}
+	return strings.Join(keys, " ")
+}
+
+// KeySequenceTimeout returns how long to wait for the next key of a key
+// sequence, 0 waits until a key is pressed
+func (a *App) KeySequenceTimeout() time.Duration {
+	if a.State.KeySequenceTimeout != nil && *a.State.KeySequenceTimeout >= 0 {
+		return time.Duration(*a.State.KeySequenceTimeout) * time.Millisecond
+	}
+	return defaultKeySequenceTimeout
+}
+
+// KeybindsConfigPath returns the path of the global opencode config, where
+// keybindings changed in the tui are saved. opencode.json is loaded after
+// config.json, the latter is only written when it's the only one.
+func (a *App) KeybindsConfigPath() string {
+	path := filepath.Join(a.Info.Path.Config, "opencode.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		legacy := filepath.Join(a.Info.Path.Config, "config.json")
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return path
+}
+
+// SaveKeybind binds a command to keys and saves them to the global config.
+// Without keys the command is set to "none", which removes it, its trigger
+// included, once the config is loaded again.
+func (a *App) SaveKeybind(name commands.CommandName, bindings []commands.Keybinding) error {
+	return a.rebind(name, bindings, commands.FormatBindings(bindings))
+}
+
+// ResetKeybind binds a command to its default keys, removing it from the
+// global config
+func (a *App) ResetKeybind(name commands.CommandName) error {
+	return a.rebind(name, a.Commands[name].DefaultKeybindings, "")
+}
+
+// rebindableCommand returns a command whose keys can be changed from the
+// keybindings dialog
+func (a *App) rebindableCommand(name commands.CommandName) (commands.Command, error) {
+	command, ok := a.Commands[name]
+	if !ok {
+		return command, fmt.Errorf("unknown command %s", name)
+	}
+	// the keys of custom commands are set where they're defined
+	if command.Custom != nil {
+		return command, fmt.Errorf("the keybinding of %s is set in its command file or the config", name)
+	}
+	return command, nil
+}
+
+func (a *App) rebind(name commands.CommandName, bindings []commands.Keybinding, value string) error {
+	command, err := a.rebindableCommand(name)
+	if err != nil {
+		return err
+	}
+	if err := writeKeybind(a.KeybindsConfigPath(), string(name), value); err != nil {
+		return err
+	}
+	command.Keybindings = bindings
+	a.Commands[name] = command
+	return nil
+}
+
+// writeKeybind sets a keybinding in the config file, keeping the rest of the
+// config as it is, in the same order. An empty value removes the keybinding.
+func writeKeybind(path, name, value string) error {
+	var config []jsonField
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if config, err = decodeObject(data); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+	if !slices.ContainsFunc(config, func(field jsonField) bool { return field.Key == "$schema" }) {
+		config = slices.Insert(config, 0, jsonField{"$schema", json.RawMessage(`"https://opencode.ai/config.json"`)})
+	}
+
+	var keybinds []jsonField
+	if i := slices.IndexFunc(config, func(field jsonField) bool { return field.Key == "keybinds" }); i >= 0 {
+		if keybinds, err = decodeObject(config[i].Value); err != nil {
+			return fmt.Errorf("failed to parse keybinds in %s: %w", path, err)
+		}
+	}
+	var encoded json.RawMessage
+	if value != "" {
+		if encoded, err = marshal(value); err != nil {
+			return err
+		}
+	}
+	keybinds = setField(keybinds, name, encoded)
+	encoded = nil
+	if len(keybinds) > 0 {
+		if encoded, err = encodeObject(keybinds); err != nil {
+			return err
+		}
+	}
+	config = setField(config, "keybinds", encoded)
+
+	encoded, err = encodeObject(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(encoded, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	slog.Debug("Keybind saved to config", "file", path, "command", name, "keys", value)
+	return nil
+}
+
+// jsonField is a key of a JSON object with its value as written
+type jsonField struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// decodeObject decodes the fields of a JSON object in the order they're
+// written
+func decodeObject(data []byte) ([]jsonField, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('{') {
+		return nil, fmt.Errorf("expected an object, got %v", token)
+	}
+	fields := []jsonField{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		field := jsonField{Key: token.(string)}
+		if err := decoder.Decode(&field.Value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// setField sets the value of a key, appended when it's new. A nil value
+// removes the key.
+func setField(fields []jsonField, key string, value json.RawMessage) []jsonField {
+	i := slices.IndexFunc(fields, func(field jsonField) bool { return field.Key == key })
+	switch {
+	case value == nil && i >= 0:
+		return slices.Delete(fields, i, i+1)
+	case value == nil:
+		return fields
+	case i >= 0:
+		fields[i].Value = value
+		return fields
+	}
+	return append(fields, jsonField{key, value})
+}
+
+// encodeObject encodes the fields as a JSON object indented like the config
+// files
+func encodeObject(fields []jsonField) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(field.Value)
+	}
+	buf.WriteByte('}')
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return indented.Bytes(), nil
+}
+
+// marshal encodes JSON indented like the config files, without escaping the
+// angle brackets of <leader>
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/commands"
+)
+
+func TestWriteKeybind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "opencode.json")
+	os.WriteFile(path, []byte(`{"model": "anthropic/claude", "keybinds": {"leader": "ctrl+a"}}`), 0o644)
+
+	if err := writeKeybind(path, "session_new", "<leader>n,ctrl+n"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	config := string(data)
+	for _, expected := range []string{
+		`"model": "anthropic/claude"`,
+		`"leader": "ctrl+a"`,
+		`"session_new": "<leader>n,ctrl+n"`,
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("expected %s in the config, got %s", expected, config)
+		}
+	}
+
+	if err := writeKeybind(path, "session_new", ""); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "session_new") {
+		t.Errorf("expected the keybinding to be removed, got %s", data)
+	}
+}
+
+func TestWriteKeybindCreatesConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "opencode", "opencode.json")
+	if err := writeKeybind(path, "tool_details", "none"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"tool_details": "none"`) {
+		t.Errorf("expected the keybinding in a new config, got %s", data)
+	}
+}
+
+func TestWriteKeybindKeepsOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "opencode.json")
+	os.WriteFile(path, []byte(`{"theme": "tokyonight", "keybinds": {"leader": "ctrl+a"}, "model": "anthropic/claude"}`), 0o644)
+
+	if err := writeKeybind(path, "session_new", "<leader>n"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	expected := `{
+  "$schema": "https://opencode.ai/config.json",
+  "theme": "tokyonight",
+  "keybinds": {
+    "leader": "ctrl+a",
+    "session_new": "<leader>n"
+  },
+  "model": "anthropic/claude"
+}
+`
+	if string(data) != expected {
+		t.Errorf("expected the keys in their order, got %s", data)
+	}
+}
+
+func TestSaveKeybindUnbindsInConfig(t *testing.T) {
+	dir := t.TempDir()
+	a := &App{
+		Info:     opencode.App{Path: opencode.AppPath{Config: dir}},
+		Commands: commands.LoadFromConfig(&opencode.Config{}),
+	}
+	// the global config only has the legacy name
+	legacy := filepath.Join(dir, "config.json")
+	os.WriteFile(legacy, []byte(`{"theme": "tokyonight"}`), 0o644)
+
+	if err := a.SaveKeybind(commands.SessionNewCommand, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Commands[commands.SessionNewCommand].Keybindings) != 0 {
+		t.Errorf("expected the command to be unbound")
+	}
+	data, _ := os.ReadFile(legacy)
+	if !strings.Contains(string(data), `"session_new": "none"`) {
+		t.Errorf("expected the command to be set to none in the loaded config, got %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "opencode.json")); !os.IsNotExist(err) {
+		t.Errorf("expected no other config to be created, got %v", err)
+	}
+
+	if err := a.ResetKeybind(commands.SessionNewCommand); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(legacy)
+	if strings.Contains(string(data), "session_new") || len(a.Commands[commands.SessionNewCommand].Keybindings) == 0 {
+		t.Errorf("expected the reset to bind the command again, got %s", data)
+	}
+}
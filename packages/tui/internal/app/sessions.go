@@ -0,0 +1,214 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+const summaryConcurrency = 8
+
+// SessionSummary holds the metadata of a session derived from its messages
+type SessionSummary struct {
+	Messages     int
+	Cost         float64
+	FirstMessage string
+}
+
+// SummarizeMessages counts the messages and cost of a session and picks the
+// text of its first user message
+func SummarizeMessages(messages []Message) SessionSummary {
+	summary := SessionSummary{Messages: len(messages)}
+	for _, message := range messages {
+		switch info := message.Info.(type) {
+		case opencode.AssistantMessage:
+			summary.Cost += info.Cost
+		case opencode.UserMessage:
+			if summary.FirstMessage != "" {
+				continue
+			}
+			summary.FirstMessage = MessageText(message)
+		}
+	}
+	return summary
+}
+
+// MessageText joins the text parts of a message written by the user or the
+// assistant, skipping synthetic parts
+func MessageText(message Message) string {
+	texts := []string{}
+	for _, part := range message.Parts {
+		if text, ok := part.(opencode.TextPart); ok && !text.Synthetic {
+			texts = append(texts, strings.TrimSpace(text.Text))
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, " "))
+}
+
+// summaryCache holds the summaries of sessions along with the time the
+// session was last updated when they were computed
+type summaryCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedSummary
+}
+
+type cachedSummary struct {
+	updated float64
+	summary SessionSummary
+}
+
+func (c *summaryCache) get(session opencode.Session) (SessionSummary, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[session.ID]
+	if !ok || cached.updated != session.Time.Updated {
+		return SessionSummary{}, false
+	}
+	return cached.summary, true
+}
+
+func (c *summaryCache) set(session opencode.Session, summary SessionSummary) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedSummary)
+	}
+	c.entries[session.ID] = cachedSummary{updated: session.Time.Updated, summary: summary}
+}
+
+// SessionSummaries summarizes the given sessions, fetching in parallel the
+// messages of those that changed since they were last summarized. Sessions
+// that fail to load are left out.
+func (a *App) SessionSummaries(
+	ctx context.Context,
+	sessions []opencode.Session,
+) map[string]SessionSummary {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	summaries := make(map[string]SessionSummary, len(sessions))
+	limit := make(chan struct{}, summaryConcurrency)
+
+	for _, session := range sessions {
+		if summary, ok := a.summaries.get(session); ok {
+			summaries[session.ID] = summary
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			messages, err := a.ListMessages(ctx, session.ID)
+			if err != nil {
+				slog.Error("Failed to list messages", "session", session.ID, "error", err)
+				return
+			}
+			summary := SummarizeMessages(messages)
+			a.summaries.set(session, summary)
+			mu.Lock()
+			summaries[session.ID] = summary
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return summaries
+}
+
+func (a *App) RenameSession(ctx context.Context, sessionID string, title string) (*opencode.Session, error) {
+	session, err := a.Client.Session.Update(ctx, sessionID, opencode.SessionUpdateParams{
+		Title: opencode.F(title),
+	})
+	if err != nil {
+		slog.Error("Failed to rename session", "error", err)
+		return nil, err
+	}
+	return session, nil
+}
+
+// ShareSession creates a link to a session anyone can open, the session
+// keeps its link when it's shared again
+func (a *App) ShareSession(ctx context.Context, sessionID string) (*opencode.Session, error) {
+	session, err := a.Client.Session.Share(ctx, sessionID)
+	if err != nil {
+		slog.Error("Failed to share session", "error", err)
+		return nil, err
+	}
+	return session, nil
+}
+
+// UnshareSession removes the link to a session
+func (a *App) UnshareSession(ctx context.Context, sessionID string) (*opencode.Session, error) {
+	session, err := a.Client.Session.Unshare(ctx, sessionID)
+	if err != nil {
+		slog.Error("Failed to unshare session", "error", err)
+		return nil, err
+	}
+	return session, nil
+}
+
+// ForkSession copies the messages of a session up to and including the given
+// message into a new session
+func (a *App) ForkSession(ctx context.Context, sessionID string, messageID string) (*opencode.Session, error) {
+	session, err := a.Client.Session.Fork(ctx, sessionID, opencode.SessionForkParams{
+		MessageID: opencode.F(messageID),
+	})
+	if err != nil {
+		slog.Error("Failed to fork session", "error", err)
+		return nil, err
+	}
+	return session, nil
+}
+
+// PickSession returns the session with the given ID, or the most recently
+// updated top level session when the ID is empty
+func PickSession(sessions []opencode.Session, sessionID string) (opencode.Session, bool) {
+	var picked *opencode.Session
+	for i, session := range sessions {
+		if sessionID != "" {
+			if session.ID == sessionID {
+				return session, true
+			}
+			continue
+		}
+		// sessions started by the task tool belong to their parent
+		if session.ParentID != "" {
+			continue
+		}
+		if picked == nil || session.Time.Updated > picked.Time.Updated {
+			picked = &sessions[i]
+		}
+	}
+	if picked == nil {
+		return opencode.Session{}, false
+	}
+	return *picked, true
+}
+
+// ResumeSession opens a session along with its messages, the most recently
+// updated one when the ID is empty. It is used to start with an existing
+// session rather than a new one.
+func (a *App) ResumeSession(ctx context.Context, sessionID string) error {
+	sessions, err := a.ListSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	session, ok := PickSession(sessions, sessionID)
+	if !ok {
+		if sessionID == "" {
+			return errors.New("no session to continue")
+		}
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	messages, err := a.ListMessages(ctx, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list messages: %w", err)
+	}
+	a.OpenTab(&session, messages)
+	return nil
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func TestSummarizeMessages(t *testing.T) {
+	messages := []Message{
+		{
+			Info: opencode.UserMessage{ID: "msg_1"},
+			Parts: []opencode.PartUnion{
+				opencode.TextPart{Text: "context", Synthetic: true},
+				opencode.TextPart{Text: " fix the build "},
+			},
+		},
+		{Info: opencode.AssistantMessage{ID: "msg_2", Cost: 0.25}},
+		{
+			Info:  opencode.UserMessage{ID: "msg_3"},
+			Parts: []opencode.PartUnion{opencode.TextPart{Text: "thanks"}},
+		},
+		{Info: opencode.AssistantMessage{ID: "msg_4", Cost: 0.5}},
+	}
+
+	summary := SummarizeMessages(messages)
+	if summary.Messages != 4 {
+		t.Errorf("expected 4 messages, got %d", summary.Messages)
+	}
+	if summary.Cost != 0.75 {
+		t.Errorf("expected a cost of 0.75, got %f", summary.Cost)
+	}
+	if summary.FirstMessage != "fix the build" {
+		t.Errorf("expected the first user message, got %q", summary.FirstMessage)
+	}
+}
+
+func TestPickSession(t *testing.T) {
+	sessions := []opencode.Session{
+		{ID: "ses_1", Time: opencode.SessionTime{Updated: 10}},
+		{ID: "ses_2", Time: opencode.SessionTime{Updated: 30}},
+		{ID: "ses_3", ParentID: "ses_2", Time: opencode.SessionTime{Updated: 40}},
+		{ID: "ses_4", Time: opencode.SessionTime{Updated: 20}},
+	}
+
+	if session, ok := PickSession(sessions, ""); !ok || session.ID != "ses_2" {
+		t.Errorf("expected the latest top level session, got %q", session.ID)
+	}
+	if session, ok := PickSession(sessions, "ses_3"); !ok || session.ID != "ses_3" {
+		t.Errorf("expected the session with the given ID, got %q", session.ID)
+	}
+	if _, ok := PickSession(sessions, "ses_5"); ok {
+		t.Error("expected an unknown session not to be found")
+	}
+	if _, ok := PickSession(nil, ""); ok {
+		t.Error("expected no session to continue")
+	}
+}
+
+func TestSummaryCacheInvalidatedOnUpdate(t *testing.T) {
+	var cache summaryCache
+	session := opencode.Session{ID: "ses_1", Time: opencode.SessionTime{Updated: 10}}
+	if _, ok := cache.get(session); ok {
+		t.Fatal("expected an empty cache")
+	}
+
+	cache.set(session, SessionSummary{Messages: 2})
+	if summary, ok := cache.get(session); !ok || summary.Messages != 2 {
+		t.Errorf("expected the cached summary, got %+v", summary)
+	}
+	session.Time.Updated = 20
+	if _, ok := cache.get(session); ok {
+		t.Error("expected the summary of an updated session to be stale")
+	}
+}
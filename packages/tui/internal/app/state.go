@@ -31,7 +31,19 @@ type State struct {
 	RecentlyUsedModels []ModelUsage         `toml:"recently_used_models"`
 	MessagesRight      bool                 `toml:"messages_right"`
 	SplitDiff          bool                 `toml:"split_diff"`
-	MessageHistory     []Prompt             `toml:"message_history"`
+	VimMode            bool                 `toml:"vim_mode"`
+	// MessageHistory is the history shared by all projects in older versions,
+	// it's copied to PromptHistory for each project without a history
+	MessageHistory []Prompt                  `toml:"message_history,omitempty"`
+	PromptHistory  map[string][]HistoryEntry `toml:"prompt_history"`
+	HistorySize    int                       `toml:"history_size"`
+	SessionSort    string                    `toml:"session_sort"`
+	Budget         Budget                    `toml:"budget"`
+	Usage          Usage                     `toml:"usage"`
+	// KeySequenceTimeout is how long to wait for the next key of a key
+	// sequence in milliseconds, 0 waits until a key is pressed. The leader
+	// key alone always waits.
+	KeySequenceTimeout *int `toml:"key_sequence_timeout"`
 }
 
 func NewState() *State {
@@ -40,7 +52,7 @@ func NewState() *State {
 		Mode:               "build",
 		ModeModel:          make(map[string]ModeModel),
 		RecentlyUsedModels: make([]ModelUsage, 0),
-		MessageHistory:     make([]Prompt, 0),
+		PromptHistory:      make(map[string][]HistoryEntry),
 	}
 }
 
@@ -81,13 +93,6 @@ func (s *State) RemoveModelFromRecentlyUsed(providerID, modelID string) {
 	}
 }
 
-func (s *State) AddPromptToHistory(prompt Prompt) {
-	s.MessageHistory = append([]Prompt{prompt}, s.MessageHistory...)
-	if len(s.MessageHistory) > 50 {
-		s.MessageHistory = s.MessageHistory[:50]
-	}
-}
-
 // SaveState writes the provided Config struct to the specified TOML file.
 // It will create the file if it doesn't exist, or overwrite it if it does.
 func SaveState(filePath string, state *State) error {
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"slices"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+// Tab is a session kept open alongside the active one. While a tab is
+// focused its state lives in App.Session and App.Messages, it is written
+// back whenever another tab takes focus.
+type Tab struct {
+	Session  *opencode.Session
+	Messages []Message
+}
+
+func (t Tab) IsBusy() bool {
+	return isBusy(t.Messages)
+}
+
+// syncTab stores the active session state back into its tab
+func (a *App) syncTab() {
+	tab := a.Tabs[a.ActiveTab]
+	tab.Session = a.Session
+	tab.Messages = a.Messages
+}
+
+// activate focuses the tab at the given index
+func (a *App) activate(index int) {
+	a.syncTab()
+	a.ActiveTab = index
+	tab := a.Tabs[index]
+	a.Session = tab.Session
+	a.Messages = tab.Messages
+}
+
+// ListTabs returns a snapshot of the open tabs, including the latest state of
+// the active one
+func (a *App) ListTabs() []Tab {
+	tabs := make([]Tab, 0, len(a.Tabs))
+	for i, tab := range a.Tabs {
+		if i == a.ActiveTab {
+			tabs = append(tabs, Tab{Session: a.Session, Messages: a.Messages})
+			continue
+		}
+		tabs = append(tabs, *tab)
+	}
+	return tabs
+}
+
+// TabIndex returns the index of the tab holding the session, or -1 when the
+// session isn't open
+func (a *App) TabIndex(sessionID string) int {
+	if sessionID == "" {
+		return -1
+	}
+	if a.Session.ID == sessionID {
+		return a.ActiveTab
+	}
+	for i, tab := range a.Tabs {
+		if i != a.ActiveTab && tab.Session.ID == sessionID {
+			return i
+		}
+	}
+	return -1
+}
+
+// OpenTab focuses the session, opening it in a new tab when it isn't open yet.
+// A blank active tab is reused instead of opening another one.
+func (a *App) OpenTab(session *opencode.Session, messages []Message) {
+	if index := a.TabIndex(session.ID); index > -1 {
+		if index != a.ActiveTab {
+			a.activate(index)
+		}
+		return
+	}
+	a.countLoadedUsage(messages)
+	if a.Session.ID != "" {
+		a.syncTab()
+		a.Tabs = append(a.Tabs, &Tab{Session: session, Messages: messages})
+		a.ActiveTab = len(a.Tabs) - 1
+	}
+	a.Session = session
+	a.Messages = messages
+}
+
+// NewTab focuses a blank tab for a new session, returns false when the active
+// tab is already blank
+func (a *App) NewTab() bool {
+	if a.Session.ID == "" {
+		return false
+	}
+	a.OpenTab(&opencode.Session{}, []Message{})
+	return true
+}
+
+// CycleTab focuses the next or previous tab, returns false when there is
+// nothing to cycle to
+func (a *App) CycleTab(forward bool) bool {
+	if len(a.Tabs) < 2 {
+		return false
+	}
+	direction := 1
+	if !forward {
+		direction = -1
+	}
+	a.activate((a.ActiveTab + direction + len(a.Tabs)) % len(a.Tabs))
+	return true
+}
+
+// CloseTab closes the tab at the given index and focuses its neighbour if it
+// was active. Closing the last tab leaves a blank one behind.
+func (a *App) CloseTab(index int) {
+	if index < 0 || index >= len(a.Tabs) {
+		return
+	}
+	if len(a.Tabs) == 1 {
+		a.Session = &opencode.Session{}
+		a.Messages = []Message{}
+		a.syncTab()
+		return
+	}
+	a.syncTab()
+	a.Tabs = slices.Delete(a.Tabs, index, index+1)
+	if index < a.ActiveTab {
+		a.ActiveTab--
+		return
+	}
+	if index == a.ActiveTab {
+		a.ActiveTab = min(index, len(a.Tabs)-1)
+		tab := a.Tabs[a.ActiveTab]
+		a.Session = tab.Session
+		a.Messages = tab.Messages
+	}
+}
+
+// UpdateSession replaces the session info of an open tab
+func (a *App) UpdateSession(session opencode.Session) {
+	index := a.TabIndex(session.ID)
+	if index == -1 {
+		return
+	}
+	if index == a.ActiveTab {
+		a.Session = &session
+		return
+	}
+	a.Tabs[index].Session = &session
+}
+
+// UpdateMessages applies fn to the messages of an open session, whether it is
+// focused or running in a background tab
+func (a *App) UpdateMessages(sessionID string, fn func([]Message) []Message) bool {
+	index := a.TabIndex(sessionID)
+	if index == -1 {
+		return false
+	}
+	if index == a.ActiveTab {
+		a.Messages = fn(a.Messages)
+		return true
+	}
+	tab := a.Tabs[index]
+	tab.Messages = fn(tab.Messages)
+	return true
+}
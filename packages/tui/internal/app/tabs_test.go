@@ -0,0 +1,83 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func newTabsApp() *App {
+	session := &opencode.Session{}
+	messages := []Message{}
+	return &App{
+		Session:  session,
+		Messages: messages,
+		Tabs:     []*Tab{{Session: session, Messages: messages}},
+	}
+}
+
+func userMessage(id string) Message {
+	return Message{Info: opencode.UserMessage{ID: id}}
+}
+
+func TestOpenTabReusesBlankTab(t *testing.T) {
+	a := newTabsApp()
+	a.OpenTab(&opencode.Session{ID: "ses_1"}, []Message{userMessage("msg_1")})
+
+	if len(a.Tabs) != 1 {
+		t.Fatalf("expected blank tab to be reused, got %d tabs", len(a.Tabs))
+	}
+	if a.Session.ID != "ses_1" || len(a.Messages) != 1 {
+		t.Errorf("expected ses_1 to be active, got %q", a.Session.ID)
+	}
+}
+
+func TestBackgroundTabKeepsUpdating(t *testing.T) {
+	a := newTabsApp()
+	a.OpenTab(&opencode.Session{ID: "ses_1"}, []Message{})
+	a.OpenTab(&opencode.Session{ID: "ses_2"}, []Message{})
+
+	if len(a.Tabs) != 2 || a.ActiveTab != 1 {
+		t.Fatalf("expected ses_2 in a second active tab, got %d tabs active %d", len(a.Tabs), a.ActiveTab)
+	}
+
+	updated := a.UpdateMessages("ses_1", func(messages []Message) []Message {
+		return append(messages, userMessage("msg_1"))
+	})
+	if !updated {
+		t.Fatal("expected background tab to be updated")
+	}
+	if len(a.Messages) != 0 {
+		t.Errorf("expected active tab to be untouched, got %d messages", len(a.Messages))
+	}
+
+	a.CycleTab(true)
+	if a.Session.ID != "ses_1" || len(a.Messages) != 1 {
+		t.Errorf("expected ses_1 with 1 message after cycling, got %q with %d", a.Session.ID, len(a.Messages))
+	}
+	if a.UpdateMessages("ses_3", func(messages []Message) []Message { return messages }) {
+		t.Error("expected sessions that aren't open to be ignored")
+	}
+}
+
+func TestCloseTab(t *testing.T) {
+	a := newTabsApp()
+	a.OpenTab(&opencode.Session{ID: "ses_1"}, []Message{})
+	a.OpenTab(&opencode.Session{ID: "ses_2"}, []Message{})
+	a.OpenTab(&opencode.Session{ID: "ses_3"}, []Message{})
+
+	a.CloseTab(0)
+	if len(a.Tabs) != 2 || a.ActiveTab != 1 || a.Session.ID != "ses_3" {
+		t.Fatalf("expected ses_3 to stay active, got %q at %d", a.Session.ID, a.ActiveTab)
+	}
+
+	a.CloseTab(a.ActiveTab)
+	if a.Session.ID != "ses_2" {
+		t.Fatalf("expected neighbour ses_2 to be focused, got %q", a.Session.ID)
+	}
+
+	a.CloseTab(a.ActiveTab)
+	if len(a.Tabs) != 1 || a.Session.ID != "" {
+		t.Errorf("expected a single blank tab, got %d tabs with %q", len(a.Tabs), a.Session.ID)
+	}
+}
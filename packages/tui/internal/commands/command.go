@@ -2,8 +2,10 @@ package commands
 
 import (
 	"encoding/json"
+	"log/slog"
 	"slices"
 	"strings"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/sst/opencode-sdk-go"
@@ -13,11 +15,34 @@ type ExecuteCommandMsg Command
 type ExecuteCommandsMsg []Command
 type CommandExecutedMsg Command
 
+// Keybinding is a key, or a sequence of keys separated by spaces such as
+// "g s", pressed after the leader key if RequiresLeader is set
 type Keybinding struct {
 	RequiresLeader bool
 	Key            string
 }
 
+// Sequence returns the keys pressed one after another for the keybinding
+func (k Keybinding) Sequence() []string {
+	return strings.Fields(k.Key)
+}
+
+// HasPrefix returns whether the keybinding starts with the keys pressed so far
+func (k Keybinding) HasPrefix(keys []string, leader bool) bool {
+	sequence := k.Sequence()
+	return k.RequiresLeader == leader &&
+		len(sequence) >= len(keys) &&
+		slices.Equal(sequence[:len(keys)], keys)
+}
+
+// String formats the keybinding the way it's written in the config
+func (k Keybinding) String() string {
+	if k.RequiresLeader {
+		return "<leader>" + k.Key
+	}
+	return k.Key
+}
+
 func (k Keybinding) Matches(msg tea.KeyPressMsg, leader bool) bool {
 	key := k.Key
 	key = strings.TrimSpace(key)
@@ -29,7 +54,17 @@ type Command struct {
 	Name        CommandName
 	Description string
 	Keybindings []Keybinding
-	Trigger     []string
+	// DefaultKeybindings are the keybindings of the command unless the config
+	// binds it to other keys
+	DefaultKeybindings []Keybinding
+	Trigger            []string
+	// Args holds the arguments typed after the trigger, e.g. /export md out.md
+	Args []string
+	// Arguments is whether the command takes the text typed after its
+	// trigger, otherwise the text is sent as a prompt
+	Arguments bool
+	// Custom is the definition of a command defined by the user
+	Custom *CustomCommand
 }
 
 func (c Command) Keys() []string {
@@ -40,6 +75,20 @@ func (c Command) Keys() []string {
 	return keys
 }
 
+// PrimaryKey returns the first key bound to the command, if any
+func (c Command) PrimaryKey() string {
+	if len(c.Keybindings) > 0 {
+		return c.Keybindings[0].Key
+	}
+	return ""
+}
+
+// IsConfigured returns whether the config binds the command to other keys
+// than its defaults
+func (c Command) IsConfigured() bool {
+	return !slices.Equal(c.Keybindings, c.DefaultKeybindings)
+}
+
 func (c Command) HasTrigger() bool {
 	return len(c.Trigger) > 0
 }
@@ -94,6 +143,90 @@ func (r CommandRegistry) Sorted() []Command {
 	})
 	return commands
 }
+
+// Parse resolves input such as "/export html out.html" to the command with a
+// matching trigger and its arguments. Only commands taking arguments match
+// input with text after the trigger.
+func (r CommandRegistry) Parse(input string) (Command, bool) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "/") {
+		return Command{}, false
+	}
+	fields := strings.Fields(strings.TrimPrefix(input, "/"))
+	if len(fields) == 0 {
+		return Command{}, false
+	}
+	for _, command := range r.Sorted() {
+		if command.MatchesTrigger(fields[0]) {
+			// e.g. "/init the repo" is a prompt, not the init command
+			if len(fields) > 1 && !command.Arguments {
+				return Command{}, false
+			}
+			command.Args = fields[1:]
+			return command, true
+		}
+	}
+	return Command{}, false
+}
+
+// MatchSequence returns the commands bound to exactly the keys pressed so far,
+// and whether more keys may follow for a longer keybinding
+func (r CommandRegistry) MatchSequence(keys []string, leader bool) ([]Command, bool) {
+	var matched []Command
+	pending := false
+	for _, command := range r.Sorted() {
+		for _, binding := range command.Keybindings {
+			if !binding.HasPrefix(keys, leader) {
+				continue
+			}
+			if len(binding.Sequence()) > len(keys) {
+				pending = true
+			} else if !slices.ContainsFunc(matched, func(c Command) bool { return c.Name == command.Name }) {
+				matched = append(matched, command)
+			}
+		}
+	}
+	return matched, pending
+}
+
+// Continuation is a key that can follow the keys pressed so far, Commands are
+// bound to the sequence ending with it and More counts the longer keybindings
+// going through it
+type Continuation struct {
+	Key      string
+	Commands []Command
+	More     int
+}
+
+// Continuations returns the keys that can follow the keys pressed so far,
+// sorted by key
+func (r CommandRegistry) Continuations(keys []string, leader bool) []Continuation {
+	var continuations []Continuation
+	for _, command := range r.Sorted() {
+		for _, binding := range command.Keybindings {
+			sequence := binding.Sequence()
+			if len(sequence) <= len(keys) || !binding.HasPrefix(keys, leader) {
+				continue
+			}
+			next := sequence[len(keys)]
+			i := slices.IndexFunc(continuations, func(c Continuation) bool { return c.Key == next })
+			if i < 0 {
+				continuations = append(continuations, Continuation{Key: next})
+				i = len(continuations) - 1
+			}
+			if len(sequence) > len(keys)+1 {
+				continuations[i].More++
+			} else if !slices.ContainsFunc(continuations[i].Commands, func(c Command) bool { return c.Name == command.Name }) {
+				continuations[i].Commands = append(continuations[i].Commands, command)
+			}
+		}
+	}
+	slices.SortStableFunc(continuations, func(a, b Continuation) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return continuations
+}
+
 func (r CommandRegistry) Matches(msg tea.KeyPressMsg, leader bool) []Command {
 	var matched []Command
 	for _, command := range r.Sorted() {
@@ -104,11 +237,60 @@ func (r CommandRegistry) Matches(msg tea.KeyPressMsg, leader bool) []Command {
 	return matched
 }
 
+// Conflict is a keybinding shared by several commands, all of them are
+// executed when it's pressed
+type Conflict struct {
+	Keybinding Keybinding
+	Commands   []CommandName
+}
+
+// Conflicts returns the keybindings bound to more than one command, the
+// commands are in the order they are executed
+func (r CommandRegistry) Conflicts() []Conflict {
+	var conflicts []Conflict
+	index := map[Keybinding]int{}
+	for _, command := range r.Sorted() {
+		for _, binding := range command.Keybindings {
+			binding.Key = strings.TrimSpace(binding.Key)
+			i, ok := index[binding]
+			if !ok {
+				index[binding] = len(conflicts)
+				conflicts = append(conflicts, Conflict{Keybinding: binding})
+				i = len(conflicts) - 1
+			}
+			if !slices.Contains(conflicts[i].Commands, command.Name) {
+				conflicts[i].Commands = append(conflicts[i].Commands, command.Name)
+			}
+		}
+	}
+	return slices.DeleteFunc(conflicts, func(c Conflict) bool {
+		return len(c.Commands) < 2
+	})
+}
+
+// ConflictsOf returns the other commands sharing a keybinding with the command
+func (r CommandRegistry) ConflictsOf(name CommandName) []CommandName {
+	var names []CommandName
+	for _, conflict := range r.Conflicts() {
+		if !slices.Contains(conflict.Commands, name) {
+			continue
+		}
+		for _, other := range conflict.Commands {
+			if other != name && !slices.Contains(names, other) {
+				names = append(names, other)
+			}
+		}
+	}
+	return names
+}
+
 const (
 	AppHelpCommand              CommandName = "app_help"
+	KeybindsListCommand         CommandName = "keybinds_list"
 	SwitchModeCommand           CommandName = "switch_mode"
 	SwitchModeReverseCommand    CommandName = "switch_mode_reverse"
 	EditorOpenCommand           CommandName = "editor_open"
+	PromptTemplateCommand       CommandName = "prompt_template"
 	SessionNewCommand           CommandName = "session_new"
 	SessionListCommand          CommandName = "session_list"
 	SessionShareCommand         CommandName = "session_share"
@@ -116,28 +298,41 @@ const (
 	SessionInterruptCommand     CommandName = "session_interrupt"
 	SessionCompactCommand       CommandName = "session_compact"
 	SessionExportCommand        CommandName = "session_export"
+	SessionTabNextCommand       CommandName = "session_tab_next"
+	SessionTabPreviousCommand   CommandName = "session_tab_previous"
+	SessionTabCloseCommand      CommandName = "session_tab_close"
 	ToolDetailsCommand          CommandName = "tool_details"
 	ModelListCommand            CommandName = "model_list"
 	ThemeListCommand            CommandName = "theme_list"
 	FileListCommand             CommandName = "file_list"
 	FileCloseCommand            CommandName = "file_close"
 	FileSearchCommand           CommandName = "file_search"
+	FileGrepCommand             CommandName = "file_grep"
+	ChangesToggleCommand        CommandName = "changes_toggle"
 	FileDiffToggleCommand       CommandName = "file_diff_toggle"
+	DiagnosticsToggleCommand    CommandName = "diagnostics_toggle"
 	ProjectInitCommand          CommandName = "project_init"
 	InputClearCommand           CommandName = "input_clear"
 	InputPasteCommand           CommandName = "input_paste"
 	InputSubmitCommand          CommandName = "input_submit"
 	InputNewlineCommand         CommandName = "input_newline"
+	InputUndoCommand            CommandName = "input_undo"
+	InputRedoCommand            CommandName = "input_redo"
+	InputHistoryCommand         CommandName = "input_history"
+	InputVimToggleCommand       CommandName = "input_vim_toggle"
 	MessagesPageUpCommand       CommandName = "messages_page_up"
 	MessagesPageDownCommand     CommandName = "messages_page_down"
 	MessagesHalfPageUpCommand   CommandName = "messages_half_page_up"
 	MessagesHalfPageDownCommand CommandName = "messages_half_page_down"
 	MessagesPreviousCommand     CommandName = "messages_previous"
 	MessagesNextCommand         CommandName = "messages_next"
+	MessagesBlockToggleCommand  CommandName = "messages_block_toggle"
 	MessagesFirstCommand        CommandName = "messages_first"
 	MessagesLastCommand         CommandName = "messages_last"
 	MessagesLayoutToggleCommand CommandName = "messages_layout_toggle"
 	MessagesCopyCommand         CommandName = "messages_copy"
+	MessagesCopyCodeCommand     CommandName = "messages_copy_code"
+	MessagesActionsCommand      CommandName = "messages_actions"
 	MessagesUndoCommand         CommandName = "messages_undo"
 	MessagesRedoCommand         CommandName = "messages_redo"
 	AppExitCommand              CommandName = "app_exit"
@@ -156,9 +351,18 @@ func parseBindings(bindings ...string) []Keybinding {
 	var parsedBindings []Keybinding
 	for _, binding := range bindings {
 		for p := range strings.SplitSeq(binding, ",") {
+			p = strings.TrimSpace(p)
 			requireLeader := strings.HasPrefix(p, "<leader>")
-			keybinding := strings.ReplaceAll(p, "<leader>", "")
-			keybinding = strings.TrimSpace(keybinding)
+			keybinding := strings.TrimPrefix(p, "<leader>")
+			// the keys of a sequence are separated by spaces, e.g. "<leader>g s"
+			keys := strings.Fields(keybinding)
+			keybinding = strings.Join(keys, " ")
+			// keys typing text go to the input before the keybindings are
+			// matched, a keybinding like "g g" would never run
+			if !requireLeader && len(keys) > 0 && typesText(keys[0]) {
+				slog.Warn("Ignoring a keybinding starting with a key that types text", "keybinding", p)
+				continue
+			}
 			parsedBindings = append(parsedBindings, Keybinding{
 				RequiresLeader: requireLeader,
 				Key:            keybinding,
@@ -168,14 +372,39 @@ func parseBindings(bindings ...string) []Keybinding {
 	return parsedBindings
 }
 
-func LoadFromConfig(config *opencode.Config) CommandRegistry {
-	defaults := []Command{
+// typesText returns whether a key types text into the input, a character or
+// space
+func typesText(key string) bool {
+	return utf8.RuneCountInString(key) == 1 || key == "space"
+}
+
+// FormatBindings formats keybindings the way they're written in the config,
+// "none" when there are none
+func FormatBindings(bindings []Keybinding) string {
+	if len(bindings) == 0 {
+		return "none"
+	}
+	formatted := make([]string, len(bindings))
+	for i, binding := range bindings {
+		formatted[i] = binding.String()
+	}
+	return strings.Join(formatted, ",")
+}
+
+// defaultCommands returns the commands with their default keybindings
+func defaultCommands() []Command {
+	return []Command{
 		{
 			Name:        AppHelpCommand,
 			Description: "show help",
 			Keybindings: parseBindings("<leader>h"),
 			Trigger:     []string{"help"},
 		},
+		{
+			Name:        KeybindsListCommand,
+			Description: "edit keybindings",
+			Trigger:     []string{"keybinds"},
+		},
 		{
 			Name:        SwitchModeCommand,
 			Description: "next mode",
@@ -192,11 +421,18 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Keybindings: parseBindings("<leader>e"),
 			Trigger:     []string{"editor"},
 		},
+		{
+			Name:        PromptTemplateCommand,
+			Description: "use prompt template",
+			Trigger:     []string{"template"},
+			Arguments:   true,
+		},
 		{
 			Name:        SessionExportCommand,
 			Description: "export conversation",
 			Keybindings: parseBindings("<leader>x"),
 			Trigger:     []string{"export"},
+			Arguments:   true,
 		},
 		{
 			Name:        SessionNewCommand,
@@ -233,6 +469,22 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Keybindings: parseBindings("<leader>c"),
 			Trigger:     []string{"compact", "summarize"},
 		},
+		{
+			Name:        SessionTabNextCommand,
+			Description: "next session tab",
+			Keybindings: parseBindings("<leader>right"),
+		},
+		{
+			Name:        SessionTabPreviousCommand,
+			Description: "previous session tab",
+			Keybindings: parseBindings("<leader>left"),
+		},
+		{
+			Name:        SessionTabCloseCommand,
+			Description: "close session tab",
+			Keybindings: parseBindings("<leader>k"),
+			Trigger:     []string{"close"},
+		},
 		{
 			Name:        ToolDetailsCommand,
 			Description: "toggle tool details",
@@ -264,14 +516,33 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 		},
 		{
 			Name:        FileSearchCommand,
-			Description: "search file",
+			Description: "search conversation",
 			Keybindings: parseBindings("<leader>/"),
+			Trigger:     []string{"search"},
+		},
+		{
+			Name:        FileGrepCommand,
+			Description: "search project files",
+			Keybindings: parseBindings("<leader>g"),
+			Trigger:     []string{"grep"},
+		},
+		{
+			Name:        ChangesToggleCommand,
+			Description: "toggle changed files",
+			Keybindings: parseBindings("<leader>b"),
+			Trigger:     []string{"changes"},
 		},
 		{
 			Name:        FileDiffToggleCommand,
 			Description: "split/unified diff",
 			Keybindings: parseBindings("<leader>v"),
 		},
+		{
+			Name:        DiagnosticsToggleCommand,
+			Description: "toggle diagnostics",
+			Keybindings: parseBindings("<leader>w"),
+			Trigger:     []string{"diagnostics"},
+		},
 		{
 			Name:        ProjectInitCommand,
 			Description: "create/update AGENTS.md",
@@ -298,6 +569,27 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "insert newline",
 			Keybindings: parseBindings("shift+enter", "ctrl+j"),
 		},
+		{
+			Name:        InputUndoCommand,
+			Description: "undo input edit",
+			Keybindings: parseBindings("ctrl+z"),
+		},
+		{
+			Name:        InputRedoCommand,
+			Description: "redo input edit",
+			Keybindings: parseBindings("ctrl+shift+z"),
+		},
+		{
+			Name:        InputHistoryCommand,
+			Description: "search prompt history",
+			Keybindings: parseBindings("ctrl+r"),
+			Trigger:     []string{"history"},
+		},
+		{
+			Name:        InputVimToggleCommand,
+			Description: "toggle vim mode",
+			Trigger:     []string{"vim"},
+		},
 		{
 			Name:        MessagesPageUpCommand,
 			Description: "page up",
@@ -328,6 +620,11 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "next message",
 			Keybindings: parseBindings("ctrl+down"),
 		},
+		{
+			Name:        MessagesBlockToggleCommand,
+			Description: "expand tool output",
+			Keybindings: parseBindings("<leader>o"),
+		},
 		{
 			Name:        MessagesFirstCommand,
 			Description: "first message",
@@ -348,6 +645,16 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "copy message",
 			Keybindings: parseBindings("<leader>y"),
 		},
+		{
+			Name:        MessagesCopyCodeCommand,
+			Description: "copy code block",
+			Keybindings: parseBindings("<leader>j"),
+		},
+		{
+			Name:        MessagesActionsCommand,
+			Description: "message actions",
+			Keybindings: parseBindings("<leader>a"),
+		},
 		{
 			Name:        MessagesUndoCommand,
 			Description: "undo last message",
@@ -367,16 +674,20 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Trigger:     []string{"exit", "quit", "q"},
 		},
 	}
+}
+
+func LoadFromConfig(config *opencode.Config) CommandRegistry {
 	registry := make(CommandRegistry)
 	keybinds := map[string]string{}
 	marshalled, _ := json.Marshal(config.Keybinds)
 	json.Unmarshal(marshalled, &keybinds)
-	for _, command := range defaults {
+	for _, command := range defaultCommands() {
 		// Remove share/unshare commands if sharing is disabled
 		if config.Share == opencode.ConfigShareDisabled &&
 			(command.Name == SessionShareCommand || command.Name == SessionUnshareCommand) {
 			continue
 		}
+		command.DefaultKeybindings = command.Keybindings
 		if keybind, ok := keybinds[string(command.Name)]; ok && keybind != "" {
 			if keybind == "none" {
 				continue
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func TestLoadFromConfigRemovesNone(t *testing.T) {
+	registry := LoadFromConfig(&opencode.Config{Keybinds: opencode.KeybindsConfig{
+		SessionShare: "none",
+		ToolDetails:  "<leader>z,ctrl+t",
+	}})
+
+	if _, ok := registry[SessionShareCommand]; ok {
+		t.Errorf("expected the command set to none to be removed")
+	}
+	details := registry[ToolDetailsCommand]
+	if got := FormatBindings(details.Keybindings); got != "<leader>z,ctrl+t" || !details.IsConfigured() {
+		t.Errorf("expected the keys of the config, got %s", got)
+	}
+	if registry[ModelListCommand].IsConfigured() {
+		t.Errorf("expected the default keys without config")
+	}
+	if grep := registry[FileGrepCommand]; FormatBindings(grep.Keybindings) != "<leader>g" {
+		t.Errorf("expected the default keys of a command unset in the config, got %v", grep.Keybindings)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	registry := LoadFromConfig(&opencode.Config{Keybinds: opencode.KeybindsConfig{
+		SessionInterrupt: "ctrl+g",
+	}})
+
+	conflicts := map[string]string{}
+	for _, conflict := range registry.Conflicts() {
+		conflicts[conflict.Keybinding.String()] = fmt.Sprint(conflict.Commands)
+	}
+	if got := conflicts["<leader>u"]; got != "[messages_undo session_unshare]" {
+		t.Errorf("expected undo and unshare to conflict, got %s", got)
+	}
+	if got := conflicts["ctrl+c"]; got != "[input_clear app_exit]" {
+		t.Errorf("expected the commands in the order they are executed, got %s", got)
+	}
+	if got := conflicts["ctrl+g"]; got != "[messages_first session_interrupt]" {
+		t.Errorf("expected the configured keys to conflict, got %s", got)
+	}
+	if _, ok := conflicts["esc"]; ok {
+		t.Errorf("expected no conflict for a key bound once")
+	}
+	if got := fmt.Sprint(registry.ConflictsOf(AppExitCommand)); got != "[input_clear]" {
+		t.Errorf("expected the other commands bound to the keys, got %s", got)
+	}
+}
+
+func TestParseBindingsSequences(t *testing.T) {
+	bindings := parseBindings(" <leader>g  s, g g,ctrl+x, ctrl+g g")
+	expected := []Keybinding{
+		{RequiresLeader: true, Key: "g s"},
+		{Key: "ctrl+x"},
+		{Key: "ctrl+g g"},
+	}
+	// "g g" starts with a key that types text
+	if fmt.Sprint(bindings) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, bindings)
+	}
+	if got := FormatBindings(bindings); got != "<leader>g s,ctrl+x,ctrl+g g" {
+		t.Errorf("expected the bindings to format back, got %s", got)
+	}
+}
+
+func TestMatchSequence(t *testing.T) {
+	registry := LoadFromConfig(&opencode.Config{Keybinds: opencode.KeybindsConfig{
+		ModelList:     "<leader>g s",
+		MessagesFirst: "ctrl+g g",
+	}})
+
+	names := func(commands []Command) string {
+		var names []CommandName
+		for _, command := range commands {
+			names = append(names, command.Name)
+		}
+		return fmt.Sprint(names)
+	}
+	tests := []struct {
+		keys    []string
+		leader  bool
+		matched string
+		pending bool
+	}{
+		{[]string{"g"}, true, "[file_grep]", true},
+		{[]string{"g", "s"}, true, "[model_list]", false},
+		{[]string{"g", "x"}, true, "[]", false},
+		{[]string{"ctrl+g"}, false, "[]", true},
+		{[]string{"ctrl+g", "g"}, false, "[messages_first]", false},
+		{[]string{"g"}, false, "[]", false},
+	}
+	for _, test := range tests {
+		matched, pending := registry.MatchSequence(test.keys, test.leader)
+		if names(matched) != test.matched || pending != test.pending {
+			t.Errorf("%v (leader %t): expected %s pending %t, got %s pending %t",
+				test.keys, test.leader, test.matched, test.pending, names(matched), pending)
+		}
+	}
+
+	var continuations []string
+	for _, continuation := range registry.Continuations([]string{"g"}, true) {
+		continuations = append(continuations, fmt.Sprintf("%s:%s:%d", continuation.Key, names(continuation.Commands), continuation.More))
+	}
+	if got := fmt.Sprint(continuations); got != "[s:[model_list]:0]" {
+		t.Errorf("expected the keys following the prefix, got %s", got)
+	}
+	for _, continuation := range registry.Continuations(nil, true) {
+		if continuation.Key == "g" && (names(continuation.Commands) != "[file_grep]" || continuation.More != 1) {
+			t.Errorf("expected the prefix to run a command and lead to another, got %+v", continuation)
+		}
+	}
+}
+
+func TestParseOnlyPassesArgumentsToCommandsTakingThem(t *testing.T) {
+	registry := LoadFromConfig(&opencode.Config{})
+
+	command, ok := registry.Parse("/export md out.md")
+	if !ok || command.Name != SessionExportCommand || fmt.Sprint(command.Args) != "[md out.md]" {
+		t.Errorf("expected the export command with its arguments, got %v %v", command.Name, command.Args)
+	}
+	if command, ok := registry.Parse(" /init "); !ok || command.Name != ProjectInitCommand {
+		t.Errorf("expected the init command, got %v", command.Name)
+	}
+	for _, input := range []string{"/init the repo", "/q why is this slow", "/undo the last change"} {
+		if command, ok := registry.Parse(input); ok {
+			t.Errorf("expected %q to be a prompt, got %v", input, command.Name)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sst/opencode-sdk-go"
+)
+
+// customCommandPrefix prefixes the names of custom commands, so they never
+// clash with the built-in commands
+const customCommandPrefix = "custom_"
+
+var argumentPattern = regexp.MustCompile(`\{\{([1-9])\}\}`)
+
+// CustomCommand is a slash command defined in the config or in a toml file,
+// the file name without extension is its trigger. It sends a prompt, runs a
+// shell command and attaches its output, or runs built-in commands one after
+// another.
+type CustomCommand struct {
+	Name        string   `toml:"-"`
+	Path        string   `toml:"-"`
+	Description string   `toml:"description"`
+	Keybind     string   `toml:"keybind"`
+	Prompt      string   `toml:"prompt"`
+	Shell       string   `toml:"shell"`
+	Commands    []string `toml:"commands"`
+}
+
+// CustomCommandDirs returns the directories custom commands are loaded from,
+// from lowest to highest priority:
+// 1. USER_CONFIG/opencode/commands/*.toml
+// 2. PROJECT_ROOT/.opencode/commands/*.toml
+// 3. CWD/.opencode/commands/*.toml
+func CustomCommandDirs(userConfig, projectRoot, cwd string) []string {
+	dirs := []string{
+		filepath.Join(userConfig, "commands"),
+		filepath.Join(projectRoot, ".opencode", "commands"),
+	}
+	if cwd != projectRoot {
+		dirs = append(dirs, filepath.Join(cwd, ".opencode", "commands"))
+	}
+	return dirs
+}
+
+// LoadCustomCommands loads the custom commands of the config and then of the
+// directories, a command overrides those of the same name loaded before.
+// Invalid commands are skipped with a warning.
+//
+// The first directory is the user's, a shell command anywhere else can't
+// have a keybinding, so that a project can't bind a key to a shell command
+// of its own. The config may come from the project too.
+func LoadCustomCommands(config map[string]opencode.ConfigCommand, dirs []string) []CustomCommand {
+	loaded := map[string]CustomCommand{}
+	for name, c := range config {
+		command := CustomCommand{
+			Name:        name,
+			Path:        "config",
+			Description: c.Description,
+			Keybind:     c.Keybind,
+			Prompt:      c.Prompt,
+			Shell:       c.Shell,
+			Commands:    c.Commands,
+		}
+		if err := command.validate(); err != nil {
+			slog.Warn("Failed to load custom command", "command", name, "error", err)
+			continue
+		}
+		loaded[name] = untrustedShellKeybind(command)
+	}
+	for i, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				slog.Warn("Failed to load custom commands", "dir", dir, "error", err)
+			}
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".toml") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			command, err := loadCustomCommand(path)
+			if err != nil {
+				slog.Warn("Failed to load custom command", "file", path, "error", err)
+				continue
+			}
+			if i > 0 {
+				command = untrustedShellKeybind(command)
+			}
+			loaded[command.Name] = command
+		}
+	}
+
+	commands := make([]CustomCommand, 0, len(loaded))
+	for _, command := range loaded {
+		commands = append(commands, command)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+func loadCustomCommand(path string) (CustomCommand, error) {
+	var command CustomCommand
+	if _, err := toml.DecodeFile(path, &command); err != nil {
+		return command, err
+	}
+	command.Name = strings.TrimSuffix(filepath.Base(path), ".toml")
+	command.Path = path
+	return command, command.validate()
+}
+
+// validate checks the command and describes it by what it runs if it has
+// no description
+func (c *CustomCommand) validate() error {
+	if c.Name == "" || strings.ContainsAny(c.Name, " \t") {
+		return errors.New("the name can't be empty or contain spaces")
+	}
+	if c.Prompt == "" && c.Shell == "" && len(c.Commands) == 0 {
+		return errors.New("one of prompt, shell or commands is required")
+	}
+	if len(c.Commands) > 0 && (c.Prompt != "" || c.Shell != "") {
+		return errors.New("commands can't be combined with prompt or shell")
+	}
+	if c.Description == "" {
+		switch {
+		case c.Shell != "":
+			c.Description = "run " + c.Shell
+		case c.Prompt != "":
+			c.Description, _, _ = strings.Cut(strings.TrimSpace(c.Prompt), "\n")
+		default:
+			c.Description = "run " + strings.Join(c.Commands, ", ")
+		}
+	}
+	return nil
+}
+
+// untrustedShellKeybind removes the keybinding of a shell command that
+// doesn't come from the user's directory
+func untrustedShellKeybind(command CustomCommand) CustomCommand {
+	if command.Shell != "" && command.Keybind != "" && command.Keybind != "none" {
+		slog.Warn("Ignoring the keybinding of a project shell command", "file", command.Path, "command", command.Name)
+		command.Keybind = ""
+	}
+	return command
+}
+
+// TakesArguments returns whether the prompt of the command refers to its
+// arguments
+func (c CustomCommand) TakesArguments() bool {
+	return strings.Contains(c.Prompt, "{{args}}") || argumentPattern.MatchString(c.Prompt)
+}
+
+// ExpandPrompt replaces {{args}} in the prompt with all the arguments and
+// {{1}}, {{2}}... with each of them. The arguments are appended to a prompt
+// that doesn't refer to them.
+func (c CustomCommand) ExpandPrompt(args []string) string {
+	if !c.TakesArguments() {
+		return strings.TrimSpace(strings.Join(append([]string{c.Prompt}, args...), " "))
+	}
+	prompt := strings.ReplaceAll(c.Prompt, "{{args}}", strings.Join(args, " "))
+	return argumentPattern.ReplaceAllStringFunc(prompt, func(match string) string {
+		index, _ := strconv.Atoi(argumentPattern.FindStringSubmatch(match)[1])
+		if index > 0 && index <= len(args) {
+			return args[index-1]
+		}
+		return ""
+	})
+}
+
+// RunShell runs the shell command in the directory and returns its output,
+// the arguments are passed as positional parameters, $1, $2 or "$@"
+func (c CustomCommand) RunShell(ctx context.Context, dir string, args []string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", append([]string{"/C", c.Shell}, args...)...)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", append([]string{"-c", c.Shell, c.Name}, args...)...)
+	}
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("%s: %w", c.Shell, err)
+	}
+	return string(output), nil
+}
+
+// SetCustomCommands replaces the custom commands of the registry, a custom
+// command can't take the trigger of a built-in command, nor its keys
+func (r CommandRegistry) SetCustomCommands(custom []CustomCommand) {
+	var builtin []Keybinding
+	for name, command := range r {
+		if command.Custom != nil {
+			delete(r, name)
+		} else {
+			builtin = append(builtin, command.Keybindings...)
+		}
+	}
+	for _, c := range custom {
+		if existing, ok := r.Parse("/" + c.Name); ok {
+			slog.Warn("Custom command clashes with a built-in command", "file", c.Path, "command", existing.Name)
+			continue
+		}
+		command := Command{
+			Name:        CommandName(customCommandPrefix + c.Name),
+			Description: c.Description,
+			Trigger:     []string{c.Name},
+			Arguments:   c.Prompt != "" || c.Shell != "",
+			Custom:      &c,
+		}
+		if c.Keybind != "" && c.Keybind != "none" {
+			for _, binding := range parseBindings(c.Keybind) {
+				if slices.Contains(builtin, binding) {
+					slog.Warn("Custom keybinding clashes with a built-in keybinding",
+						"file", c.Path, "command", c.Name, "keys", binding.String())
+					continue
+				}
+				command.Keybindings = append(command.Keybindings, binding)
+			}
+			command.DefaultKeybindings = slices.Clone(command.Keybindings)
+		}
+		r[command.Name] = command
+	}
+}
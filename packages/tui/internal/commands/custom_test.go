@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func writeCustomCommand(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadCustomCommandsOverridesAndValidates(t *testing.T) {
+	user := t.TempDir()
+	project := t.TempDir()
+	writeCustomCommand(t, user, "review.toml", "prompt = \"Review {{args}}\"\n")
+	writeCustomCommand(t, user, "status.toml", "shell = \"git status\"\nkeybind = \"<leader>s\"\n")
+	writeCustomCommand(t, project, "review.toml", "description = \"Review the diff\"\nprompt = \"Review the diff\"\n")
+	writeCustomCommand(t, project, "empty.toml", "description = \"Nothing to do\"\n")
+	writeCustomCommand(t, project, "both.toml", "prompt = \"x\"\ncommands = [\"session_new\"]\n")
+	writeCustomCommand(t, project, "notes.md", "prompt = \"ignored\"\n")
+	writeCustomCommand(t, project, "deploy.toml", "shell = \"make deploy\"\nkeybind = \"<leader>p\"\n")
+
+	config := map[string]opencode.ConfigCommand{
+		"status": {Prompt: "overridden by the file"},
+		"fresh":  {Commands: []string{"session_new"}, Keybind: "<leader>f"},
+		"lint":   {Shell: "make lint", Keybind: "<leader>l"},
+		"bad":    {},
+	}
+	custom := LoadCustomCommands(config, []string{user, project, filepath.Join(project, "missing")})
+
+	names := []string{}
+	for _, command := range custom {
+		names = append(names, command.Name)
+	}
+	if !slices.Equal(names, []string{"deploy", "fresh", "lint", "review", "status"}) {
+		t.Fatalf("expected the valid commands sorted by name, got %v", names)
+	}
+	if custom[3].Description != "Review the diff" {
+		t.Errorf("expected the project command to override the user one, got %q", custom[3].Description)
+	}
+	if custom[4].Description != "run git status" || custom[4].Keybind != "<leader>s" {
+		t.Errorf("expected the file to override the config and keep its keys, got %+v", custom[4])
+	}
+	if custom[1].Keybind != "<leader>f" || custom[1].Path != "config" {
+		t.Errorf("unexpected config command %+v", custom[1])
+	}
+	if custom[0].Keybind != "" || custom[2].Keybind != "" {
+		t.Errorf("expected the keys of shell commands outside the user's directory to be ignored")
+	}
+}
+
+func TestExpandPrompt(t *testing.T) {
+	tests := []struct {
+		prompt   string
+		args     []string
+		expected string
+	}{
+		{"Explain {{args}} in detail", []string{"the", "parser"}, "Explain the parser in detail"},
+		{"Port {{1}} to {{2}}{{3}}", []string{"main.go", "rust"}, "Port main.go to rust"},
+		{"Write the release notes", []string{"for", "v2"}, "Write the release notes for v2"},
+		{"Write the release notes", nil, "Write the release notes"},
+	}
+	for _, test := range tests {
+		got := CustomCommand{Prompt: test.prompt}.ExpandPrompt(test.args)
+		if got != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, got)
+		}
+	}
+}
+
+func TestSetCustomCommands(t *testing.T) {
+	registry := LoadFromConfig(&opencode.Config{})
+	registry.SetCustomCommands([]CustomCommand{
+		{Name: "review", Prompt: "Review {{args}}", Keybind: "ctrl+alt+r"},
+		{Name: "new", Prompt: "clashes with /new"},
+	})
+
+	command, ok := registry.Parse("/review the parser")
+	if !ok || command.Custom == nil || command.Name != "custom_review" {
+		t.Fatalf("expected the custom command to be parsed, got %+v", command)
+	}
+	if len(command.Args) != 2 || FormatBindings(command.Keybindings) != "ctrl+alt+r" {
+		t.Errorf("unexpected args %v or keys %v", command.Args, command.Keybindings)
+	}
+	if command, _ := registry.Parse("/new"); command.Custom != nil {
+		t.Errorf("expected the built-in command to keep its trigger")
+	}
+
+	registry.SetCustomCommands([]CustomCommand{
+		{Name: "send", Prompt: "clashes with enter", Keybind: "enter,<leader>z"},
+	})
+	if command := registry["custom_send"]; FormatBindings(command.Keybindings) != "<leader>z" {
+		t.Errorf("expected the keys of built-in commands to be refused, got %v", command.Keybindings)
+	}
+
+	registry.SetCustomCommands(nil)
+	if _, ok := registry.Parse("/review"); ok {
+		t.Errorf("expected the custom commands to be removed")
+	}
+}
+
+func TestRunShellPassesArguments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the arguments are positional parameters of sh")
+	}
+	dir := t.TempDir()
+	command := CustomCommand{Name: "greet", Shell: `echo "$1 from $(basename "$PWD")"`}
+
+	output, err := command.RunShell(context.Background(), dir, []string{"hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "hello from " + filepath.Base(dir) + "\n"; output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
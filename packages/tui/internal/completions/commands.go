@@ -42,6 +42,9 @@ func (c *CommandCompletionProvider) getCommandCompletionItem(
 	}
 
 	value := string(cmd.Name)
+	if len(cmd.Args) > 0 {
+		value += ":" + strings.Join(cmd.Args, " ")
+	}
 	return CompletionSuggestion{
 		Display:    displayFunc,
 		Value:      value,
@@ -50,20 +53,37 @@ func (c *CommandCompletionProvider) getCommandCompletionItem(
 	}
 }
 
+// templateCommands lists a template command for each prompt template, with
+// the name of the template as argument
+func (c *CommandCompletionProvider) templateCommands() []commands.Command {
+	command, ok := c.app.Commands[commands.PromptTemplateCommand]
+	if !ok {
+		return nil
+	}
+	result := []commands.Command{}
+	for _, template := range c.app.Templates {
+		cmd := command
+		cmd.Trigger = []string{command.PrimaryTrigger() + " " + template.Name}
+		cmd.Description = template.Description
+		cmd.Args = []string{template.Name}
+		result = append(result, cmd)
+	}
+	return result
+}
+
 func (c *CommandCompletionProvider) GetChildEntries(
 	query string,
 ) ([]CompletionSuggestion, error) {
-	commands := c.app.Commands
+	sorted := append(c.app.Commands.Sorted(), c.templateCommands()...)
 
 	space := 1
-	for _, cmd := range c.app.Commands {
+	for _, cmd := range sorted {
 		if cmd.HasTrigger() && lipgloss.Width(cmd.PrimaryTrigger()) > space {
 			space = lipgloss.Width(cmd.PrimaryTrigger())
 		}
 	}
 	space += 2
 
-	sorted := commands.Sorted()
 	if query == "" {
 		// If no query, return all commands
 		items := []CompletionSuggestion{}
@@ -0,0 +1,293 @@
+package changes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// Width is the width of the sidebar, border included
+const Width = 40
+
+// refreshDebounce groups the bursts of file events sent while the agent edits
+// several files into a single refresh
+const refreshDebounce = 250 * time.Millisecond
+
+// ChangeSelectedMsg is sent when a changed file is picked to show its diff
+type ChangeSelectedMsg struct {
+	Path string
+}
+
+type refreshMsg struct {
+	generation int
+}
+
+type changesLoadedMsg struct {
+	generation int
+	files      []opencode.File
+	err        error
+}
+
+// Model is a sidebar listing the files changed in the working tree, with
+// their git status and the lines added and removed
+type Model struct {
+	app        *app.App
+	height     int
+	visible    bool
+	focused    bool
+	files      []opencode.File
+	selected   int
+	offset     int
+	generation int
+	loaded     bool
+	err        error
+}
+
+func New(app *app.App) Model {
+	return Model{app: app}
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case refreshMsg:
+		// a newer refresh is pending
+		if msg.generation != m.generation {
+			return m, nil
+		}
+		return m, m.load()
+	case changesLoadedMsg:
+		if msg.generation != m.generation {
+			return m, nil
+		}
+		m.loaded = true
+		m.err = msg.err
+		if msg.err == nil {
+			m.files = msg.files
+		}
+		m.selected = max(0, min(m.selected, len(m.files)-1))
+		m.scroll()
+		return m, nil
+	case tea.KeyPressMsg:
+		if !m.focused {
+			return m, nil
+		}
+		switch msg.String() {
+		case "up", "k", "ctrl+p":
+			m.selected = max(0, m.selected-1)
+			m.scroll()
+		case "down", "j", "ctrl+n":
+			m.selected = max(0, min(m.selected+1, len(m.files)-1))
+			m.scroll()
+		case "enter":
+			if m.selected >= len(m.files) {
+				return m, nil
+			}
+			file := m.files[m.selected]
+			if file.Status == opencode.FileStatusDeleted {
+				return m, toast.NewInfoToast(file.Path + " was deleted")
+			}
+			return m, util.CmdHandler(ChangeSelectedMsg{Path: file.Path})
+		case "esc":
+			m.focused = false
+		}
+	}
+	return m, nil
+}
+
+// load fetches the status of the working tree
+func (m Model) load() tea.Cmd {
+	generation := m.generation
+	return func() tea.Msg {
+		status, err := m.app.Client.File.Status(context.Background())
+		if err != nil {
+			return changesLoadedMsg{generation: generation, err: err}
+		}
+		files := []opencode.File{}
+		if status != nil {
+			files = *status
+		}
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Path < files[j].Path
+		})
+		return changesLoadedMsg{generation: generation, files: files}
+	}
+}
+
+// Refresh reloads the changed files shortly, once the events for a batch of
+// edits have settled. Nothing is loaded while the sidebar is hidden.
+func (m *Model) Refresh() (Model, tea.Cmd) {
+	if !m.visible {
+		return *m, nil
+	}
+	m.generation++
+	generation := m.generation
+	return *m, tea.Tick(refreshDebounce, func(time.Time) tea.Msg {
+		return refreshMsg{generation: generation}
+	})
+}
+
+// Toggle shows and focuses the sidebar, or hides it when it is shown
+func (m *Model) Toggle() (Model, tea.Cmd) {
+	if m.visible {
+		m.visible = false
+		m.focused = false
+		return *m, nil
+	}
+	m.visible = true
+	m.focused = true
+	m.generation++
+	return *m, m.load()
+}
+
+func (m Model) Visible() bool {
+	return m.visible
+}
+
+func (m Model) Focused() bool {
+	return m.visible && m.focused
+}
+
+func (m *Model) Blur() {
+	m.focused = false
+}
+
+func (m *Model) SetHeight(height int) {
+	m.height = height
+	m.scroll()
+}
+
+// listHeight is the number of files that fit below the header and above
+// the totals
+func (m Model) listHeight() int {
+	return max(1, m.height-4)
+}
+
+// scroll keeps the selected file in view
+func (m *Model) scroll() {
+	height := m.listHeight()
+	if m.selected < m.offset {
+		m.offset = m.selected
+	}
+	if m.selected >= m.offset+height {
+		m.offset = m.selected - height + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.files)-height))
+}
+
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	t := theme.CurrentTheme()
+	width := Width - 2
+	background := t.Background()
+	base := styles.NewStyle().Background(background)
+	muted := base.Foreground(t.TextMuted()).Render
+
+	title := base.Foreground(t.Text()).Bold(true).Render("Changes")
+	switch len(m.files) {
+	case 0:
+	case 1:
+		title += muted(" 1 file")
+	default:
+		title += muted(fmt.Sprintf(" %d files", len(m.files)))
+	}
+	lines := []string{title, ""}
+
+	switch {
+	case !m.loaded:
+		lines = append(lines, muted("Loading..."))
+	case m.err != nil:
+		lines = append(lines, base.Foreground(t.Error()).Render("Failed to load changes"))
+	case len(m.files) == 0:
+		lines = append(lines, muted("No changes"))
+	}
+
+	var added, removed int64
+	for _, file := range m.files {
+		added += file.Added
+		removed += file.Removed
+	}
+	end := min(len(m.files), m.offset+m.listHeight())
+	for i := m.offset; i < end; i++ {
+		lines = append(lines, m.renderFile(m.files[i], i == m.selected, width))
+	}
+
+	content := lipgloss.PlaceVertical(
+		max(0, m.height-1),
+		lipgloss.Top,
+		strings.Join(lines, "\n"),
+		styles.WhitespaceStyle(background),
+	)
+	totals := base.Foreground(t.Success()).Render(fmt.Sprintf("+%d", added)) +
+		base.Render(" ") +
+		base.Foreground(t.Error()).Render(fmt.Sprintf("-%d", removed))
+	if m.focused {
+		totals += muted("  enter diff  esc back")
+	}
+
+	borderColor := t.Border()
+	if m.focused {
+		borderColor = t.Primary()
+	}
+	return styles.NewStyle().
+		Background(background).
+		Width(Width).
+		Height(m.height).
+		PaddingLeft(1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(borderColor).
+		BorderBackground(background).
+		Render(content + "\n" + totals)
+}
+
+// renderFile renders a file with its status badge and line counts, long
+// paths are cut from the left to keep the file name visible
+func (m Model) renderFile(file opencode.File, selected bool, width int) string {
+	t := theme.CurrentTheme()
+	background := t.Background()
+	if selected && m.focused {
+		background = t.BackgroundElement()
+	}
+	base := styles.NewStyle().Background(background)
+
+	badge, color := "M", t.Warning()
+	switch file.Status {
+	case opencode.FileStatusAdded:
+		badge, color = "A", t.Success()
+	case opencode.FileStatusDeleted:
+		badge, color = "D", t.Error()
+	}
+
+	counts := base.Foreground(t.Success()).Render(fmt.Sprintf("+%d", file.Added)) +
+		base.Render(" ") +
+		base.Foreground(t.Error()).Render(fmt.Sprintf("-%d", file.Removed))
+
+	pathStyle := base.Foreground(t.Text())
+	if selected {
+		pathStyle = pathStyle.Foreground(t.Primary())
+	}
+	path := file.Path
+	available := max(1, width-ansi.StringWidth(counts)-4)
+	if overflow := ansi.StringWidth(path) - available; overflow > 0 {
+		path = ansi.TruncateLeft(path, overflow+1, "…")
+	}
+
+	left := base.Foreground(color).Bold(true).Render(badge) + base.Render(" ") + pathStyle.Render(path)
+	gap := max(1, width-ansi.StringWidth(left)-ansi.StringWidth(counts)-1)
+	return left + base.Render(strings.Repeat(" ", gap)) + counts + base.Render(" ")
+}
@@ -0,0 +1,219 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/dialog"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/util"
+)
+
+// findPart returns the index of the message holding a text or tool part,
+// along with the part
+func (m *messagesComponent) findPart(partID string) (int, opencode.PartUnion, bool) {
+	for i, message := range m.app.Messages {
+		for _, part := range message.Parts {
+			switch casted := part.(type) {
+			case opencode.TextPart:
+				if casted.ID == partID {
+					return i, part, true
+				}
+			case opencode.ToolPart:
+				if casted.ID == partID {
+					return i, part, true
+				}
+			}
+		}
+	}
+	return -1, nil, false
+}
+
+// Actions returns the part under the cursor and the actions available for
+// it, none when there is no cursor
+func (m *messagesComponent) Actions() (string, []dialog.MessageAction) {
+	index, part, ok := m.findPart(m.cursor)
+	if !ok {
+		return "", nil
+	}
+
+	actions := []dialog.MessageAction{}
+	switch part := part.(type) {
+	case opencode.TextPart:
+		actions = append(actions, dialog.MessageAction{
+			Kind:  dialog.MessageActionCopy,
+			Title: "Copy message",
+		})
+		for i, block := range util.CodeBlocks(part.Text) {
+			detail := block.FirstLine()
+			if block.Language != "" {
+				detail = block.Language + "  " + detail
+			}
+			actions = append(actions, dialog.MessageAction{
+				Kind:   dialog.MessageActionCopyCode,
+				Title:  fmt.Sprintf("Copy code block %d", i+1),
+				Detail: detail,
+				Index:  i,
+			})
+		}
+		if _, ok := m.app.Messages[index].Info.(opencode.UserMessage); ok {
+			actions = append(actions, dialog.MessageAction{
+				Kind:   dialog.MessageActionEdit,
+				Title:  "Edit and resend",
+				Detail: "reverts the messages after it",
+			})
+		}
+	case opencode.ToolPart:
+		if part.State.Output != "" || part.State.Error != "" {
+			actions = append(actions, dialog.MessageAction{
+				Kind:  dialog.MessageActionCopyOutput,
+				Title: "Copy output",
+			})
+		}
+	}
+	if m.nextUserMessage(index) >= 0 {
+		actions = append(actions, dialog.MessageAction{
+			Kind:   dialog.MessageActionRevert,
+			Title:  "Revert to here",
+			Detail: "undoes the later messages and their changes",
+		})
+	}
+	return m.cursor, actions
+}
+
+// nextUserMessage returns the index of the first user message after a
+// message, -1 when there is none
+func (m *messagesComponent) nextUserMessage(index int) int {
+	for i := index + 1; i < len(m.app.Messages); i++ {
+		if _, ok := m.app.Messages[i].Info.(opencode.UserMessage); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// runAction runs an action picked from the actions menu of a part
+func (m *messagesComponent) runAction(msg dialog.MessageActionSelectedMsg) tea.Cmd {
+	index, part, ok := m.findPart(msg.PartID)
+	if !ok {
+		return toast.NewErrorToast("The message no longer exists")
+	}
+
+	switch msg.Action.Kind {
+	case dialog.MessageActionCopy:
+		if text, ok := part.(opencode.TextPart); ok {
+			return tea.Batch(
+				app.SetClipboard(text.Text),
+				toast.NewSuccessToast("Message copied to clipboard"),
+			)
+		}
+	case dialog.MessageActionCopyCode:
+		text, ok := part.(opencode.TextPart)
+		if !ok {
+			return nil
+		}
+		blocks := util.CodeBlocks(text.Text)
+		if msg.Action.Index >= len(blocks) {
+			return toast.NewErrorToast("The code block no longer exists")
+		}
+		return tea.Batch(
+			app.SetClipboard(blocks[msg.Action.Index].Code),
+			toast.NewSuccessToast("Code block copied to clipboard"),
+		)
+	case dialog.MessageActionCopyOutput:
+		if tool, ok := part.(opencode.ToolPart); ok {
+			output := tool.State.Output
+			if tool.State.Status == opencode.ToolPartStateStatusError {
+				output = tool.State.Error
+			}
+			return tea.Batch(
+				app.SetClipboard(output),
+				toast.NewSuccessToast("Output copied to clipboard"),
+			)
+		}
+	case dialog.MessageActionEdit:
+		// reverting a user message puts it back in the editor
+		message := m.app.Messages[index]
+		return m.revert(message.Info, message)
+	case dialog.MessageActionRevert:
+		next := m.nextUserMessage(index)
+		if next < 0 {
+			return toast.NewInfoToast("Nothing to revert after this message")
+		}
+		// the editor is left alone, the reverted message isn't being edited
+		return m.revert(m.app.Messages[next].Info, app.Message{})
+	}
+	return nil
+}
+
+// revert reverts the session from a user message, restoring the workspace to
+// its state before the message
+func (m *messagesComponent) revert(info opencode.MessageUnion, reverted app.Message) tea.Cmd {
+	user, ok := info.(opencode.UserMessage)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		response, err := m.app.Client.Session.Revert(
+			context.Background(),
+			m.app.Session.ID,
+			opencode.SessionRevertParams{
+				MessageID: opencode.F(user.ID),
+			},
+		)
+		if err != nil {
+			slog.Error("Failed to revert message", "error", err)
+			return toast.NewErrorToast("Failed to revert message")
+		}
+		if response == nil {
+			return toast.NewErrorToast("Failed to revert message")
+		}
+		return app.MessageRevertedMsg{Session: *response, Message: reverted}
+	}
+}
+
+// CodeBlocks returns the code blocks of the assistant reply under the cursor,
+// or of the latest reply with code blocks when the cursor isn't on one
+func (m *messagesComponent) CodeBlocks() []util.CodeBlock {
+	if index, part, ok := m.findPart(m.cursor); ok {
+		if _, assistant := m.app.Messages[index].Info.(opencode.AssistantMessage); assistant {
+			if text, ok := part.(opencode.TextPart); ok {
+				return util.CodeBlocks(text.Text)
+			}
+		}
+	}
+
+	// reverted messages aren't shown
+	end := len(m.app.Messages)
+	for i, message := range m.app.Messages {
+		id := ""
+		switch casted := message.Info.(type) {
+		case opencode.UserMessage:
+			id = casted.ID
+		case opencode.AssistantMessage:
+			id = casted.ID
+		}
+		if id != "" && id == m.app.Session.Revert.MessageID {
+			end = i
+			break
+		}
+	}
+	for _, message := range slices.Backward(m.app.Messages[:end]) {
+		if _, ok := message.Info.(opencode.AssistantMessage); !ok {
+			continue
+		}
+		for _, part := range slices.Backward(message.Parts) {
+			if text, ok := part.(opencode.TextPart); ok {
+				if blocks := util.CodeBlocks(text.Text); len(blocks) > 0 {
+					return blocks
+				}
+			}
+		}
+	}
+	return nil
+}
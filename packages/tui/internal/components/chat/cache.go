@@ -4,19 +4,45 @@ import (
 	"encoding/hex"
 	"fmt"
 	"hash/fnv"
+	"strings"
 	"sync"
+
+	"github.com/charmbracelet/lipgloss/v2"
 )
 
-// PartCache caches rendered messages to avoid re-rendering
+// renderedPart is a part rendered into lines, kept until what it was rendered
+// from changes
+type renderedPart struct {
+	// key identifies what the part was rendered from
+	key   string
+	lines []string
+	// width is the width of the longest line
+	width int
+}
+
+func newRenderedPart(key string, content string) renderedPart {
+	lines := strings.Split(content, "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, lipgloss.Width(line))
+	}
+	return renderedPart{key: key, lines: lines, width: width}
+}
+
+// PartCache keeps the rendered lines of each part so only the parts that
+// changed are rendered again, along with the parts expanded by the user which
+// outlive the rendered entries
 type PartCache struct {
-	mu    sync.RWMutex
-	cache map[string]string
+	mu       sync.RWMutex
+	parts    map[string]renderedPart
+	expanded map[string]bool
 }
 
 // NewPartCache creates a new message cache
 func NewPartCache() *PartCache {
 	return &PartCache{
-		cache: make(map[string]string),
+		parts:    make(map[string]renderedPart),
+		expanded: make(map[string]bool),
 	}
 }
 
@@ -29,28 +55,33 @@ func (c *PartCache) GenerateKey(params ...any) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// Get retrieves a cached rendered message
-func (c *PartCache) Get(key string) (string, bool) {
+// Get retrieves the rendered lines of a part, as long as it was rendered
+// from the same key
+func (c *PartCache) Get(id string, key string) (renderedPart, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	content, exists := c.cache[key]
-	return content, exists
+	part, exists := c.parts[id]
+	return part, exists && part.key == key
 }
 
-// Set stores a rendered message in the cache
-func (c *PartCache) Set(key string, content string) {
+// Set stores the rendered content of a part, replacing what it was rendered
+// to before
+func (c *PartCache) Set(id string, key string, content string) renderedPart {
+	part := newRenderedPart(key, content)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.cache[key] = content
+	c.parts[id] = part
+	return part
 }
 
-// Clear removes all entries from the cache
+// Clear removes all entries from the cache, the expanded parts are kept
 func (c *PartCache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.cache = make(map[string]string)
+	c.parts = make(map[string]renderedPart)
 }
 
 // Size returns the number of cached entries
@@ -58,5 +89,25 @@ func (c *PartCache) Size() int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return len(c.cache)
+	return len(c.parts)
+}
+
+// Expanded reports whether a part was expanded to show its full output
+func (c *PartCache) Expanded(partID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.expanded[partID]
+}
+
+// SetExpanded expands or collapses a part
+func (c *PartCache) SetExpanded(partID string, expanded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if expanded {
+		c.expanded[partID] = true
+	} else {
+		delete(c.expanded, partID)
+	}
 }
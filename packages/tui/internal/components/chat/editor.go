@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/v2/spinner"
 	tea "github.com/charmbracelet/bubbletea/v2"
@@ -22,6 +23,7 @@ import (
 	"github.com/sst/opencode/internal/components/textarea"
 	"github.com/sst/opencode/internal/components/toast"
 	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/templates"
 	"github.com/sst/opencode/internal/theme"
 	"github.com/sst/opencode/internal/util"
 )
@@ -40,11 +42,19 @@ type EditorComponent interface {
 	Clear() (tea.Model, tea.Cmd)
 	Paste() (tea.Model, tea.Cmd)
 	Newline() (tea.Model, tea.Cmd)
+	Undo() (tea.Model, tea.Cmd)
+	Redo() (tea.Model, tea.Cmd)
 	SetValue(value string)
 	SetValueWithAttachments(value string)
 	SetInterruptKeyInDebounce(inDebounce bool)
 	SetExitKeyInDebounce(inDebounce bool)
+	SetVimEnabled(enabled bool)
+	SetWidth(width int)
+	VimCaptures(msg tea.KeyPressMsg) bool
 	RestoreFromHistory(index int)
+	RestoreFromPrompt(prompt app.Prompt)
+	StartTemplate(template templates.Template) (tea.Model, tea.Cmd)
+	InsertAttachment(attachment *attachment.Attachment) (tea.Model, tea.Cmd)
 }
 
 type editorComponent struct {
@@ -58,6 +68,16 @@ type editorComponent struct {
 	currentText            string // Store current text when navigating history
 	pasteCounter           int
 	reverted               bool
+	template               *templateFill
+}
+
+// templateFill holds the values entered so far for the variables of a prompt
+// template, one variable is prompted for at a time
+type templateFill struct {
+	template  templates.Template
+	variables []string
+	values    map[string]string
+	index     int
 }
 
 func (m *editorComponent) Init() tea.Cmd {
@@ -69,26 +89,27 @@ func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		m.width = msg.Width - 4
-		return m, nil
 	case spinner.TickMsg:
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 	case tea.KeyPressMsg:
+		// with vim mode, esc leaves insert mode before cancelling the template
+		if m.template != nil && msg.String() == "esc" && !m.textarea.VimCaptures(msg) {
+			return m.Clear()
+		}
 		// Handle up/down arrows and ctrl+p/ctrl+n for history navigation
 		switch msg.String() {
 		case "up", "ctrl+p":
 			// Only navigate history if cursor is at the first line and column (for arrow keys)
 			// or allow ctrl+p from anywhere
-			if (msg.String() == "ctrl+p" || (m.textarea.Line() == 0 && m.textarea.CursorColumn() == 0)) && len(m.app.State.MessageHistory) > 0 {
+			if (msg.String() == "ctrl+p" || (m.textarea.Line() == 0 && m.textarea.CursorColumn() == 0)) && len(m.app.History()) > 0 {
 				if m.historyIndex == -1 {
 					// Save current text before entering history
 					m.currentText = m.textarea.Value()
 					m.textarea.MoveToBegin()
 				}
 				// Move up in history (older messages)
-				if m.historyIndex < len(m.app.State.MessageHistory)-1 {
+				if m.historyIndex < len(m.app.History())-1 {
 					m.historyIndex++
 					m.RestoreFromHistory(m.historyIndex)
 					m.textarea.MoveToBegin()
@@ -218,14 +239,25 @@ func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.textarea = updateTextareaStyles(m.textarea)
 		m.spinner = createSpinner()
 		return m, tea.Batch(m.textarea.Focus(), m.spinner.Tick)
+	case dialog.GrepAttachMsg:
+		attachment := m.createAttachmentFromPath(msg.FilePath)
+		attachment.Display = fmt.Sprintf("@%s:%d", msg.FilePath, msg.Line+1)
+		attachment.URL = fmt.Sprintf("%s?start=%d&end=%d", attachment.URL, msg.Line, msg.Line)
+		m.textarea.InsertAttachment(attachment)
+		m.textarea.InsertString(" ")
+		return m, m.textarea.Focus()
 	case dialog.CompletionSelectedMsg:
 		switch msg.Item.ProviderID {
 		case "commands":
-			commandName := strings.TrimPrefix(msg.Item.Value, "/")
+			command, ok := msg.Item.RawData.(commands.Command)
+			if !ok {
+				commandName := strings.TrimPrefix(msg.Item.Value, "/")
+				command = m.app.Commands[commands.CommandName(commandName)]
+			}
 			updated, cmd := m.Clear()
 			m = updated.(*editorComponent)
 			cmds = append(cmds, cmd)
-			cmds = append(cmds, util.CmdHandler(commands.ExecuteCommandMsg(m.app.Commands[commands.CommandName(commandName)])))
+			cmds = append(cmds, util.CmdHandler(commands.ExecuteCommandMsg(command)))
 			return m, tea.Batch(cmds...)
 		case "files":
 			atIndex := m.textarea.LastRuneIndex('@')
@@ -311,7 +343,16 @@ func (m *editorComponent) Content() string {
 	t := theme.CurrentTheme()
 	base := styles.NewStyle().Foreground(t.Text()).Background(t.Background()).Render
 	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.Background()).Render
-	promptStyle := styles.NewStyle().Foreground(t.Primary()).
+	promptColor := t.Primary()
+	if m.textarea.VimEnabled() {
+		switch m.textarea.VimMode() {
+		case textarea.VimNormal:
+			promptColor = t.Secondary()
+		case textarea.VimVisual, textarea.VimVisualLine:
+			promptColor = t.Warning()
+		}
+	}
+	promptStyle := styles.NewStyle().Foreground(promptColor).
 		Padding(0, 0, 0, 1).
 		Bold(true)
 	prompt := promptStyle.Render(">")
@@ -323,7 +364,7 @@ func (m *editorComponent) Content() string {
 		m.textarea.View(),
 	)
 	borderForeground := t.Border()
-	if m.app.IsLeaderSequence {
+	if m.app.KeySequencePending() {
 		borderForeground = t.Accent()
 	}
 	textarea = styles.NewStyle().
@@ -339,7 +380,14 @@ func (m *editorComponent) Content() string {
 		Render(textarea)
 
 	hint := base(m.getSubmitKeyText()) + muted(" send   ")
-	if m.exitKeyInDebounce {
+	if m.template != nil {
+		hint = base("{{"+m.template.variables[m.template.index]+"}}") + muted(fmt.Sprintf(
+			" %d/%d  %s next  esc cancel",
+			m.template.index+1,
+			len(m.template.variables),
+			m.getSubmitKeyText(),
+		))
+	} else if m.exitKeyInDebounce {
 		keyText := m.getExitKeyText()
 		hint = base(keyText+" again") + muted(" to exit")
 	} else if m.app.IsBusy() {
@@ -359,6 +407,11 @@ func (m *editorComponent) Content() string {
 		}
 	}
 
+	if m.textarea.VimEnabled() && m.template == nil {
+		mode := styles.NewStyle().Foreground(promptColor).Background(t.Background()).Bold(true)
+		hint = mode.Render(m.textarea.VimMode().String()) + muted("  ") + hint
+	}
+
 	model := ""
 	if m.app.Model != nil {
 		model = muted(m.app.Provider.Name) + base(" "+m.app.Model.Name)
@@ -418,6 +471,10 @@ func (m *editorComponent) Length() int {
 }
 
 func (m *editorComponent) Submit() (tea.Model, tea.Cmd) {
+	if m.template != nil {
+		return m.submitTemplateValue()
+	}
+
 	value := strings.TrimSpace(m.Value())
 	if value == "" {
 		return m, nil
@@ -437,10 +494,18 @@ func (m *editorComponent) Submit() (tea.Model, tea.Cmd) {
 	}
 
 	var cmds []tea.Cmd
+	if command, ok := m.app.Commands.Parse(value); ok {
+		updated, cmd := m.Clear()
+		m = updated.(*editorComponent)
+		cmds = append(cmds, cmd)
+		cmds = append(cmds, util.CmdHandler(commands.ExecuteCommandMsg(command)))
+		return m, tea.Batch(cmds...)
+	}
+
 	attachments := m.textarea.GetAttachments()
 
 	prompt := app.Prompt{Text: value, Attachments: attachments}
-	m.app.State.AddPromptToHistory(prompt)
+	m.app.State.AddPromptToHistory(m.app.Info.Path.Root, prompt)
 	cmds = append(cmds, m.app.SaveState())
 
 	updated, cmd := m.Clear()
@@ -456,9 +521,57 @@ func (m *editorComponent) Clear() (tea.Model, tea.Cmd) {
 	m.historyIndex = -1
 	m.currentText = ""
 	m.pasteCounter = 0
+	m.cancelTemplate()
 	return m, nil
 }
 
+// StartTemplate fills the editor with a prompt template, prompting for the
+// value of each of its variables first
+func (m *editorComponent) StartTemplate(template templates.Template) (tea.Model, tea.Cmd) {
+	updated, cmd := m.Clear()
+	m = updated.(*editorComponent)
+
+	variables := template.Variables()
+	if len(variables) == 0 {
+		m.SetValueWithAttachments(template.Content)
+		return m, cmd
+	}
+
+	m.template = &templateFill{
+		template:  template,
+		variables: variables,
+		values:    map[string]string{},
+	}
+	m.textarea.Placeholder = variables[0]
+	return m, tea.Batch(cmd, m.textarea.Focus())
+}
+
+// submitTemplateValue records the value entered for the current variable of
+// the template, once every variable has a value the rendered template is
+// placed in the editor to be reviewed and sent
+func (m *editorComponent) submitTemplateValue() (tea.Model, tea.Cmd) {
+	fill := m.template
+	fill.values[fill.variables[fill.index]] = strings.TrimSpace(m.Value())
+	fill.index++
+	m.textarea.Reset()
+	if fill.index < len(fill.variables) {
+		m.textarea.Placeholder = fill.variables[fill.index]
+		return m, nil
+	}
+
+	m.cancelTemplate()
+	m.SetValueWithAttachments(fill.template.Render(fill.values))
+	return m, nil
+}
+
+func (m *editorComponent) cancelTemplate() {
+	if m.template == nil {
+		return
+	}
+	m.template = nil
+	m.textarea.Placeholder = ""
+}
+
 func (m *editorComponent) Paste() (tea.Model, tea.Cmd) {
 	imageBytes := clipboard.Read(clipboard.FmtImage)
 	if imageBytes != nil {
@@ -499,11 +612,39 @@ func (m *editorComponent) Paste() (tea.Model, tea.Cmd) {
 	return m, tea.ReadClipboard
 }
 
+// InsertAttachment inserts an attachment at the cursor, for the prompt to be
+// finished before it's sent
+func (m *editorComponent) InsertAttachment(attachment *attachment.Attachment) (tea.Model, tea.Cmd) {
+	m.textarea.InsertAttachment(attachment)
+	m.textarea.InsertString(" ")
+	return m, m.textarea.Focus()
+}
+
 func (m *editorComponent) Newline() (tea.Model, tea.Cmd) {
 	m.textarea.Newline()
 	return m, nil
 }
 
+// Undo reverts the latest edit of the input, including clearing it. With
+// nothing left to undo the app is suspended, like ctrl+z does in a terminal.
+func (m *editorComponent) Undo() (tea.Model, tea.Cmd) {
+	if !m.textarea.Undo() {
+		return m, tea.Suspend
+	}
+	m.historyIndex = -1
+	m.currentText = ""
+	return m, nil
+}
+
+// Redo applies the latest edit reverted by Undo again
+func (m *editorComponent) Redo() (tea.Model, tea.Cmd) {
+	if m.textarea.Redo() {
+		m.historyIndex = -1
+		m.currentText = ""
+	}
+	return m, nil
+}
+
 func (m *editorComponent) SetInterruptKeyInDebounce(inDebounce bool) {
 	m.interruptKeyInDebounce = inDebounce
 }
@@ -541,8 +682,9 @@ func (m *editorComponent) SetValueWithAttachments(value string) {
 		}
 
 		// Not a valid file path, insert the character normally
-		m.textarea.InsertRune(rune(value[i]))
-		i++
+		r, size := utf8.DecodeRuneInString(value[i:])
+		m.textarea.InsertRune(r)
+		i += size
 	}
 }
 
@@ -550,16 +692,31 @@ func (m *editorComponent) SetExitKeyInDebounce(inDebounce bool) {
 	m.exitKeyInDebounce = inDebounce
 }
 
+func (m *editorComponent) SetVimEnabled(enabled bool) {
+	m.textarea.SetVimEnabled(enabled)
+}
+
+// VimCaptures returns whether vim mode handles a key press, the keys of normal
+// mode shouldn't trigger completions or commands
+// SetWidth sets the width the editor is laid out in
+func (m *editorComponent) SetWidth(width int) {
+	m.width = width - 4
+}
+
+func (m *editorComponent) VimCaptures(msg tea.KeyPressMsg) bool {
+	return m.textarea.Focused() && m.textarea.VimCaptures(msg)
+}
+
 func (m *editorComponent) getInterruptKeyText() string {
-	return m.app.Commands[commands.SessionInterruptCommand].Keys()[0]
+	return m.app.Commands[commands.SessionInterruptCommand].PrimaryKey()
 }
 
 func (m *editorComponent) getSubmitKeyText() string {
-	return m.app.Commands[commands.InputSubmitCommand].Keys()[0]
+	return m.app.Commands[commands.InputSubmitCommand].PrimaryKey()
 }
 
 func (m *editorComponent) getExitKeyText() string {
-	return m.app.Commands[commands.AppExitCommand].Keys()[0]
+	return m.app.Commands[commands.AppExitCommand].PrimaryKey()
 }
 
 // shouldSummarizePastedText determines if pasted text should be summarized
@@ -632,6 +789,10 @@ func updateTextareaStyles(ta textarea.Model) textarea.Model {
 		Foreground(t.Text()).
 		Background(t.Secondary()).
 		Lipgloss()
+	ta.Styles.Selection = styles.NewStyle().
+		Foreground(t.Background()).
+		Background(t.TextMuted()).
+		Lipgloss()
 	ta.Styles.Cursor.Color = t.Primary()
 	return ta
 }
@@ -657,6 +818,7 @@ func NewEditorComponent(app *app.App) EditorComponent {
 	ta.Prompt = " "
 	ta.ShowLineNumbers = false
 	ta.CharLimit = -1
+	ta.SetVimEnabled(app.State.VimMode)
 	ta = updateTextareaStyles(ta)
 
 	m := &editorComponent{
@@ -697,11 +859,11 @@ func (m *editorComponent) RestoreFromPrompt(prompt app.Prompt) {
 
 // RestoreFromHistory restores a message from history at the given index
 func (m *editorComponent) RestoreFromHistory(index int) {
-	if index < 0 || index >= len(m.app.State.MessageHistory) {
+	history := m.app.History()
+	if index < 0 || index >= len(history) {
 		return
 	}
-	entry := m.app.State.MessageHistory[index]
-	m.RestoreFromPrompt(entry)
+	m.RestoreFromPrompt(history[index].Prompt)
 }
 
 func getMediaTypeFromExtension(ext string) string {
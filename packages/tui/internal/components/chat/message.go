@@ -1,7 +1,6 @@
 package chat
 
 import (
-	"encoding/json"
 	"fmt"
 	"slices"
 	"strings"
@@ -13,7 +12,6 @@ import (
 	"github.com/muesli/reflow/truncate"
 	"github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
-	"github.com/sst/opencode/internal/components/diff"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
 	"github.com/sst/opencode/internal/util"
@@ -193,7 +191,7 @@ func renderText(
 	switch casted := message.(type) {
 	case opencode.AssistantMessage:
 		ts = time.UnixMilli(int64(casted.Time.Created))
-		content = util.ToMarkdown(text, width, backgroundColor)
+		content = util.ToMarkdown(numberCodeBlocks(text), width, backgroundColor)
 	case opencode.UserMessage:
 		ts = time.UnixMilli(int64(casted.Time.Created))
 		base := styles.NewStyle().Foreground(t.Text()).Background(backgroundColor)
@@ -229,6 +227,9 @@ func renderText(
 				style = style.Foreground(t.Error())
 			}
 			title = style.Render(title)
+			if summary := renderToolSummary(toolCall); summary != "" {
+				title += styles.NewStyle().Foreground(t.TextMuted()).Faint(true).Render(" · " + summary)
+			}
 			title = "∟ " + title + "\n"
 			content = content + title
 		}
@@ -260,10 +261,34 @@ func renderText(
 	return ""
 }
 
+// numberCodeBlocks labels the code blocks of a markdown text with their
+// number, the one used to pick them for copying. Only the blocks at the top
+// level are labelled, an indented fence may belong to a list item the label
+// would break out of.
+func numberCodeBlocks(text string) string {
+	blocks := util.CodeBlocks(text)
+	if len(blocks) == 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, block := range slices.Backward(blocks) {
+		if strings.HasPrefix(lines[block.Line], " ") {
+			continue
+		}
+		label := fmt.Sprintf("_[%d]", i+1)
+		if block.Language != "" {
+			label += " " + block.Language
+		}
+		lines = slices.Insert(lines, block.Line, "", label+"_")
+	}
+	return strings.Join(lines, "\n")
+}
+
 func renderToolDetails(
 	app *app.App,
 	toolCall opencode.ToolPart,
 	width int,
+	expanded bool,
 ) string {
 	measure := util.Measure("chat.renderToolDetails")
 	defer measure("tool", toolCall.Tool)
@@ -277,23 +302,9 @@ func renderToolDetails(
 		return renderContentBlock(app, title, width)
 	}
 
-	var result *string
-	if toolCall.State.Output != "" {
-		result = &toolCall.State.Output
-	}
-
-	toolInputMap := make(map[string]any)
-	if toolCall.State.Input != nil {
-		value := toolCall.State.Input
-		if m, ok := value.(map[string]any); ok {
-			toolInputMap = m
-			keys := make([]string, 0, len(toolInputMap))
-			for key := range toolInputMap {
-				keys = append(keys, key)
-			}
-			slices.Sort(keys)
-		}
-	}
+	call := newToolCall(toolCall)
+	call.Expanded = expanded
+	renderer := toolRendererFor(toolCall.Tool)
 
 	body := ""
 	t := theme.CurrentTheme()
@@ -301,137 +312,16 @@ func renderToolDetails(
 	borderColor := t.BackgroundPanel()
 	defaultStyle := styles.NewStyle().Background(backgroundColor).Width(width - 6).Render
 
-	if toolCall.State.Metadata != nil {
-		metadata := toolCall.State.Metadata.(map[string]any)
-		switch toolCall.Tool {
-		case "read":
-			var preview any
-			if metadata != nil {
-				preview = metadata["preview"]
-			}
-			if preview != nil && toolInputMap["filePath"] != nil {
-				filename := toolInputMap["filePath"].(string)
-				body = preview.(string)
-				body = util.RenderFile(filename, body, width, util.WithTruncate(6))
-			}
-		case "edit":
-			if filename, ok := toolInputMap["filePath"].(string); ok {
-				var diffField any
-				if metadata != nil {
-					diffField = metadata["diff"]
-				}
-				if diffField != nil {
-					patch := diffField.(string)
-					var formattedDiff string
-					if width < 120 {
-						formattedDiff, _ = diff.FormatUnifiedDiff(
-							filename,
-							patch,
-							diff.WithWidth(width-2),
-						)
-					} else {
-						formattedDiff, _ = diff.FormatDiff(
-							filename,
-							patch,
-							diff.WithWidth(width-2),
-						)
-					}
-					body = strings.TrimSpace(formattedDiff)
-					style := styles.NewStyle().
-						Background(backgroundColor).
-						Foreground(t.TextMuted()).
-						Padding(1, 2).
-						Width(width - 4)
-
-					if diagnostics := renderDiagnostics(metadata, filename, backgroundColor, width-6); diagnostics != "" {
-						diagnostics = style.Render(diagnostics)
-						body += "\n" + diagnostics
-					}
-
-					title := renderToolTitle(toolCall, width)
-					title = style.Render(title)
-					content := title + "\n" + body
-					content = renderContentBlock(
-						app,
-						content,
-						width,
-						WithPadding(0),
-						WithBorderColor(borderColor),
-					)
-					return content
-				}
-			}
-		case "write":
-			if filename, ok := toolInputMap["filePath"].(string); ok {
-				if content, ok := toolInputMap["content"].(string); ok {
-					body = util.RenderFile(filename, content, width)
-					if diagnostics := renderDiagnostics(metadata, filename, backgroundColor, width-4); diagnostics != "" {
-						body += "\n\n" + diagnostics
-					}
-				}
-			}
-		case "bash":
-			command := toolInputMap["command"].(string)
-			body = fmt.Sprintf("```console\n$ %s\n", command)
-			stdout := metadata["stdout"]
-			if stdout != nil {
-				body += ansi.Strip(fmt.Sprintf("%s", stdout))
+	if call.Metadata != nil {
+		if renderer.Block != nil {
+			if block := renderer.Block(app, call, width); block != "" {
+				return block
 			}
-			body += "```"
-			body = util.ToMarkdown(body, width, backgroundColor)
-		case "webfetch":
-			if format, ok := toolInputMap["format"].(string); ok && result != nil {
-				body = *result
-				body = util.TruncateHeight(body, 10)
-				if format == "html" || format == "markdown" {
-					body = util.ToMarkdown(body, width, backgroundColor)
-				}
-			}
-		case "todowrite":
-			todos := metadata["todos"]
-			if todos != nil {
-				for _, item := range todos.([]any) {
-					todo := item.(map[string]any)
-					content := todo["content"].(string)
-					switch todo["status"] {
-					case "completed":
-						body += fmt.Sprintf("- [x] %s\n", content)
-					case "cancelled":
-						// strike through cancelled todo
-						body += fmt.Sprintf("- [ ] ~~%s~~\n", content)
-					case "in_progress":
-						// highlight in progress todo
-						body += fmt.Sprintf("- [ ] `%s`\n", content)
-					default:
-						body += fmt.Sprintf("- [ ] %s\n", content)
-					}
-				}
-				body = util.ToMarkdown(body, width, backgroundColor)
-			}
-		case "task":
-			summary := metadata["summary"]
-			if summary != nil {
-				toolcalls := summary.([]any)
-				steps := []string{}
-				for _, item := range toolcalls {
-					data, _ := json.Marshal(item)
-					var toolCall opencode.ToolPart
-					_ = json.Unmarshal(data, &toolCall)
-					step := renderToolTitle(toolCall, width)
-					step = "∟ " + step
-					steps = append(steps, step)
-				}
-				body = strings.Join(steps, "\n")
-			}
-			body = defaultStyle(body)
-		default:
-			if result == nil {
-				empty := ""
-				result = &empty
-			}
-			body = *result
-			body = util.TruncateHeight(body, 10)
-			body = defaultStyle(body)
+		}
+		if renderer.Body != nil {
+			body = renderer.Body(app, call, width)
+		} else {
+			body = renderToolOutput(call, width)
 		}
 	}
 
@@ -448,10 +338,8 @@ func renderToolDetails(
 			Render(error)
 	}
 
-	if body == "" && error == "" && result != nil {
-		body = *result
-		body = util.TruncateHeight(body, 10)
-		body = defaultStyle(body)
+	if body == "" && error == "" && call.Output != nil {
+		body = renderToolOutput(call, width)
 	}
 
 	if body == "" {
@@ -520,58 +408,21 @@ func renderToolTitle(
 		return styles.NewStyle().Width(width - 6).Render(title)
 	}
 
-	toolArgs := ""
-	toolArgsMap := make(map[string]any)
-	if toolCall.State.Input != nil {
-		value := toolCall.State.Input
-		if m, ok := value.(map[string]any); ok {
-			toolArgsMap = m
-
-			keys := make([]string, 0, len(toolArgsMap))
-			for key := range toolArgsMap {
-				keys = append(keys, key)
-			}
-			slices.Sort(keys)
-			firstKey := ""
-			if len(keys) > 0 {
-				firstKey = keys[0]
-			}
-
-			toolArgs = renderArgs(&toolArgsMap, firstKey)
+	call := newToolCall(toolCall)
+	var title string
+	if renderer := toolRendererFor(toolCall.Tool); renderer.Title != nil {
+		title = renderer.Title(call)
+	} else {
+		keys := make([]string, 0, len(call.Input))
+		for key := range call.Input {
+			keys = append(keys, key)
 		}
-	}
-
-	title := renderToolName(toolCall.Tool)
-	switch toolCall.Tool {
-	case "read":
-		toolArgs = renderArgs(&toolArgsMap, "filePath")
-		title = fmt.Sprintf("%s %s", title, toolArgs)
-	case "edit", "write":
-		if filename, ok := toolArgsMap["filePath"].(string); ok {
-			title = fmt.Sprintf("%s %s", title, util.Relative(filename))
+		slices.Sort(keys)
+		firstKey := ""
+		if len(keys) > 0 {
+			firstKey = keys[0]
 		}
-	case "bash":
-		if description, ok := toolArgsMap["description"].(string); ok {
-			title = fmt.Sprintf("%s %s", title, description)
-		}
-	case "task":
-		description := toolArgsMap["description"]
-		subagent := toolArgsMap["subagent_type"]
-		if description != nil && subagent != nil {
-			title = fmt.Sprintf("%s[%s] %s", title, subagent, description)
-		} else if description != nil {
-			title = fmt.Sprintf("%s %s", title, description)
-		}
-	case "webfetch":
-		toolArgs = renderArgs(&toolArgsMap, "url")
-		title = fmt.Sprintf("%s %s", title, toolArgs)
-	case "todowrite":
-		title = getTodoTitle(toolCall)
-	case "todoread":
-		return "Plan"
-	default:
-		toolName := renderToolName(toolCall.Tool)
-		title = fmt.Sprintf("%s %s", toolName, toolArgs)
+		title = fmt.Sprintf("%s %s", renderToolName(toolCall.Tool), renderArgs(&call.Input, firstKey))
 	}
 
 	title = truncate.StringWithTail(title, uint(width-6), "...")
@@ -579,29 +430,8 @@ func renderToolTitle(
 }
 
 func renderToolAction(name string) string {
-	switch name {
-	case "task":
-		return "Delegating..."
-	case "bash":
-		return "Writing command..."
-	case "edit":
-		return "Preparing edit..."
-	case "webfetch":
-		return "Fetching from the web..."
-	case "glob":
-		return "Finding files..."
-	case "grep":
-		return "Searching content..."
-	case "list":
-		return "Listing directory..."
-	case "read":
-		return "Reading file..."
-	case "write":
-		return "Preparing write..."
-	case "todowrite", "todoread":
-		return "Planning..."
-	case "patch":
-		return "Preparing patch..."
+	if action := toolRendererFor(name).Action; action != "" {
+		return action
 	}
 	return "Working..."
 }
@@ -639,18 +469,6 @@ func renderArgs(args *map[string]any, titleKey string) string {
 	return fmt.Sprintf("%s (%s)", title, strings.Join(parts, ", "))
 }
 
-// Diagnostic represents an LSP diagnostic
-type Diagnostic struct {
-	Range struct {
-		Start struct {
-			Line      int `json:"line"`
-			Character int `json:"character"`
-		} `json:"start"`
-	} `json:"range"`
-	Severity int    `json:"severity"`
-	Message  string `json:"message"`
-}
-
 // renderDiagnostics formats LSP diagnostics for display in the TUI
 func renderDiagnostics(
 	metadata map[string]any,
@@ -659,66 +477,37 @@ func renderDiagnostics(
 	width int,
 ) string {
 	if diagnosticsData, ok := metadata["diagnostics"].(map[string]any); ok {
-		if fileDiagnostics, ok := diagnosticsData[filePath].([]any); ok {
-			var errorDiagnostics []string
-			for _, diagInterface := range fileDiagnostics {
-				diagMap, ok := diagInterface.(map[string]any)
-				if !ok {
-					continue
-				}
-				// Parse the diagnostic
-				var diag Diagnostic
-				diagBytes, err := json.Marshal(diagMap)
-				if err != nil {
-					continue
-				}
-				if err := json.Unmarshal(diagBytes, &diag); err != nil {
-					continue
-				}
-				// Only show error diagnostics (severity === 1)
-				if diag.Severity != 1 {
-					continue
-				}
-				line := diag.Range.Start.Line + 1        // 1-based
-				column := diag.Range.Start.Character + 1 // 1-based
-				errorDiagnostics = append(
-					errorDiagnostics,
-					fmt.Sprintf("Error [%d:%d] %s", line, column, diag.Message),
-				)
-			}
-			if len(errorDiagnostics) == 0 {
-				return ""
+		var errorDiagnostics []string
+		for _, diag := range app.ParseDiagnostics(diagnosticsData[filePath]) {
+			// Only show error diagnostics
+			if diag.Severity != app.DiagnosticSeverityError {
+				continue
 			}
-			t := theme.CurrentTheme()
-			var result strings.Builder
-			for _, diagnostic := range errorDiagnostics {
-				if result.Len() > 0 {
-					result.WriteString("\n\n")
-				}
-				diagnostic = ansi.WordwrapWc(diagnostic, width, " -")
-				result.WriteString(
-					styles.NewStyle().
-						Background(backgroundColor).
-						Foreground(t.Error()).
-						Render(diagnostic),
-				)
+			line := diag.Range.Start.Line + 1        // 1-based
+			column := diag.Range.Start.Character + 1 // 1-based
+			errorDiagnostics = append(
+				errorDiagnostics,
+				fmt.Sprintf("Error [%d:%d] %s", line, column, diag.Message),
+			)
+		}
+		if len(errorDiagnostics) == 0 {
+			return ""
+		}
+		t := theme.CurrentTheme()
+		var result strings.Builder
+		for _, diagnostic := range errorDiagnostics {
+			if result.Len() > 0 {
+				result.WriteString("\n\n")
 			}
-			return result.String()
+			diagnostic = ansi.WordwrapWc(diagnostic, width, " -")
+			result.WriteString(
+				styles.NewStyle().
+					Background(backgroundColor).
+					Foreground(t.Error()).
+					Render(diagnostic),
+			)
 		}
+		return result.String()
 	}
 	return ""
-
-	// diagnosticsData should be a map[string][]Diagnostic
-	// strDiagnosticsData := diagnosticsData.Raw()
-	// diagnosticsMap := gjson.Parse(strDiagnosticsData).Value().(map[string]any)
-	// fileDiagnostics, ok := diagnosticsMap[filePath]
-	// if !ok {
-	// 	return ""
-	// }
-
-	// diagnosticsList, ok := fileDiagnostics.([]any)
-	// if !ok {
-	// 	return ""
-	// }
-
 }
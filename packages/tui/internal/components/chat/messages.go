@@ -38,6 +38,18 @@ type MessagesComponent interface {
 	CopyLastMessage() (tea.Model, tea.Cmd)
 	UndoLastMessage() (tea.Model, tea.Cmd)
 	RedoLastMessage() (tea.Model, tea.Cmd)
+	Searching() bool
+	SearchFocused() bool
+	StartSearch() (tea.Model, tea.Cmd)
+	CloseSearch() (tea.Model, tea.Cmd)
+	NextMatch() (tea.Model, tea.Cmd)
+	PreviousMatch() (tea.Model, tea.Cmd)
+	PreviousBlock() (tea.Model, tea.Cmd)
+	NextBlock() (tea.Model, tea.Cmd)
+	ToggleBlock() (tea.Model, tea.Cmd)
+	Actions() (string, []dialog.MessageAction)
+	CodeBlocks() []util.CodeBlock
+	SetSize(width, height int) tea.Cmd
 }
 
 type messagesComponent struct {
@@ -55,6 +67,22 @@ type messagesComponent struct {
 	partCount       int
 	lineCount       int
 	selection       *selection
+	search          search
+	blocks          []messageBlock
+	cursor          string
+}
+
+// messageBlock locates a rendered block in the content of the viewport
+type messageBlock struct {
+	// id is the ID of the part rendered in the block, empty for the blocks
+	// the cursor skips
+	id string
+	// tools are the IDs of the tool calls expanded or collapsed from the
+	// block, the call itself for a tool block or the calls listed under a
+	// message when tool details are hidden
+	tools  []string
+	start  int
+	height int
 }
 
 type selection struct {
@@ -64,6 +92,12 @@ type selection struct {
 	endY   int
 }
 
+// overlaps reports whether the lines from start up to end are selected, none
+// are without a selection
+func (s *selection) overlaps(start, end int) bool {
+	return s != nil && start <= s.endY && end > s.startY
+}
+
 func (s selection) coords(offset int) *selection {
 	// selecting backwards
 	if s.startY > s.endY && s.endY >= 0 {
@@ -104,6 +138,10 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	defer measure("from", fmt.Sprintf("%T", msg))
 	var cmds []tea.Cmd
 	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		if m.search.open {
+			return m.updateSearch(msg)
+		}
 	case tea.MouseClickMsg:
 		slog.Info("mouse", "x", msg.X, "y", msg.Y, "offset", m.viewport.YOffset)
 		y := msg.Y + m.viewport.YOffset
@@ -141,17 +179,6 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				toast.NewSuccessToast("Copied to clipboard"),
 			)
 		}
-	case tea.WindowSizeMsg:
-		effectiveWidth := msg.Width - 4
-		// Clear cache on resize since width affects rendering
-		if m.width != effectiveWidth {
-			m.cache.Clear()
-		}
-		m.width = effectiveWidth
-		m.height = msg.Height - 7
-		m.viewport.SetWidth(m.width)
-		m.loading = true
-		return m, m.renderView()
 	case app.SendPrompt:
 		m.viewport.GotoBottom()
 		m.tail = true
@@ -160,11 +187,14 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.cache.Clear()
 		m.loading = true
 		return m, m.renderView()
+	case dialog.MessageActionSelectedMsg:
+		return m, m.runAction(msg)
 	case ToggleToolDetailsMsg:
 		m.showToolDetails = !m.showToolDetails
 		return m, m.renderView()
 	case app.SessionLoadedMsg, app.SessionClearedMsg:
 		m.cache.Clear()
+		m.cursor = ""
 		m.tail = true
 		m.loading = true
 		return m, m.renderView()
@@ -195,8 +225,6 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case opencode.EventListResponseEventMessagePartRemoved:
 		if msg.Properties.SessionID == m.app.Session.ID {
-			// Clear the cache when a part is removed to ensure proper re-rendering
-			m.cache.Clear()
 			cmds = append(cmds, m.renderView())
 		}
 	case renderCompleteMsg:
@@ -204,10 +232,13 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.lineCount = msg.lineCount
 		m.rendering = false
 		m.clipboard = msg.clipboard
+		m.blocks = msg.blocks
 		m.loading = false
 		m.tail = m.viewport.AtBottom()
+		index := m.viewport.HighlightIndex()
 		m.viewport = msg.viewport
 		m.header = msg.header
+		m.reapplySearch(index)
 		if m.dirty {
 			cmds = append(cmds, m.renderView())
 		}
@@ -218,6 +249,11 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.viewport = viewport
 	cmds = append(cmds, cmd)
 
+	if m.search.open {
+		m.search.input, cmd = m.search.input.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
@@ -225,10 +261,27 @@ type renderCompleteMsg struct {
 	viewport  viewport.Model
 	clipboard []string
 	header    string
+	blocks    []messageBlock
 	partCount int
 	lineCount int
 }
 
+// SetSize sets the space the messages are laid out in, above the status bar,
+// and renders them again for the new width
+func (m *messagesComponent) SetSize(width, height int) tea.Cmd {
+	effectiveWidth := width - 4
+	// Clear cache on resize since width affects rendering
+	if m.width != effectiveWidth {
+		m.cache.Clear()
+	}
+	m.width = effectiveWidth
+	m.height = height - 5
+	m.viewport.SetWidth(m.width)
+	m.search.input.SetWidth(max(0, m.width-searchStatusWidth))
+	m.loading = true
+	return m.renderView()
+}
+
 func (m *messagesComponent) renderView() tea.Cmd {
 	if m.rendering {
 		slog.Debug("pending render, skipping")
@@ -240,8 +293,9 @@ func (m *messagesComponent) renderView() tea.Cmd {
 	m.dirty = false
 	m.rendering = true
 
-	viewport := m.viewport
+	vp := m.viewport
 	tail := m.tail
+	cursor := m.cursor
 
 	return func() tea.Msg {
 		header := m.renderHeader()
@@ -249,7 +303,12 @@ func (m *messagesComponent) renderView() tea.Cmd {
 		defer measure()
 
 		t := theme.CurrentTheme()
-		blocks := make([]string, 0)
+		parts := make([]renderedPart, 0)
+		messageBlocks := make([]messageBlock, 0)
+		addBlock := func(part renderedPart, block messageBlock) {
+			parts = append(parts, part)
+			messageBlocks = append(messageBlocks, block)
+		}
 		partCount := 0
 		lineCount := 0
 
@@ -268,9 +327,6 @@ func (m *messagesComponent) renderView() tea.Cmd {
 			}
 		}
 		for _, message := range m.app.Messages {
-			var content string
-			var cached bool
-
 			switch casted := message.Info.(type) {
 			case opencode.UserMessage:
 				if casted.ID == m.app.Session.Revert.MessageID {
@@ -295,58 +351,30 @@ func (m *messagesComponent) renderView() tea.Cmd {
 						}
 						remainingParts := message.Parts[partIndex+1:]
 						fileParts := make([]opencode.FilePart, 0)
+						fileKeys := make([]string, 0)
 						for _, part := range remainingParts {
 							switch part := part.(type) {
 							case opencode.FilePart:
 								fileParts = append(fileParts, part)
+								fileKeys = append(fileKeys, part.Mime+" "+part.Filename)
 							}
 						}
-						flexItems := []layout.FlexItem{}
-						if len(fileParts) > 0 {
-							fileStyle := styles.NewStyle().Background(t.BackgroundElement()).Foreground(t.TextMuted()).Padding(0, 1)
-							mediaTypeStyle := styles.NewStyle().Background(t.Secondary()).Foreground(t.BackgroundPanel()).Padding(0, 1)
-							for _, filePart := range fileParts {
-								mediaType := ""
-								switch filePart.Mime {
-								case "text/plain":
-									mediaType = "txt"
-								case "image/png", "image/jpeg", "image/gif", "image/webp":
-									mediaType = "img"
-									mediaTypeStyle = mediaTypeStyle.Background(t.Accent())
-								case "application/pdf":
-									mediaType = "pdf"
-									mediaTypeStyle = mediaTypeStyle.Background(t.Primary())
-								}
-								flexItems = append(flexItems, layout.FlexItem{
-									View: mediaTypeStyle.Render(mediaType) + fileStyle.Render(filePart.Filename),
-								})
-							}
-						}
-						bgColor := t.BackgroundPanel()
-						files := layout.Render(
-							layout.FlexOptions{
-								Background: &bgColor,
-								Width:      width - 6,
-								Direction:  layout.Column,
-							},
-							flexItems...,
-						)
 
 						author := m.app.Config.Username
 						if casted.ID > lastAssistantMessage {
 							author += " [queued]"
 						}
-						key := m.cache.GenerateKey(casted.ID, part.Text, width, files, author)
-						content, cached = m.cache.Get(key)
+						key := m.cache.GenerateKey(part.Text, width, fileKeys, author)
+						rendered, cached := m.cache.Get(part.ID, key)
 						if !cached {
-							content = renderText(
+							content := renderText(
 								m.app,
 								message.Info,
 								part.Text,
 								author,
 								m.showToolDetails,
 								width,
-								files,
+								renderFileParts(fileParts, width),
 								fileParts,
 							)
 							content = lipgloss.PlaceHorizontal(
@@ -355,12 +383,12 @@ func (m *messagesComponent) renderView() tea.Cmd {
 								content,
 								styles.WhitespaceStyle(t.Background()),
 							)
-							m.cache.Set(key, content)
+							rendered = m.cache.Set(part.ID, key, content)
 						}
-						if content != "" {
+						if !rendered.empty() {
 							partCount++
-							lineCount += lipgloss.Height(content) + 1
-							blocks = append(blocks, content)
+							lineCount += len(rendered.lines) + 1
+							addBlock(rendered, messageBlock{id: part.ID})
 						}
 					}
 				}
@@ -382,9 +410,9 @@ func (m *messagesComponent) renderView() tea.Cmd {
 							continue
 						}
 						hasTextPart = true
-						finished := part.Time.End > 0
 						remainingParts := message.Parts[partIndex+1:]
 						toolCallParts := make([]opencode.ToolPart, 0)
+						toolCallIDs := make([]string, 0)
 
 						// sometimes tool calls happen without an assistant message
 						// these should be included in this assistant message as well
@@ -404,40 +432,33 @@ func (m *messagesComponent) renderView() tea.Cmd {
 								// if we hit another text part, we're done.
 								remaining = false
 							case opencode.ToolPart:
-								toolCallParts = append(toolCallParts, part)
-								if part.State.Status != opencode.ToolPartStateStatusCompleted && part.State.Status != opencode.ToolPartStateStatusError {
-									// i don't think there's a case where a tool call isn't in result state
-									// and the message time is 0, but just in case
-									finished = false
+								// expanded tool calls are rendered in blocks of their own
+								if !m.cache.Expanded(part.ID) {
+									toolCallParts = append(toolCallParts, part)
 								}
 							}
 						}
 
-						if finished {
-							key := m.cache.GenerateKey(casted.ID, part.Text, width, m.showToolDetails)
-							content, cached = m.cache.Get(key)
-							if !cached {
-								content = renderText(
-									m.app,
-									message.Info,
-									part.Text,
-									casted.ModelID,
-									m.showToolDetails,
-									width,
-									"",
-									[]opencode.FilePart{},
-									toolCallParts...,
-								)
-								content = lipgloss.PlaceHorizontal(
-									m.width,
-									lipgloss.Center,
-									content,
-									styles.WhitespaceStyle(t.Background()),
-								)
-								m.cache.Set(key, content)
+						// the tool calls are listed under the text when their
+						// details are hidden, so the text changes along with them
+						toolKeys := make([]any, 0)
+						for _, toolCall := range toolCallParts {
+							toolCallIDs = append(toolCallIDs, toolCall.ID)
+							if !m.showToolDetails {
+								toolKeys = append(toolKeys, toolKey(toolCall))
 							}
-						} else {
-							content = renderText(
+						}
+
+						key := m.cache.GenerateKey(
+							part.Text,
+							casted.ModelID,
+							width,
+							m.showToolDetails,
+							toolKeys,
+						)
+						rendered, cached := m.cache.Get(part.ID, key)
+						if !cached {
+							content := renderText(
 								m.app,
 								message.Info,
 								part.Text,
@@ -454,51 +475,43 @@ func (m *messagesComponent) renderView() tea.Cmd {
 								content,
 								styles.WhitespaceStyle(t.Background()),
 							)
+							rendered = m.cache.Set(part.ID, key, content)
 						}
-						if content != "" {
+						if !rendered.empty() {
+							block := messageBlock{id: part.ID}
+							if !m.showToolDetails {
+								block.tools = toolCallIDs
+							}
 							partCount++
-							lineCount += lipgloss.Height(content) + 1
-							blocks = append(blocks, content)
+							lineCount += len(rendered.lines) + 1
+							addBlock(rendered, block)
 						}
 					case opencode.ToolPart:
 						if reverted {
 							revertedToolCount++
 							continue
 						}
-						if !m.showToolDetails {
+						expanded := m.cache.Expanded(part.ID)
+						if !m.showToolDetails && !expanded {
 							if !hasTextPart {
 								orphanedToolCalls = append(orphanedToolCalls, part)
 							}
 							continue
 						}
 
-						if part.State.Status == opencode.ToolPartStateStatusCompleted || part.State.Status == opencode.ToolPartStateStatusError {
-							key := m.cache.GenerateKey(casted.ID,
-								part.ID,
-								m.showToolDetails,
-								width,
-							)
-							content, cached = m.cache.Get(key)
-							if !cached {
-								content = renderToolDetails(
-									m.app,
-									part,
-									width,
-								)
-								content = lipgloss.PlaceHorizontal(
-									m.width,
-									lipgloss.Center,
-									content,
-									styles.WhitespaceStyle(t.Background()),
-								)
-								m.cache.Set(key, content)
-							}
-						} else {
-							// if the tool call isn't finished, don't cache
-							content = renderToolDetails(
+						key := m.cache.GenerateKey(
+							toolKey(part),
+							m.showToolDetails,
+							width,
+							expanded,
+						)
+						rendered, cached := m.cache.Get(part.ID, key)
+						if !cached {
+							content := renderToolDetails(
 								m.app,
 								part,
 								width,
+								expanded,
 							)
 							content = lipgloss.PlaceHorizontal(
 								m.width,
@@ -506,11 +519,12 @@ func (m *messagesComponent) renderView() tea.Cmd {
 								content,
 								styles.WhitespaceStyle(t.Background()),
 							)
+							rendered = m.cache.Set(part.ID, key, content)
 						}
-						if content != "" {
+						if !rendered.empty() {
 							partCount++
-							lineCount += lipgloss.Height(content) + 1
-							blocks = append(blocks, content)
+							lineCount += len(rendered.lines) + 1
+							addBlock(rendered, messageBlock{id: part.ID, tools: []string{part.ID}})
 						}
 					}
 				}
@@ -529,157 +543,252 @@ func (m *messagesComponent) renderView() tea.Cmd {
 				case opencode.UnknownError:
 					error = err.Data.Message
 				}
-			}
-
-			if error != "" && !reverted {
-				error = styles.NewStyle().Width(width - 6).Render(error)
-				error = renderContentBlock(
-					m.app,
-					error,
-					width,
-					WithBorderColor(t.Error()),
-				)
-				error = lipgloss.PlaceHorizontal(
-					m.width,
-					lipgloss.Center,
-					error,
-					styles.WhitespaceStyle(t.Background()),
-				)
-				blocks = append(blocks, error)
-				lineCount += lipgloss.Height(error) + 1
-			}
-		}
-
-		if revertedMessageCount > 0 || revertedToolCount > 0 {
-			messagePlural := ""
-			toolPlural := ""
-			if revertedMessageCount != 1 {
-				messagePlural = "s"
-			}
-			if revertedToolCount != 1 {
-				toolPlural = "s"
-			}
-			revertedStyle := styles.NewStyle().
-				Background(t.BackgroundPanel()).
-				Foreground(t.TextMuted())
-
-			content := revertedStyle.Render(fmt.Sprintf(
-				"%d message%s reverted, %d tool call%s reverted",
-				revertedMessageCount,
-				messagePlural,
-				revertedToolCount,
-				toolPlural,
-			))
-			hintStyle := styles.NewStyle().Background(t.BackgroundPanel()).Foreground(t.Text())
-			hint := hintStyle.Render(m.app.Keybind(commands.MessagesRedoCommand))
-			hint += revertedStyle.Render(" (or /redo) to restore")
-
-			content += "\n" + hint
-			if m.app.Session.Revert.Diff != "" {
-				t := theme.CurrentTheme()
-				s := styles.NewStyle().Background(t.BackgroundPanel())
-				green := s.Foreground(t.Success()).Render
-				red := s.Foreground(t.Error()).Render
-				content += "\n"
-				stats, err := diff.ParseStats(m.app.Session.Revert.Diff)
-				if err != nil {
-					slog.Error("Failed to parse diff stats", "error", err)
-				} else {
-					var files []string
-					for file := range stats {
-						files = append(files, file)
-					}
-					sort.Strings(files)
 
-					for _, file := range files {
-						fileStats := stats[file]
-						display := file
-						if fileStats.Added > 0 {
-							display += green(" +" + strconv.Itoa(int(fileStats.Added)))
-						}
-						if fileStats.Removed > 0 {
-							display += red(" -" + strconv.Itoa(int(fileStats.Removed)))
-						}
-						content += "\n" + display
+				if error != "" && !reverted {
+					id := assistant.ID + ":error"
+					key := m.cache.GenerateKey(error, width)
+					rendered, cached := m.cache.Get(id, key)
+					if !cached {
+						content := styles.NewStyle().Width(width - 6).Render(error)
+						content = renderContentBlock(
+							m.app,
+							content,
+							width,
+							WithBorderColor(t.Error()),
+						)
+						content = lipgloss.PlaceHorizontal(
+							m.width,
+							lipgloss.Center,
+							content,
+							styles.WhitespaceStyle(t.Background()),
+						)
+						rendered = m.cache.Set(id, key, content)
 					}
+					addBlock(rendered, messageBlock{})
+					lineCount += len(rendered.lines) + 1
 				}
 			}
+		}
 
-			content = styles.NewStyle().
-				Background(t.BackgroundPanel()).
-				Width(width - 6).
-				Render(content)
-			content = renderContentBlock(
-				m.app,
-				content,
-				width,
-				WithBorderColor(t.BackgroundPanel()),
-			)
-			blocks = append(blocks, content)
+		if revertedMessageCount > 0 || revertedToolCount > 0 {
+			content := m.renderReverted(revertedMessageCount, revertedToolCount, width)
+			addBlock(newRenderedPart("", content), messageBlock{})
 		}
 
-		final := []string{}
 		clipboard := []string{}
 		var selection *selection
 		if m.selection != nil {
 			selection = m.selection.coords(lipgloss.Height(header) + 1)
 		}
-		for _, block := range blocks {
-			lines := strings.Split(block, "\n")
-			for index, line := range lines {
-				if selection == nil || index == 0 || index == len(lines)-1 {
-					final = append(final, line)
-					continue
-				}
-				y := len(final)
-				if y >= selection.startY && y <= selection.endY {
-					left := 3
-					if y == selection.startY {
-						left = selection.startX - 2
+		cursorStyle := styles.NewStyle().
+			Foreground(t.Primary()).
+			Background(t.Background())
+		// the lines of the parts are shared with the viewport as they are,
+		// only the parts under the cursor or the selection are copied
+		content := make([]viewport.Block, 0, len(parts)*2+1)
+		content = append(content, viewport.Block{Lines: emptyLine})
+		y := 0
+		for i, part := range parts {
+			lines := part.lines
+			messageBlocks[i].start = y
+			messageBlocks[i].height = len(lines)
+			selected := cursor != "" && messageBlocks[i].id == cursor
+			// the first and last lines of a block are never selected
+			if selected || selection.overlaps(y+1, y+len(lines)-1) {
+				lines = slices.Clone(lines)
+				for index, line := range lines {
+					// the left border of the block under the cursor is highlighted
+					if selected {
+						line = cursorStyle.Render("┃") + ansi.Cut(line, 1, ansi.StringWidth(line))
 					}
-					left = max(3, left)
-
-					width := ansi.StringWidth(line)
-					right := width - 1
-					if y == selection.endY {
-						right = min(selection.endX-2, right)
+					if selection == nil || index == 0 || index == len(lines)-1 {
+						lines[index] = line
+						continue
 					}
+					y := y + index
+					if y >= selection.startY && y <= selection.endY {
+						left := 3
+						if y == selection.startY {
+							left = selection.startX - 2
+						}
+						left = max(3, left)
+
+						width := ansi.StringWidth(line)
+						right := width - 1
+						if y == selection.endY {
+							right = min(selection.endX-2, right)
+						}
 
-					prefix := ansi.Cut(line, 0, left)
-					middle := strings.TrimRight(ansi.Strip(ansi.Cut(line, left, right)), " ")
-					suffix := ansi.Cut(line, left+ansi.StringWidth(middle), width)
-					clipboard = append(clipboard, middle)
-					line = prefix + styles.NewStyle().
-						Background(t.Accent()).
-						Foreground(t.BackgroundPanel()).
-						Render(ansi.Strip(middle)) +
-						suffix
+						prefix := ansi.Cut(line, 0, left)
+						middle := strings.TrimRight(ansi.Strip(ansi.Cut(line, left, right)), " ")
+						suffix := ansi.Cut(line, left+ansi.StringWidth(middle), width)
+						clipboard = append(clipboard, middle)
+						line = prefix + styles.NewStyle().
+							Background(t.Accent()).
+							Foreground(t.BackgroundPanel()).
+							Render(ansi.Strip(middle)) +
+							suffix
+					}
+					lines[index] = line
 				}
-				final = append(final, line)
 			}
-			y := len(final)
+			content = append(
+				content,
+				viewport.Block{Lines: lines, Width: part.width},
+				viewport.Block{Lines: emptyLine},
+			)
+			y += len(lines)
 			if selection != nil && y >= selection.startY && y < selection.endY {
 				clipboard = append(clipboard, "")
 			}
-			final = append(final, "")
+			y++
 		}
-		content := "\n" + strings.Join(final, "\n")
-		viewport.SetHeight(m.height - lipgloss.Height(header))
-		viewport.SetContent(content)
+		vp.SetHeight(m.height - lipgloss.Height(header) - m.searchHeight())
+		vp.SetContentBlocks(content)
 		if tail {
-			viewport.GotoBottom()
+			vp.GotoBottom()
 		}
 
 		return renderCompleteMsg{
 			header:    header,
 			clipboard: clipboard,
-			viewport:  viewport,
+			blocks:    messageBlocks,
+			viewport:  vp,
 			partCount: partCount,
 			lineCount: lineCount,
 		}
 	}
 }
 
+// emptyLine separates the blocks of the content
+var emptyLine = []string{""}
+
+// empty reports whether the part rendered to nothing
+func (p renderedPart) empty() bool {
+	return len(p.lines) == 1 && p.lines[0] == ""
+}
+
+// toolKey identifies the state of a tool call a part is rendered from, calls
+// that are finished don't change anymore
+func toolKey(part opencode.ToolPart) any {
+	switch part.State.Status {
+	case opencode.ToolPartStateStatusCompleted, opencode.ToolPartStateStatusError:
+		return []any{part.ID, part.State.Status}
+	}
+	return []any{
+		part.ID,
+		part.State.Status,
+		part.State.Title,
+		part.State.Input,
+		part.State.Metadata,
+		part.State.Output,
+	}
+}
+
+// renderFileParts renders the files attached to a user message
+func renderFileParts(fileParts []opencode.FilePart, width int) string {
+	t := theme.CurrentTheme()
+	flexItems := []layout.FlexItem{}
+	if len(fileParts) > 0 {
+		fileStyle := styles.NewStyle().Background(t.BackgroundElement()).Foreground(t.TextMuted()).Padding(0, 1)
+		mediaTypeStyle := styles.NewStyle().Background(t.Secondary()).Foreground(t.BackgroundPanel()).Padding(0, 1)
+		for _, filePart := range fileParts {
+			mediaType := ""
+			switch filePart.Mime {
+			case "text/plain":
+				mediaType = "txt"
+			case "image/png", "image/jpeg", "image/gif", "image/webp":
+				mediaType = "img"
+				mediaTypeStyle = mediaTypeStyle.Background(t.Accent())
+			case "application/pdf":
+				mediaType = "pdf"
+				mediaTypeStyle = mediaTypeStyle.Background(t.Primary())
+			}
+			flexItems = append(flexItems, layout.FlexItem{
+				View: mediaTypeStyle.Render(mediaType) + fileStyle.Render(filePart.Filename),
+			})
+		}
+	}
+	bgColor := t.BackgroundPanel()
+	return layout.Render(
+		layout.FlexOptions{
+			Background: &bgColor,
+			Width:      width - 6,
+			Direction:  layout.Column,
+		},
+		flexItems...,
+	)
+}
+
+// renderReverted renders the summary of the messages and tool calls reverted
+// at the end of the session
+func (m *messagesComponent) renderReverted(messages int, tools int, width int) string {
+	t := theme.CurrentTheme()
+	messagePlural := ""
+	toolPlural := ""
+	if messages != 1 {
+		messagePlural = "s"
+	}
+	if tools != 1 {
+		toolPlural = "s"
+	}
+	revertedStyle := styles.NewStyle().
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+
+	content := revertedStyle.Render(fmt.Sprintf(
+		"%d message%s reverted, %d tool call%s reverted",
+		messages,
+		messagePlural,
+		tools,
+		toolPlural,
+	))
+	hintStyle := styles.NewStyle().Background(t.BackgroundPanel()).Foreground(t.Text())
+	hint := hintStyle.Render(m.app.Keybind(commands.MessagesRedoCommand))
+	hint += revertedStyle.Render(" (or /redo) to restore")
+
+	content += "\n" + hint
+	if m.app.Session.Revert.Diff != "" {
+		s := styles.NewStyle().Background(t.BackgroundPanel())
+		green := s.Foreground(t.Success()).Render
+		red := s.Foreground(t.Error()).Render
+		content += "\n"
+		stats, err := diff.ParseStats(m.app.Session.Revert.Diff)
+		if err != nil {
+			slog.Error("Failed to parse diff stats", "error", err)
+		} else {
+			var files []string
+			for file := range stats {
+				files = append(files, file)
+			}
+			sort.Strings(files)
+
+			for _, file := range files {
+				fileStats := stats[file]
+				display := file
+				if fileStats.Added > 0 {
+					display += green(" +" + strconv.Itoa(int(fileStats.Added)))
+				}
+				if fileStats.Removed > 0 {
+					display += red(" -" + strconv.Itoa(int(fileStats.Removed)))
+				}
+				content += "\n" + display
+			}
+		}
+	}
+
+	content = styles.NewStyle().
+		Background(t.BackgroundPanel()).
+		Width(width - 6).
+		Render(content)
+	return renderContentBlock(
+		m.app,
+		content,
+		width,
+		WithBorderColor(t.BackgroundPanel()),
+	)
+}
+
 func (m *messagesComponent) renderHeader() string {
 	if m.app.Session.ID == "" {
 		return ""
@@ -849,6 +958,9 @@ func (m *messagesComponent) View() string {
 	measure := util.Measure("messages.View")
 	viewport := m.viewport.View()
 	measure()
+	if m.search.open {
+		viewport += "\n" + m.renderSearch()
+	}
 	return styles.NewStyle().
 		Background(t.Background()).
 		Render(m.header + "\n" + viewport)
@@ -888,6 +1000,108 @@ func (m *messagesComponent) GotoBottom() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// cursorIndex returns the index of the block under the cursor, -1 when
+// there is none
+func (m *messagesComponent) cursorIndex() int {
+	if m.cursor == "" {
+		return -1
+	}
+	for i, block := range m.blocks {
+		if block.id == m.cursor {
+			return i
+		}
+	}
+	return -1
+}
+
+// moveCursor moves the cursor to a block, scrolling it into view
+func (m *messagesComponent) moveCursor(index int) (tea.Model, tea.Cmd) {
+	block := m.blocks[index]
+	m.cursor = block.id
+	// the content starts with an empty line
+	line := block.start + 1
+	top := m.viewport.YOffset
+	height := m.viewport.Height()
+	switch {
+	case line < top || block.height >= height:
+		m.viewport.SetYOffset(line)
+	case line+block.height > top+height:
+		m.viewport.SetYOffset(line + block.height - height)
+	}
+	return m, m.renderView()
+}
+
+// PreviousBlock moves the cursor to the previous block, starting from the
+// last block in view
+func (m *messagesComponent) PreviousBlock() (tea.Model, tea.Cmd) {
+	index := m.cursorIndex()
+	if index < 0 {
+		bottom := m.viewport.YOffset + m.viewport.Height()
+		for i := len(m.blocks) - 1; i >= 0; i-- {
+			if m.blocks[i].id != "" && m.blocks[i].start+1 < bottom {
+				return m.moveCursor(i)
+			}
+		}
+		return m, nil
+	}
+	for i := index - 1; i >= 0; i-- {
+		if m.blocks[i].id != "" {
+			return m.moveCursor(i)
+		}
+	}
+	return m, nil
+}
+
+// NextBlock moves the cursor to the next block, starting from the first
+// block in view. Moving past the last block removes the cursor and follows
+// the conversation again.
+func (m *messagesComponent) NextBlock() (tea.Model, tea.Cmd) {
+	index := m.cursorIndex()
+	if index < 0 {
+		top := m.viewport.YOffset
+		for i, block := range m.blocks {
+			if block.id != "" && block.start+1+block.height > top {
+				return m.moveCursor(i)
+			}
+		}
+		return m, nil
+	}
+	for i := index + 1; i < len(m.blocks); i++ {
+		if m.blocks[i].id != "" {
+			return m.moveCursor(i)
+		}
+	}
+	m.cursor = ""
+	m.viewport.GotoBottom()
+	m.tail = true
+	return m, m.renderView()
+}
+
+// ToggleBlock expands the tool calls of the block under the cursor to show
+// their full output, or collapses them when they are all expanded
+func (m *messagesComponent) ToggleBlock() (tea.Model, tea.Cmd) {
+	index := m.cursorIndex()
+	if index < 0 {
+		return m, toast.NewInfoToast(
+			"Select a block with " + m.app.Keybind(commands.MessagesPreviousCommand) + " first",
+		)
+	}
+	tools := m.blocks[index].tools
+	if len(tools) == 0 {
+		return m, nil
+	}
+	expand := false
+	for _, id := range tools {
+		if !m.cache.Expanded(id) {
+			expand = true
+		}
+	}
+	for _, id := range tools {
+		m.cache.SetExpanded(id, expand)
+	}
+	return m, m.renderView()
+}
+
 func (m *messagesComponent) CopyLastMessage() (tea.Model, tea.Cmd) {
 	if len(m.app.Messages) == 0 {
 		return m, nil
@@ -1080,5 +1294,6 @@ func NewMessagesComponent(app *app.App) MessagesComponent {
 		showToolDetails: true,
 		cache:           NewPartCache(),
 		tail:            true,
+		search:          newSearch(),
 	}
 }
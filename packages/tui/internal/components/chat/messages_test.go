@@ -0,0 +1,146 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// newSyntheticSession creates messages for a long session, each exchange is a
+// prompt and a markdown reply with a tool call
+func newSyntheticSession(exchanges int) *app.App {
+	messages := make([]app.Message, 0, exchanges*2)
+	for i := range exchanges {
+		user := fmt.Sprintf("msg_%05d_a", i)
+		assistant := fmt.Sprintf("msg_%05d_b", i)
+		messages = append(messages,
+			app.Message{
+				Info: opencode.UserMessage{ID: user},
+				Parts: []opencode.PartUnion{
+					opencode.TextPart{ID: user + "_text", Text: fmt.Sprintf("Question %d about the code", i)},
+				},
+			},
+			app.Message{
+				Info: opencode.AssistantMessage{ID: assistant, ModelID: "model"},
+				Parts: []opencode.PartUnion{
+					opencode.TextPart{
+						ID:   assistant + "_text",
+						Text: "Here is the **answer**:\n\n```go\nfmt.Println(\"hello\")\n```\n\n- one\n- two",
+						Time: opencode.TextPartTime{End: 1},
+					},
+					opencode.ToolPart{
+						ID:   assistant + "_tool",
+						Tool: "bash",
+						State: opencode.ToolPartState{
+							Status: opencode.ToolPartStateStatusCompleted,
+							Input:  map[string]any{"command": "ls", "description": "List files"},
+							Output: strings.Repeat("file.go\n", 5),
+						},
+					},
+				},
+			},
+		)
+	}
+	return &app.App{
+		Config:   &opencode.Config{Username: "user"},
+		Session:  &opencode.Session{},
+		State:    app.NewState(),
+		Messages: messages,
+	}
+}
+
+func newTestMessages(a *app.App) *messagesComponent {
+	theme.LoadThemesFromJSON()
+	theme.SetTheme("opencode")
+
+	m := NewMessagesComponent(a).(*messagesComponent)
+	m.width = 116
+	m.height = 33
+	m.viewport.SetWidth(m.width)
+	return m
+}
+
+// render renders the messages and applies the result, like the update loop
+func (m *messagesComponent) render() {
+	m.Update(m.renderView()())
+	m.View()
+}
+
+// appendToLastPart streams text into the last text part of the session
+func appendToLastPart(a *app.App, text string) {
+	message := a.Messages[len(a.Messages)-1]
+	part := message.Parts[0].(opencode.TextPart)
+	part.Text += text
+	part.Time.End = 0
+	message.Parts[0] = part
+}
+
+func TestRenderViewRendersChangedParts(t *testing.T) {
+	a := newSyntheticSession(3)
+	m := newTestMessages(a)
+	m.render()
+
+	before := m.cache.parts["msg_00000_b_text"]
+	last := m.cache.parts["msg_00002_b_text"]
+	if before.lines == nil || last.lines == nil {
+		t.Fatalf("expected the parts to be rendered")
+	}
+	if total := m.viewport.TotalLineCount(); total != m.lineCount+1 {
+		t.Errorf("expected %d lines in the viewport, got %d", m.lineCount+1, total)
+	}
+
+	appendToLastPart(a, " and more")
+	m.render()
+
+	if &m.cache.parts["msg_00000_b_text"].lines[0] != &before.lines[0] {
+		t.Errorf("expected the unchanged part not to be rendered again")
+	}
+	if m.cache.parts["msg_00002_b_text"].key == last.key {
+		t.Errorf("expected the changed part to be rendered again")
+	}
+	if !strings.Contains(ansi.Strip(m.viewport.GetContent()), "and more") {
+		t.Errorf("expected the viewport to show the changed part")
+	}
+}
+
+// BenchmarkRenderViewStreaming measures rendering a long session while a reply
+// streams in, where only the last part changes
+func BenchmarkRenderViewStreaming(b *testing.B) {
+	a := newSyntheticSession(500)
+	m := newTestMessages(a)
+	m.render()
+
+	b.ResetTimer()
+	for i := range b.N {
+		appendToLastPart(a, fmt.Sprintf(" token%d", i))
+		m.render()
+	}
+}
+
+// BenchmarkRenderViewScroll measures scrolling through a long session that
+// was already rendered
+func BenchmarkRenderViewScroll(b *testing.B) {
+	a := newSyntheticSession(500)
+	m := newTestMessages(a)
+	m.render()
+
+	b.ResetTimer()
+	for range b.N {
+		m.PageUp()
+		m.View()
+	}
+}
+
+func TestNumberCodeBlocksLabelsTopLevelFences(t *testing.T) {
+	text := "```go\nfmt.Println()\n```\n\n1. install\n\n   ```sh\n   npm i\n   ```\n\n```\nplain\n```"
+	expected := "\n_[1] go_\n```go\nfmt.Println()\n```\n\n1. install\n\n   ```sh\n   npm i\n   ```\n\n\n_[3]_\n```\nplain\n```"
+	if got := numberCodeBlocks(text); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,234 @@
+package chat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// searchStatusWidth is the room left next to the query for the match count
+const searchStatusWidth = 24
+
+// search holds the state of the incremental search bar shown below the
+// messages, matches are highlighted in the viewport
+type search struct {
+	input textinput.Model
+	open  bool
+}
+
+func newSearch() search {
+	input := textinput.New()
+	input.Placeholder = "Search conversation"
+	input.Prompt = "/ "
+	input.CharLimit = -1
+	input.VirtualCursor = true
+	return search{input: input}
+}
+
+// findMatches returns the byte ranges of query in the content with ANSI
+// sequences stripped. The search ignores case unless the query contains an
+// upper case letter.
+func findMatches(content string, query string) [][]int {
+	if query == "" {
+		return nil
+	}
+	pattern := regexp.QuoteMeta(query)
+	if strings.ToLower(query) == query {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.MustCompile(pattern).FindAllStringIndex(ansi.Strip(content), -1)
+}
+
+func (m *messagesComponent) Searching() bool {
+	return m.search.open
+}
+
+func (m *messagesComponent) SearchFocused() bool {
+	return m.search.open && m.search.input.Focused()
+}
+
+// StartSearch opens the search bar, or focuses it again if it is already open
+// so the query can be refined
+func (m *messagesComponent) StartSearch() (tea.Model, tea.Cmd) {
+	m.updateSearchStyles()
+	cmd := m.search.input.Focus()
+	if m.search.open {
+		return m, cmd
+	}
+	m.search.open = true
+	m.search.input.SetValue("")
+	return m, tea.Batch(cmd, textinput.Blink, m.renderView())
+}
+
+func (m *messagesComponent) CloseSearch() (tea.Model, tea.Cmd) {
+	if !m.search.open {
+		return m, nil
+	}
+	m.search.open = false
+	m.search.input.Blur()
+	m.search.input.SetValue("")
+	m.viewport.ClearHighlights()
+	return m, m.renderView()
+}
+
+func (m *messagesComponent) NextMatch() (tea.Model, tea.Cmd) {
+	m.tail = false
+	m.viewport.HighlightNext()
+	return m, nil
+}
+
+func (m *messagesComponent) PreviousMatch() (tea.Model, tea.Cmd) {
+	m.tail = false
+	m.viewport.HighlightPrevious()
+	return m, nil
+}
+
+// updateSearch handles key presses while the search bar is open. While the
+// input is focused keys edit the query and enter confirms it, afterwards n and
+// N step through the matches and / refines the query.
+func (m *messagesComponent) updateSearch(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if !m.search.input.Focused() {
+		switch msg.String() {
+		case "n":
+			return m.NextMatch()
+		case "N", "shift+n":
+			return m.PreviousMatch()
+		case "/":
+			return m.StartSearch()
+		case "esc":
+			return m.CloseSearch()
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		return m.CloseSearch()
+	case "enter":
+		if m.search.input.Value() == "" {
+			return m.CloseSearch()
+		}
+		m.search.input.Blur()
+		return m, nil
+	case "down", "ctrl+n":
+		return m.NextMatch()
+	case "up", "ctrl+p":
+		return m.PreviousMatch()
+	}
+
+	query := m.search.input.Value()
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+	if m.search.input.Value() != query {
+		m.applySearch()
+	}
+	return m, cmd
+}
+
+// applySearch highlights the matches of the query in the rendered messages and
+// scrolls to the first match below the current position
+func (m *messagesComponent) applySearch() {
+	m.viewport.ClearHighlights()
+	if !m.search.open {
+		return
+	}
+
+	matches := findMatches(m.viewport.GetContent(), m.search.input.Value())
+	if len(matches) == 0 {
+		return
+	}
+	m.updateSearchStyles()
+	m.viewport.SetHighlights(matches)
+	m.tail = m.viewport.AtBottom()
+}
+
+// reapplySearch highlights the matches again after the messages were rendered,
+// keeping the scroll position and the focused match
+func (m *messagesComponent) reapplySearch(index int) {
+	if !m.search.open {
+		return
+	}
+	offset := m.viewport.YOffset
+	tail := m.tail
+	m.applySearch()
+	m.viewport.SetYOffset(offset)
+	m.viewport.SetHighlightIndex(index)
+	m.tail = tail
+}
+
+func (m *messagesComponent) updateSearchStyles() {
+	t := theme.CurrentTheme()
+	m.viewport.HighlightStyle = styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Foreground(t.Warning()).
+		Lipgloss()
+	m.viewport.SelectedHighlightStyle = styles.NewStyle().
+		Background(t.Warning()).
+		Foreground(t.Background()).
+		Lipgloss()
+
+	m.search.input.Styles.Focused.Prompt = styles.NewStyle().
+		Foreground(t.Primary()).
+		Background(t.BackgroundElement()).
+		Lipgloss()
+	m.search.input.Styles.Blurred.Prompt = styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundElement()).
+		Lipgloss()
+	for _, state := range []*textinput.StyleState{
+		&m.search.input.Styles.Focused,
+		&m.search.input.Styles.Blurred,
+	} {
+		state.Text = styles.NewStyle().
+			Foreground(t.Text()).
+			Background(t.BackgroundElement()).
+			Lipgloss()
+		state.Placeholder = styles.NewStyle().
+			Foreground(t.TextMuted()).
+			Background(t.BackgroundElement()).
+			Lipgloss()
+	}
+	m.search.input.Styles.Cursor.Color = t.Primary()
+}
+
+func (m *messagesComponent) searchHeight() int {
+	if m.search.open {
+		return 1
+	}
+	return 0
+}
+
+// renderSearch renders the search bar with the position of the focused match
+func (m *messagesComponent) renderSearch() string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Background(t.BackgroundElement())
+	muted := base.Foreground(t.TextMuted())
+
+	status := ""
+	if m.search.input.Value() != "" {
+		count := m.viewport.HighlightCount()
+		switch {
+		case count == 0:
+			status = "no matches"
+		case m.viewport.HighlightIndex() < 0:
+			status = fmt.Sprintf("%d matches", count)
+		default:
+			status = fmt.Sprintf("%d of %d", m.viewport.HighlightIndex()+1, count)
+		}
+		if !m.search.input.Focused() && count > 0 {
+			status += "  n/N"
+		}
+	}
+	status = muted.Render(status + " ")
+
+	input := base.Render(m.search.input.View())
+	gap := base.Render(strings.Repeat(" ", max(0, m.width-lipgloss.Width(input)-lipgloss.Width(status))))
+	return input + gap + status
+}
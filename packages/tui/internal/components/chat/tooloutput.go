@@ -0,0 +1,260 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/lipgloss/v2/table"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+const (
+	maxToolOutputLines = 10
+	maxTableColumns    = 8
+	// jsonTreeDepth is the number of levels of a JSON tree expanded, deeper
+	// objects and arrays are collapsed to their size
+	jsonTreeDepth = 2
+)
+
+// jsonNode is a decoded JSON value that keeps the order of object keys
+type jsonNode struct {
+	// kind is '{' for objects, '[' for arrays and 0 for scalars
+	kind     json.Delim
+	value    any
+	keys     []string
+	children []jsonNode
+}
+
+// parseJSON decodes a JSON object or array, keeping the order of the keys
+func parseJSON(data string) (jsonNode, bool) {
+	data = strings.TrimSpace(data)
+	if !strings.HasPrefix(data, "{") && !strings.HasPrefix(data, "[") {
+		return jsonNode{}, false
+	}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	node, err := decodeJSONNode(decoder)
+	if err != nil {
+		return jsonNode{}, false
+	}
+	// trailing content means the output only starts with JSON
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return jsonNode{}, false
+	}
+	return node, true
+}
+
+func decodeJSONNode(decoder *json.Decoder) (jsonNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return jsonNode{}, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return jsonNode{value: token}, nil
+	}
+
+	node := jsonNode{kind: delim}
+	for decoder.More() {
+		if delim == '{' {
+			key, err := decoder.Token()
+			if err != nil {
+				return jsonNode{}, err
+			}
+			node.keys = append(node.keys, fmt.Sprint(key))
+		}
+		child, err := decodeJSONNode(decoder)
+		if err != nil {
+			return jsonNode{}, err
+		}
+		node.children = append(node.children, child)
+	}
+	// closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return jsonNode{}, err
+	}
+	return node, nil
+}
+
+func (n jsonNode) scalar() string {
+	switch value := n.value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// size describes a collapsed object or array
+func (n jsonNode) size() string {
+	if n.kind == '{' {
+		return fmt.Sprintf("{%d}", len(n.children))
+	}
+	return fmt.Sprintf("[%d]", len(n.children))
+}
+
+// table returns the columns and rows of an array of flat objects, the
+// columns are the keys in the order they first appear
+func (n jsonNode) table() ([]string, [][]string, bool) {
+	if n.kind != '[' || len(n.children) == 0 {
+		return nil, nil, false
+	}
+	columns := []string{}
+	index := map[string]int{}
+	for _, child := range n.children {
+		if child.kind != '{' {
+			return nil, nil, false
+		}
+		for i, key := range child.keys {
+			if child.children[i].kind != 0 {
+				return nil, nil, false
+			}
+			if _, ok := index[key]; !ok {
+				index[key] = len(columns)
+				columns = append(columns, key)
+			}
+		}
+	}
+	if len(columns) > maxTableColumns {
+		return nil, nil, false
+	}
+
+	rows := make([][]string, 0, len(n.children))
+	for _, child := range n.children {
+		row := make([]string, len(columns))
+		for i, key := range child.keys {
+			row[index[key]] = child.children[i].scalar()
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows, true
+}
+
+// renderJSONTree renders a JSON value as an indented tree, expanding depth
+// levels and collapsing the objects and arrays below them to their size
+func renderJSONTree(node jsonNode, depth int) string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Background(t.BackgroundPanel())
+	keyStyle := base.Foreground(t.Primary()).Render
+	valueStyle := base.Foreground(t.Text()).Render
+	mutedStyle := base.Foreground(t.TextMuted()).Render
+
+	lines := []string{}
+	var walk func(node jsonNode, label string, indent int, level int)
+	walk = func(node jsonNode, label string, indent int, level int) {
+		prefix := strings.Repeat("  ", indent)
+		if label != "" {
+			label = keyStyle(label) + mutedStyle(": ")
+		}
+		if node.kind == 0 {
+			value := node.scalar()
+			if _, ok := node.value.(string); ok {
+				value = fmt.Sprintf("%q", value)
+			}
+			lines = append(lines, base.Render(prefix+"  ")+label+valueStyle(value))
+			return
+		}
+		if level >= depth || len(node.children) == 0 {
+			lines = append(lines, base.Render(prefix)+mutedStyle("▸ ")+label+mutedStyle(node.size()))
+			return
+		}
+		lines = append(lines, base.Render(prefix)+mutedStyle("▾ ")+label+mutedStyle(node.size()))
+		for i, child := range node.children {
+			key := fmt.Sprint(i)
+			if node.kind == '{' {
+				key = node.keys[i]
+			}
+			walk(child, key, indent+1, level+1)
+		}
+	}
+	walk(node, "", 0, 0)
+	return strings.Join(lines, "\n")
+}
+
+// delimitedTable splits tab separated output into rows, the first row is
+// used as the header. Every line must have the same number of columns.
+func delimitedTable(output string) ([]string, [][]string, bool) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return nil, nil, false
+	}
+	rows := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		cells := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(cells) < 2 || len(cells) > maxTableColumns || (len(rows) > 0 && len(cells) != len(rows[0])) {
+			return nil, nil, false
+		}
+		rows = append(rows, cells)
+	}
+	return rows[0], rows[1:], true
+}
+
+// renderTable renders rows under a header, showing the first maxRows rows
+// only, or all of them when maxRows is 0
+func renderTable(columns []string, rows [][]string, width int, maxRows int) string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Background(t.BackgroundPanel())
+	hidden := 0
+	if maxRows > 0 && len(rows) > maxRows {
+		hidden = len(rows) - maxRows
+		rows = rows[:maxRows]
+	}
+
+	tbl := table.New().
+		Headers(columns...).
+		Rows(rows...).
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(base.Foreground(t.BorderSubtle()).Lipgloss()).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			style := base.Foreground(t.Text()).Padding(0, 1)
+			if row == table.HeaderRow {
+				style = style.Foreground(t.TextMuted()).Bold(true)
+			}
+			return style.Lipgloss()
+		})
+	rendered := tbl.String()
+	// shrink the columns of tables wider than the block
+	if lipgloss.Width(rendered) > width {
+		rendered = tbl.Width(width).Wrap(false).String()
+	}
+	if hidden > 0 {
+		rendered += "\n" + base.Foreground(t.TextMuted()).Render(fmt.Sprintf("… %d more rows", hidden))
+	}
+	return rendered
+}
+
+// renderToolOutput renders the output of a tool without a renderer of its
+// own. JSON output is shown as a tree, or as a table when it is a list of
+// records, and tab separated output as a table. Expanded calls show the
+// whole tree and every row.
+func renderToolOutput(call ToolCall, width int) string {
+	t := theme.CurrentTheme()
+	defaultStyle := styles.NewStyle().Background(t.BackgroundPanel()).Width(width - 6).Render
+
+	depth, maxRows := jsonTreeDepth, maxToolOutputLines-3
+	if call.Expanded {
+		depth, maxRows = math.MaxInt, 0
+	}
+	output := ""
+	if call.Output != nil {
+		output = *call.Output
+	}
+	if node, ok := parseJSON(output); ok {
+		if columns, rows, ok := node.table(); ok {
+			return defaultStyle(renderTable(columns, rows, width-6, maxRows))
+		}
+		return defaultStyle(call.truncate(renderJSONTree(node, depth)))
+	}
+	if columns, rows, ok := delimitedTable(output); ok {
+		return defaultStyle(renderTable(columns, rows, width-6, maxRows))
+	}
+	return defaultStyle(call.truncate(output))
+}
@@ -0,0 +1,398 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/diff"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// ToolCall is a tool part with its input and metadata decoded, as passed to
+// the renderers of a tool
+type ToolCall struct {
+	Part     opencode.ToolPart
+	Input    map[string]any
+	Metadata map[string]any
+	Output   *string
+	// Expanded is set when the user expanded the call, its output should be
+	// shown in full rather than cut to its first lines
+	Expanded bool
+}
+
+func newToolCall(part opencode.ToolPart) ToolCall {
+	call := ToolCall{Part: part, Input: map[string]any{}}
+	if input, ok := part.State.Input.(map[string]any); ok {
+		call.Input = input
+	}
+	if metadata, ok := part.State.Metadata.(map[string]any); ok {
+		call.Metadata = metadata
+	}
+	if part.State.Output != "" {
+		call.Output = &part.State.Output
+	}
+	return call
+}
+
+// truncate cuts the output of a call to its first lines, unless the call
+// was expanded
+func (c ToolCall) truncate(output string) string {
+	if c.Expanded {
+		return output
+	}
+	return util.TruncateHeight(output, maxToolOutputLines)
+}
+
+// ToolRenderer customizes how the calls of a tool are shown in the
+// conversation, any function left nil falls back to the default rendering
+type ToolRenderer struct {
+	// Action describes the call while its input is still being generated
+	Action string
+	// Title renders the title of the call, tool name included
+	Title func(call ToolCall) string
+	// Body renders the output of a finished call below its title
+	Body func(app *app.App, call ToolCall, width int) string
+	// Summary describes the result of a finished call in a few words, it is
+	// shown next to the title when the details of the call are hidden
+	Summary func(call ToolCall) string
+	// Block renders the whole block of a finished call in place of the
+	// title and body, an empty block falls back to them
+	Block func(app *app.App, call ToolCall, width int) string
+}
+
+type toolRendererEntry struct {
+	pattern  string
+	renderer ToolRenderer
+}
+
+var toolRenderers []toolRendererEntry
+
+// RegisterToolRenderer registers the renderer for the tools matching the
+// pattern, either a tool name or a glob such as "github_*" matching all the
+// tools of an MCP server. A tool name takes precedence over the patterns
+// matching it, and the last pattern registered takes precedence over the
+// others. Renderers are meant to be registered from init functions.
+func RegisterToolRenderer(pattern string, renderer ToolRenderer) {
+	toolRenderers = append(toolRenderers, toolRendererEntry{
+		pattern:  pattern,
+		renderer: renderer,
+	})
+}
+
+// toolRendererFor returns the renderer registered for a tool, the zero
+// renderer when there is none
+func toolRendererFor(name string) ToolRenderer {
+	for i := len(toolRenderers) - 1; i >= 0; i-- {
+		if toolRenderers[i].pattern == name {
+			return toolRenderers[i].renderer
+		}
+	}
+	for i := len(toolRenderers) - 1; i >= 0; i-- {
+		if ok, _ := path.Match(toolRenderers[i].pattern, name); ok {
+			return toolRenderers[i].renderer
+		}
+	}
+	return ToolRenderer{}
+}
+
+// renderToolSummary describes the result of a completed tool call with the
+// summary of its renderer, if any
+func renderToolSummary(part opencode.ToolPart) string {
+	renderer := toolRendererFor(part.Tool)
+	if renderer.Summary == nil || part.State.Status != opencode.ToolPartStateStatusCompleted {
+		return ""
+	}
+	return renderer.Summary(newToolCall(part))
+}
+
+func init() {
+	RegisterToolRenderer("read", ToolRenderer{
+		Action: "Reading file...",
+		Title: func(call ToolCall) string {
+			return fmt.Sprintf("%s %s", renderToolName(call.Part.Tool), renderArgs(&call.Input, "filePath"))
+		},
+		Body: func(app *app.App, call ToolCall, width int) string {
+			preview, ok := call.Metadata["preview"].(string)
+			filename, hasFilename := call.Input["filePath"].(string)
+			if !ok || !hasFilename {
+				return ""
+			}
+			return util.RenderFile(filename, preview, width, util.WithTruncate(6))
+		},
+	})
+	RegisterToolRenderer("edit", ToolRenderer{
+		Action:  "Preparing edit...",
+		Title:   fileToolTitle,
+		Summary: editSummary,
+		Block:   renderEditBlock,
+	})
+	RegisterToolRenderer("write", ToolRenderer{
+		Action: "Preparing write...",
+		Title:  fileToolTitle,
+		Body: func(app *app.App, call ToolCall, width int) string {
+			filename, ok := call.Input["filePath"].(string)
+			if !ok {
+				return ""
+			}
+			content, ok := call.Input["content"].(string)
+			if !ok {
+				return ""
+			}
+			body := util.RenderFile(filename, content, width)
+			backgroundColor := theme.CurrentTheme().BackgroundPanel()
+			if diagnostics := renderDiagnostics(call.Metadata, filename, backgroundColor, width-4); diagnostics != "" {
+				body += "\n\n" + diagnostics
+			}
+			return body
+		},
+	})
+	RegisterToolRenderer("bash", ToolRenderer{
+		Action: "Writing command...",
+		Title: func(call ToolCall) string {
+			title := renderToolName(call.Part.Tool)
+			if description, ok := call.Input["description"].(string); ok {
+				title = fmt.Sprintf("%s %s", title, description)
+			}
+			return title
+		},
+		Body: func(app *app.App, call ToolCall, width int) string {
+			command, _ := call.Input["command"].(string)
+			body := fmt.Sprintf("```console\n$ %s\n", command)
+			stdout := call.Metadata["stdout"]
+			if stdout != nil {
+				body += call.truncate(ansi.Strip(fmt.Sprintf("%s", stdout)))
+			}
+			body += "```"
+			return util.ToMarkdown(body, width, theme.CurrentTheme().BackgroundPanel())
+		},
+		Summary: func(call ToolCall) string {
+			if exit, ok := call.Metadata["exit"].(float64); ok && exit != 0 {
+				return fmt.Sprintf("exit %d", int(exit))
+			}
+			return ""
+		},
+	})
+	RegisterToolRenderer("webfetch", ToolRenderer{
+		Action: "Fetching from the web...",
+		Title: func(call ToolCall) string {
+			return fmt.Sprintf("%s %s", renderToolName(call.Part.Tool), renderArgs(&call.Input, "url"))
+		},
+		Body: func(app *app.App, call ToolCall, width int) string {
+			format, ok := call.Input["format"].(string)
+			if !ok || call.Output == nil {
+				return ""
+			}
+			body := call.truncate(*call.Output)
+			if format == "html" || format == "markdown" {
+				body = util.ToMarkdown(body, width, theme.CurrentTheme().BackgroundPanel())
+			}
+			return body
+		},
+	})
+	RegisterToolRenderer("todowrite", ToolRenderer{
+		Action: "Planning...",
+		Title: func(call ToolCall) string {
+			return getTodoTitle(call.Part)
+		},
+		Body: func(app *app.App, call ToolCall, width int) string {
+			todos, ok := call.Metadata["todos"].([]any)
+			if !ok {
+				return ""
+			}
+			body := ""
+			for _, item := range todos {
+				todo := item.(map[string]any)
+				content := todo["content"].(string)
+				switch todo["status"] {
+				case "completed":
+					body += fmt.Sprintf("- [x] %s\n", content)
+				case "cancelled":
+					// strike through cancelled todo
+					body += fmt.Sprintf("- [ ] ~~%s~~\n", content)
+				case "in_progress":
+					// highlight in progress todo
+					body += fmt.Sprintf("- [ ] `%s`\n", content)
+				default:
+					body += fmt.Sprintf("- [ ] %s\n", content)
+				}
+			}
+			return util.ToMarkdown(body, width, theme.CurrentTheme().BackgroundPanel())
+		},
+		Summary: func(call ToolCall) string {
+			todos, ok := call.Metadata["todos"].([]any)
+			if !ok || len(todos) == 0 {
+				return ""
+			}
+			completed := 0
+			for _, item := range todos {
+				if todo, ok := item.(map[string]any); ok && todo["status"] == "completed" {
+					completed++
+				}
+			}
+			return fmt.Sprintf("%d/%d done", completed, len(todos))
+		},
+	})
+	RegisterToolRenderer("todoread", ToolRenderer{
+		Action: "Planning...",
+		Title: func(call ToolCall) string {
+			return "Plan"
+		},
+	})
+	RegisterToolRenderer("task", ToolRenderer{
+		Action: "Delegating...",
+		Title: func(call ToolCall) string {
+			title := renderToolName(call.Part.Tool)
+			description := call.Input["description"]
+			subagent := call.Input["subagent_type"]
+			if description != nil && subagent != nil {
+				title = fmt.Sprintf("%s[%s] %s", title, subagent, description)
+			} else if description != nil {
+				title = fmt.Sprintf("%s %s", title, description)
+			}
+			return title
+		},
+		Body: func(app *app.App, call ToolCall, width int) string {
+			body := ""
+			if summary, ok := call.Metadata["summary"].([]any); ok {
+				steps := []string{}
+				for _, item := range summary {
+					data, _ := json.Marshal(item)
+					var toolCall opencode.ToolPart
+					_ = json.Unmarshal(data, &toolCall)
+					step := renderToolTitle(toolCall, width)
+					step = "∟ " + step
+					steps = append(steps, step)
+				}
+				body = strings.Join(steps, "\n")
+			}
+			return styles.NewStyle().
+				Background(theme.CurrentTheme().BackgroundPanel()).
+				Width(width - 6).
+				Render(body)
+		},
+	})
+	RegisterToolRenderer("glob", ToolRenderer{
+		Action:  "Finding files...",
+		Summary: countSummary("count", "file"),
+	})
+	RegisterToolRenderer("grep", ToolRenderer{
+		Action:  "Searching content...",
+		Summary: countSummary("matches", "match"),
+	})
+	RegisterToolRenderer("list", ToolRenderer{
+		Action:  "Listing directory...",
+		Summary: countSummary("count", "file"),
+	})
+	RegisterToolRenderer("patch", ToolRenderer{
+		Action: "Preparing patch...",
+	})
+}
+
+// fileToolTitle titles the call of a tool editing a file with the path of
+// the file
+func fileToolTitle(call ToolCall) string {
+	title := renderToolName(call.Part.Tool)
+	if filename, ok := call.Input["filePath"].(string); ok {
+		title = fmt.Sprintf("%s %s", title, util.Relative(filename))
+	}
+	return title
+}
+
+// renderEditBlock renders the diff of an edit with its title inside the
+// block, along with the errors reported for the file
+func renderEditBlock(app *app.App, call ToolCall, width int) string {
+	filename, ok := call.Input["filePath"].(string)
+	if !ok {
+		return ""
+	}
+	patch, ok := call.Metadata["diff"].(string)
+	if !ok {
+		return ""
+	}
+
+	t := theme.CurrentTheme()
+	backgroundColor := t.BackgroundPanel()
+	var formattedDiff string
+	if width < 120 {
+		formattedDiff, _ = diff.FormatUnifiedDiff(
+			filename,
+			patch,
+			diff.WithWidth(width-2),
+		)
+	} else {
+		formattedDiff, _ = diff.FormatDiff(
+			filename,
+			patch,
+			diff.WithWidth(width-2),
+		)
+	}
+	body := strings.TrimSpace(formattedDiff)
+	style := styles.NewStyle().
+		Background(backgroundColor).
+		Foreground(t.TextMuted()).
+		Padding(1, 2).
+		Width(width - 4)
+
+	if diagnostics := renderDiagnostics(call.Metadata, filename, backgroundColor, width-6); diagnostics != "" {
+		diagnostics = style.Render(diagnostics)
+		body += "\n" + diagnostics
+	}
+
+	title := renderToolTitle(call.Part, width)
+	title = style.Render(title)
+	content := title + "\n" + body
+	return renderContentBlock(
+		app,
+		content,
+		width,
+		WithPadding(0),
+		WithBorderColor(backgroundColor),
+	)
+}
+
+// editSummary counts the lines added and removed by an edit
+func editSummary(call ToolCall) string {
+	patch, ok := call.Metadata["diff"].(string)
+	if !ok {
+		return ""
+	}
+	added, removed := 0, 0
+	for line := range strings.SplitSeq(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return fmt.Sprintf("+%d -%d", added, removed)
+}
+
+// countSummary summarizes a call with a count reported in its metadata
+func countSummary(key string, noun string) func(call ToolCall) string {
+	return func(call ToolCall) string {
+		count, ok := call.Metadata[key].(float64)
+		if !ok {
+			return ""
+		}
+		if count != 1 {
+			if strings.HasSuffix(noun, "ch") {
+				noun += "es"
+			} else {
+				noun += "s"
+			}
+		}
+		if truncated, _ := call.Metadata["truncated"].(bool); truncated {
+			return fmt.Sprintf("%d+ %s", int(count), noun)
+		}
+		return fmt.Sprintf("%d %s", int(count), noun)
+	}
+}
@@ -0,0 +1,117 @@
+package chat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode/internal/theme"
+)
+
+func TestToolRendererFor(t *testing.T) {
+	registered := len(toolRenderers)
+	defer func() { toolRenderers = toolRenderers[:registered] }()
+
+	RegisterToolRenderer("github_*", ToolRenderer{Action: "Calling GitHub..."})
+	RegisterToolRenderer("github_search", ToolRenderer{Action: "Searching GitHub..."})
+	RegisterToolRenderer("github_*", ToolRenderer{Action: "Asking GitHub..."})
+
+	if action := toolRendererFor("github_issues").Action; action != "Asking GitHub..." {
+		t.Errorf("expected the last pattern registered to win, got %q", action)
+	}
+	if action := toolRendererFor("github_search").Action; action != "Searching GitHub..." {
+		t.Errorf("expected the tool name to take precedence over patterns, got %q", action)
+	}
+	if action := toolRendererFor("read").Action; action != "Reading file..." {
+		t.Errorf("expected the built-in renderer, got %q", action)
+	}
+	if action := renderToolAction("unknown"); action != "Working..." {
+		t.Errorf("expected the default action, got %q", action)
+	}
+}
+
+func TestParseJSONTable(t *testing.T) {
+	node, ok := parseJSON(`[{"name": "a", "size": 1}, {"size": 2, "name": "b", "mode": null}]`)
+	if !ok {
+		t.Fatal("expected the output to parse as JSON")
+	}
+	columns, rows, ok := node.table()
+	if !ok {
+		t.Fatal("expected a list of flat objects to render as a table")
+	}
+	if !reflect.DeepEqual(columns, []string{"name", "size", "mode"}) {
+		t.Errorf("unexpected columns %v", columns)
+	}
+	if !reflect.DeepEqual(rows, [][]string{{"a", "1", ""}, {"b", "2", "null"}}) {
+		t.Errorf("unexpected rows %v", rows)
+	}
+
+	if _, ok := parseJSON(`{"a": 1} and more`); ok {
+		t.Errorf("expected output with trailing text not to parse as JSON")
+	}
+	nested, _ := parseJSON(`[{"name": "a", "tags": ["x"]}]`)
+	if _, _, ok := nested.table(); ok {
+		t.Errorf("expected nested values not to render as a table")
+	}
+}
+
+func TestRenderJSONTreeCollapsesDeepLevels(t *testing.T) {
+	if err := theme.LoadThemesFromJSON(); err != nil {
+		t.Fatal(err)
+	}
+	theme.SetTheme("opencode")
+
+	node, _ := parseJSON(`{"z": 1, "a": {"b": {"c": true}}}`)
+	tree := ansi.Strip(renderJSONTree(node, 2))
+	expected := strings.Join([]string{
+		"▾ {2}",
+		"    z: 1",
+		"  ▾ a: {1}",
+		"    ▸ b: {1}",
+	}, "\n")
+	if tree != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, tree)
+	}
+}
+
+func TestDelimitedTable(t *testing.T) {
+	columns, rows, ok := delimitedTable("NAME\tSTATUS\nweb\tup\ndb\tdown\n")
+	if !ok || !reflect.DeepEqual(columns, []string{"NAME", "STATUS"}) || len(rows) != 2 {
+		t.Errorf("expected a table, got %v %v %v", columns, rows, ok)
+	}
+	if _, _, ok := delimitedTable("a\tb\nc"); ok {
+		t.Errorf("expected rows of different lengths not to be a table")
+	}
+}
+
+func TestExpandedToolOutput(t *testing.T) {
+	theme.LoadThemesFromJSON()
+	theme.SetTheme("opencode")
+
+	lines := make([]string, 30)
+	for i := range lines {
+		lines[i] = "line"
+	}
+	output := strings.Join(lines, "\n")
+	call := ToolCall{Output: &output}
+
+	if height := strings.Count(ansi.Strip(renderToolOutput(call, 80)), "\n") + 1; height != maxToolOutputLines {
+		t.Errorf("expected a collapsed call to show %d lines, got %d", maxToolOutputLines, height)
+	}
+	call.Expanded = true
+	if height := strings.Count(ansi.Strip(renderToolOutput(call, 80)), "\n") + 1; height != len(lines) {
+		t.Errorf("expected an expanded call to show %d lines, got %d", len(lines), height)
+	}
+
+	cache := NewPartCache()
+	cache.SetExpanded("prt_1", true)
+	cache.Clear()
+	if !cache.Expanded("prt_1") {
+		t.Error("expected the expanded parts to be kept when the cache is cleared")
+	}
+	cache.SetExpanded("prt_1", false)
+	if cache.Expanded("prt_1") {
+		t.Error("expected the part to be collapsed")
+	}
+}
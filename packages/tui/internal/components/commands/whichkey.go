@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+const (
+	whichKeyColumnPadding       = 3
+	whichKeyMinDescriptionWidth = 16
+)
+
+// WhichKey renders the keys that can follow the keys of a pending key
+// sequence along with their commands, in columns fitting the width. It's
+// empty when no key sequence is pending.
+func WhichKey(app *app.App, width int) string {
+	if !app.KeySequencePending() {
+		return ""
+	}
+	continuations := app.Commands.Continuations(app.KeySequence, app.IsLeaderSequence)
+	if len(continuations) == 0 {
+		return ""
+	}
+
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Background(t.BackgroundElement())
+	keyStyle := base.Foreground(t.Text()).Bold(true)
+	descriptionStyle := base.Foreground(t.TextMuted())
+	moreStyle := base.Foreground(t.Accent())
+
+	// the borders and padding take 4 columns
+	innerWidth := max(1, width-4)
+	keyWidth := 0
+	for _, continuation := range continuations {
+		keyWidth = max(keyWidth, lipgloss.Width(continuation.Key))
+	}
+
+	type cell struct {
+		key, description string
+		more             bool
+	}
+	// the descriptions are cut to fit up to 3 columns, unless they get too
+	// short to read
+	descriptionWidth := innerWidth - keyWidth - 1
+	for columns := 3; columns > 1; columns-- {
+		narrow := (innerWidth-(columns-1)*whichKeyColumnPadding)/columns - keyWidth - 1
+		if narrow >= whichKeyMinDescriptionWidth {
+			descriptionWidth = narrow
+			break
+		}
+	}
+	cells := make([]cell, 0, len(continuations))
+	cellWidth := 0
+	for _, continuation := range continuations {
+		descriptions := make([]string, 0, len(continuation.Commands))
+		for _, command := range continuation.Commands {
+			descriptions = append(descriptions, command.Description)
+		}
+		description := strings.Join(descriptions, ", ")
+		more := len(descriptions) == 0
+		if continuation.More > 0 {
+			if description != "" {
+				description += ", "
+			}
+			description += fmt.Sprintf("+%d more", continuation.More)
+		}
+		description = truncate.StringWithTail(description, uint(max(0, descriptionWidth)), "…")
+		cells = append(cells, cell{key: continuation.Key, description: description, more: more})
+		cellWidth = max(cellWidth, keyWidth+1+lipgloss.Width(description))
+	}
+
+	columns := max(1, (innerWidth+whichKeyColumnPadding)/(cellWidth+whichKeyColumnPadding))
+	rows := (len(cells) + columns - 1) / columns
+	lines := make([]string, rows)
+	for i, c := range cells {
+		row := i % rows
+		description := descriptionStyle.Render(c.description)
+		if c.more {
+			description = moreStyle.Render(c.description)
+		}
+		rendered := keyStyle.Width(keyWidth).Render(c.key) + base.Render(" ") + description
+		if i >= rows {
+			lines[row] += base.Render(strings.Repeat(" ", whichKeyColumnPadding))
+		}
+		if i+rows < len(cells) {
+			rendered = base.Width(cellWidth).Render(rendered)
+		}
+		lines[row] += rendered
+	}
+
+	title := base.Foreground(t.Accent()).Bold(true).Render(app.KeySequenceText()) +
+		descriptionStyle.Render(" …")
+	content := title + "\n" + strings.Join(lines, "\n")
+
+	return base.
+		Padding(0, 1).
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderLeft(true).
+		BorderRight(true).
+		BorderForeground(t.Accent()).
+		BorderBackground(t.Background()).
+		Width(width).
+		Render(content)
+}
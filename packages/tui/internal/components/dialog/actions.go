@@ -0,0 +1,171 @@
+package dialog
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const messageActionsDialogWidth = 56
+
+// MessageActionKind identifies what a message action does
+type MessageActionKind int
+
+const (
+	// MessageActionCopy copies the text of a message
+	MessageActionCopy MessageActionKind = iota
+	// MessageActionCopyCode copies a code block of a message
+	MessageActionCopyCode
+	// MessageActionCopyOutput copies the raw output of a tool call
+	MessageActionCopyOutput
+	// MessageActionEdit reverts a user message and the ones after it, and
+	// puts it back in the editor to be edited and sent again
+	MessageActionEdit
+	// MessageActionRevert reverts the messages after a message, along with
+	// their changes to the workspace
+	MessageActionRevert
+)
+
+// MessageAction is an action offered for a message part
+type MessageAction struct {
+	Kind   MessageActionKind
+	Title  string
+	Detail string
+	// Index is the index of the code block copied by MessageActionCopyCode
+	Index int
+}
+
+// MessageActionSelectedMsg is sent when an action is picked for a part
+type MessageActionSelectedMsg struct {
+	PartID string
+	Action MessageAction
+}
+
+// MessageActionsDialog interface for the message actions menu
+type MessageActionsDialog interface {
+	layout.Modal
+}
+
+// messageActionItem is a list item for an action, with its detail muted
+type messageActionItem struct {
+	action MessageAction
+}
+
+func (m messageActionItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	itemStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	detailStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+
+	title := truncate.StringWithTail(m.action.Title, uint(max(0, width-2)), "...")
+	detail := ""
+	if m.action.Detail != "" {
+		detail = truncate.StringWithTail(
+			"  "+m.action.Detail,
+			uint(max(0, width-lipgloss.Width(title)-2)),
+			"...",
+		)
+	}
+	return itemStyle.PaddingLeft(1).Render(title) + detailStyle.Render(detail)
+}
+
+func (m messageActionItem) Selectable() bool {
+	return true
+}
+
+type messageActionsDialog struct {
+	width  int
+	height int
+	partID string
+	modal  *modal.Modal
+	list   list.List[list.Item]
+}
+
+func (m *messageActionsDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (m *messageActionsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			if item, idx := m.list.GetSelectedItem(); idx >= 0 {
+				if actionItem, ok := item.(messageActionItem); ok {
+					return m, tea.Sequence(
+						util.CmdHandler(modal.CloseModalMsg{}),
+						util.CmdHandler(MessageActionSelectedMsg{
+							PartID: m.partID,
+							Action: actionItem.action,
+						}),
+					)
+				}
+			}
+		}
+	}
+
+	listModel, cmd := m.list.Update(msg)
+	m.list = listModel.(list.List[list.Item])
+	return m, cmd
+}
+
+func (m *messageActionsDialog) Render(background string) string {
+	return m.modal.Render(m.list.View(), background)
+}
+
+func (m *messageActionsDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewMessageActionsDialog creates a menu of the actions available for a
+// message part
+func NewMessageActionsDialog(partID string, actions []MessageAction) MessageActionsDialog {
+	width := min(messageActionsDialogWidth, layout.Current.Container.Width-12)
+
+	items := make([]list.Item, len(actions))
+	for i, action := range actions {
+		items[i] = messageActionItem{action: action}
+	}
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[list.Item](10),
+		list.WithFallbackMessage[list.Item]("No actions available"),
+		list.WithAlphaNumericKeys[list.Item](true),
+		list.WithRenderFunc(func(item list.Item, selected bool, width int, baseStyle styles.Style) string {
+			return item.Render(selected, width, baseStyle)
+		}),
+		list.WithSelectableFunc(func(item list.Item) bool {
+			return item.Selectable()
+		}),
+	)
+	listComponent.SetMaxWidth(width)
+
+	return &messageActionsDialog{
+		partID: partID,
+		list:   listComponent,
+		modal: modal.New(
+			modal.WithTitle("Message Actions"),
+			modal.WithMaxWidth(width+4),
+		),
+	}
+}
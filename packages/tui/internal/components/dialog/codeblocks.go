@@ -0,0 +1,153 @@
+package dialog
+
+import (
+	"fmt"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const codeBlocksDialogWidth = 72
+
+// CodeBlocksDialog interface for the code block picker
+type CodeBlocksDialog interface {
+	layout.Modal
+}
+
+// codeBlockItem is a list item for a code block, showing its number, language
+// and first line
+type codeBlockItem struct {
+	number int
+	block  util.CodeBlock
+}
+
+func (c codeBlockItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	itemStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	mutedStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+
+	number := mutedStyle.PaddingLeft(1).Render(fmt.Sprintf("%2d ", c.number))
+	language := c.block.Language
+	if language == "" {
+		language = "text"
+	}
+	language = mutedStyle.Render(fmt.Sprintf("%-10s ", truncate.StringWithTail(language, 10, "…")))
+	available := max(0, width-lipgloss.Width(number)-lipgloss.Width(language)-1)
+	line := truncate.StringWithTail(c.block.FirstLine(), uint(available), "...")
+	return number + language + itemStyle.Render(line)
+}
+
+func (c codeBlockItem) Selectable() bool {
+	return true
+}
+
+type codeBlocksDialog struct {
+	width  int
+	height int
+	blocks []util.CodeBlock
+	modal  *modal.Modal
+	list   list.List[list.Item]
+}
+
+func (c *codeBlocksDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (c *codeBlocksDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+	case tea.KeyMsg:
+		key := msg.String()
+		// the number of a block copies it right away
+		if number, err := strconv.Atoi(key); err == nil {
+			if number >= 1 && number <= len(c.blocks) {
+				return c, c.copy(number - 1)
+			}
+			return c, nil
+		}
+		if key == "enter" {
+			if _, idx := c.list.GetSelectedItem(); idx >= 0 {
+				return c, c.copy(idx)
+			}
+		}
+	}
+
+	listModel, cmd := c.list.Update(msg)
+	c.list = listModel.(list.List[list.Item])
+	return c, cmd
+}
+
+// copy copies the contents of a block, without its fences, and closes the
+// dialog
+func (c *codeBlocksDialog) copy(index int) tea.Cmd {
+	return tea.Sequence(
+		util.CmdHandler(modal.CloseModalMsg{}),
+		app.SetClipboard(c.blocks[index].Code),
+		toast.NewSuccessToast(fmt.Sprintf("Code block %d copied to clipboard", index+1)),
+	)
+}
+
+func (c *codeBlocksDialog) Render(background string) string {
+	return c.modal.Render(c.list.View(), background)
+}
+
+func (c *codeBlocksDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewCodeBlocksDialog creates a dialog to pick one of the code blocks of a
+// message to copy, by moving to it or typing its number
+func NewCodeBlocksDialog(blocks []util.CodeBlock) CodeBlocksDialog {
+	width := min(codeBlocksDialogWidth, layout.Current.Container.Width-12)
+
+	items := make([]list.Item, len(blocks))
+	for i, block := range blocks {
+		items[i] = codeBlockItem{number: i + 1, block: block}
+	}
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[list.Item](10),
+		list.WithFallbackMessage[list.Item]("No code blocks"),
+		list.WithAlphaNumericKeys[list.Item](true),
+		list.WithRenderFunc(func(item list.Item, selected bool, width int, baseStyle styles.Style) string {
+			return item.Render(selected, width, baseStyle)
+		}),
+		list.WithSelectableFunc(func(item list.Item) bool {
+			return item.Selectable()
+		}),
+	)
+	listComponent.SetMaxWidth(width)
+
+	return &codeBlocksDialog{
+		blocks: blocks,
+		list:   listComponent,
+		modal: modal.New(
+			modal.WithTitle("Copy Code Block"),
+			modal.WithMaxWidth(width+4),
+		),
+	}
+}
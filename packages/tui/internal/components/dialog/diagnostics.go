@@ -0,0 +1,187 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// DiagnosticSelectedMsg is sent when a diagnostic is chosen from the panel
+type DiagnosticSelectedMsg struct {
+	FilePath string
+	Line     int
+}
+
+// DiagnosticsDialog interface for the diagnostics panel
+type DiagnosticsDialog interface {
+	layout.Modal
+}
+
+// diagnosticItem is a custom list item for a single diagnostic
+type diagnosticItem struct {
+	path       string
+	diagnostic app.Diagnostic
+}
+
+func (d diagnosticItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	label := "I"
+	severityStyle := baseStyle.Foreground(t.Info())
+	switch d.diagnostic.Severity {
+	case app.DiagnosticSeverityError:
+		label = "E"
+		severityStyle = baseStyle.Foreground(t.Error())
+	case app.DiagnosticSeverityWarning:
+		label = "W"
+		severityStyle = baseStyle.Foreground(t.Warning())
+	case app.DiagnosticSeverityHint:
+		label = "H"
+		severityStyle = baseStyle.Foreground(t.TextMuted())
+	}
+
+	position := fmt.Sprintf(
+		" %d:%d ",
+		d.diagnostic.Range.Start.Line+1,
+		d.diagnostic.Range.Start.Character+1,
+	)
+	message := strings.ReplaceAll(d.diagnostic.Message, "\n", " ")
+	message = truncate.StringWithTail(message, uint(max(0, width-len(position)-3)), "...")
+
+	itemStyle := baseStyle.Foreground(t.Text())
+	positionStyle := baseStyle.Foreground(t.TextMuted())
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+
+	return baseStyle.
+		PaddingLeft(1).
+		Render(severityStyle.Bold(true).Render(label) + positionStyle.Render(position) + itemStyle.Render(message))
+}
+
+func (d diagnosticItem) Selectable() bool {
+	return true
+}
+
+type diagnosticsDialog struct {
+	width  int
+	height int
+	app    *app.App
+	modal  *modal.Modal
+	list   list.List[list.Item]
+}
+
+func (d *diagnosticsDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (d *diagnosticsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		d.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case opencode.EventListResponseEventLspClientDiagnostics,
+		opencode.EventListResponseEventMessagePartUpdated:
+		d.updateListItems()
+		return d, nil
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter":
+			if item, idx := d.list.GetSelectedItem(); idx >= 0 {
+				if selected, ok := item.(diagnosticItem); ok {
+					return d, tea.Sequence(
+						util.CmdHandler(modal.CloseModalMsg{}),
+						util.CmdHandler(DiagnosticSelectedMsg{
+							FilePath: selected.path,
+							Line:     selected.diagnostic.Range.Start.Line,
+						}),
+					)
+				}
+			}
+			return d, nil
+		}
+	}
+
+	listModel, cmd := d.list.Update(msg)
+	d.list = listModel.(list.List[list.Item])
+	return d, cmd
+}
+
+func (d *diagnosticsDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	errors, warnings := d.app.Diagnostics.Counts()
+	d.modal.SetTitle(fmt.Sprintf("Diagnostics (%d errors, %d warnings)", errors, warnings))
+
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+	helpText := keyStyle("enter") + mutedStyle(" open file at diagnostic")
+	helpText = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpText)
+
+	content := strings.Join([]string{d.list.View(), helpText}, "\n")
+	return d.modal.Render(content, background)
+}
+
+// updateListItems rebuilds the list from the diagnostics store, keeping the
+// current selection in place
+func (d *diagnosticsDialog) updateListItems() {
+	_, currentIdx := d.list.GetSelectedItem()
+	d.list.SetItems(buildDiagnosticItems(d.app.Diagnostics))
+	d.list.SetSelectedIndex(currentIdx)
+}
+
+func buildDiagnosticItems(store *app.Diagnostics) []list.Item {
+	items := []list.Item{}
+	for _, path := range store.Files() {
+		diagnostics := store.Get(path)
+		items = append(items, list.HeaderItem(fmt.Sprintf("%s (%d)", util.Relative(path), len(diagnostics))))
+		for _, diagnostic := range diagnostics {
+			items = append(items, diagnosticItem{path: path, diagnostic: diagnostic})
+		}
+	}
+	return items
+}
+
+func (d *diagnosticsDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewDiagnosticsDialog creates a new panel listing the current diagnostics
+func NewDiagnosticsDialog(app *app.App) DiagnosticsDialog {
+	listComponent := list.NewListComponent(
+		list.WithItems(buildDiagnosticItems(app.Diagnostics)),
+		list.WithMaxVisibleHeight[list.Item](14),
+		list.WithFallbackMessage[list.Item]("No diagnostics reported"),
+		list.WithAlphaNumericKeys[list.Item](true),
+		list.WithRenderFunc(func(item list.Item, selected bool, width int, baseStyle styles.Style) string {
+			return item.Render(selected, width, baseStyle)
+		}),
+		list.WithSelectableFunc(func(item list.Item) bool {
+			return item.Selectable()
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &diagnosticsDialog{
+		app:  app,
+		list: listComponent,
+		modal: modal.New(
+			modal.WithTitle("Diagnostics"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
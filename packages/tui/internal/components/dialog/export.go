@@ -0,0 +1,177 @@
+package dialog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/export"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const exportDialogWidth = 64
+
+// ExportSelectedMsg is sent when a destination for the export is chosen, an
+// empty path writes to the default file name for the format
+type ExportSelectedMsg struct {
+	Format export.Format
+	Path   string
+	Editor bool
+}
+
+// ExportDialog interface for the export destination dialog
+type ExportDialog interface {
+	layout.Modal
+}
+
+// exportItem is a custom list item for an export destination
+type exportItem struct {
+	format export.Format
+	path   string
+	editor bool
+}
+
+func (e exportItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	itemStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	pathStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+
+	label := e.format.Title()
+	destination := " → " + e.path
+	if e.editor {
+		label = "Open in $EDITOR"
+		destination = " (markdown)"
+	}
+	label = truncate.StringWithTail(label, uint(width), "...")
+	destination = truncate.StringWithTail(destination, uint(max(0, width-len(label)-2)), "...")
+
+	return itemStyle.PaddingLeft(1).Render(label) + pathStyle.Render(destination)
+}
+
+func (e exportItem) Selectable() bool {
+	return true
+}
+
+type exportDialog struct {
+	app          *app.App
+	width        int
+	height       int
+	modal        *modal.Modal
+	searchDialog *SearchDialog
+}
+
+func (e *exportDialog) Init() tea.Cmd {
+	return e.searchDialog.Init()
+}
+
+func (e *exportDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.width = msg.Width
+		e.height = msg.Height
+	case SearchQueryChangedMsg:
+		e.searchDialog.SetItems(e.items(msg.Query))
+		return e, nil
+	case SearchSelectionMsg:
+		if item, ok := msg.Item.(exportItem); ok {
+			path := strings.TrimSpace(e.searchDialog.GetQuery())
+			if path != "" && filepath.Ext(path) == "" {
+				path += "." + item.format.Extension()
+			}
+			return e, tea.Sequence(
+				e.Close(),
+				util.CmdHandler(ExportSelectedMsg{
+					Format: item.format,
+					Path:   path,
+					Editor: item.editor,
+				}),
+			)
+		}
+		return e, nil
+	case SearchCancelledMsg:
+		return e, e.Close()
+	}
+
+	updated, cmd := e.searchDialog.Update(msg)
+	e.searchDialog = updated.(*SearchDialog)
+	return e, cmd
+}
+
+// items lists a destination per format, a format matching the extension of
+// the typed path is listed first
+func (e *exportDialog) items(query string) []list.Item {
+	query = strings.TrimSpace(query)
+	formats := export.Formats
+	if query != "" {
+		if format, err := export.ParseFormat(filepath.Ext(query)); err == nil {
+			formats = []export.Format{format}
+			for _, f := range export.Formats {
+				if f != format {
+					formats = append(formats, f)
+				}
+			}
+		}
+	}
+
+	items := []list.Item{}
+	for _, format := range formats {
+		path := query
+		if path == "" {
+			path = export.DefaultPath(e.app.Session, format)
+		} else if filepath.Ext(path) == "" {
+			path += "." + format.Extension()
+		}
+		items = append(items, exportItem{format: format, path: path})
+	}
+	if os.Getenv("EDITOR") != "" {
+		items = append(items, exportItem{format: export.FormatMarkdown, editor: true})
+	}
+	return items
+}
+
+func (e *exportDialog) Render(background string) string {
+	return e.modal.Render(e.searchDialog.View(), background)
+}
+
+func (e *exportDialog) Close() tea.Cmd {
+	e.searchDialog.SetQuery("")
+	e.searchDialog.Blur()
+	return util.CmdHandler(modal.CloseModalMsg{})
+}
+
+// NewExportDialog creates a new dialog to pick the format and destination of
+// a session export
+func NewExportDialog(app *app.App) ExportDialog {
+	width := min(exportDialogWidth, layout.Current.Container.Width-12)
+	dialog := &exportDialog{
+		app:          app,
+		searchDialog: NewSearchDialog("Destination path (optional)", len(export.Formats)+1),
+		modal: modal.New(
+			modal.WithTitle("Export Session"),
+			modal.WithMaxWidth(width+4),
+		),
+	}
+	dialog.searchDialog.SetWidth(width)
+	dialog.searchDialog.SetItems(dialog.items(""))
+	return dialog
+}
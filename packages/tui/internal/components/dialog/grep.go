@@ -0,0 +1,302 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	numVisibleGrepResults = 14
+	maxGrepMatches        = 500
+	grepDebounce          = 150 * time.Millisecond
+)
+
+// GrepSelectedMsg is sent when a match is picked to be opened in the file
+// viewer, the line is zero based
+type GrepSelectedMsg struct {
+	FilePath string
+	Line     int
+}
+
+// GrepAttachMsg is sent when a match is picked to be inserted into the editor
+// as an @file attachment of the matched line, the line is zero based
+type GrepAttachMsg struct {
+	FilePath string
+	Line     int
+}
+
+// GrepDialog interface for the project search dialog
+type GrepDialog interface {
+	layout.Modal
+}
+
+// grepSearchMsg is sent once the query stopped changing for a moment
+type grepSearchMsg struct {
+	query string
+}
+
+// grepResultsMsg carries the matches found for a query
+type grepResultsMsg struct {
+	query   string
+	matches []opencode.FindTextResponse
+	err     error
+}
+
+// grepMatchItem is a list item for a matched line, showing the line number
+// and a preview with the matched text highlighted
+type grepMatchItem struct {
+	path  string
+	match opencode.FindTextResponse
+}
+
+func (g grepMatchItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	lineStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+	textStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	matchStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Accent()).
+		Bold(true)
+	if selected {
+		lineStyle = lineStyle.Foreground(t.Primary())
+		textStyle = textStyle.Foreground(t.Primary())
+	}
+
+	lineNumber := lineStyle.Render(fmt.Sprintf(" %5d  ", int(g.match.LineNumber)))
+	preview := g.preview(textStyle, matchStyle)
+	preview = ansi.Truncate(preview, max(0, width-ansi.StringWidth(lineNumber)-1), "…")
+	rendered := lineNumber + preview
+	gap := max(0, width-ansi.StringWidth(rendered))
+	return rendered + textStyle.Render(strings.Repeat(" ", gap))
+}
+
+// preview renders the matched line without its indentation, highlighting
+// the submatches
+func (g grepMatchItem) preview(textStyle styles.Style, matchStyle styles.Style) string {
+	text := strings.TrimRight(g.match.Lines.Text, "\r\n")
+	indent := len(text) - len(strings.TrimLeft(text, " \t"))
+	clean := func(s string) string {
+		return strings.ReplaceAll(s, "\t", "    ")
+	}
+
+	var b strings.Builder
+	offset := indent
+	for _, submatch := range g.match.Submatches {
+		start := max(int(submatch.Start), offset)
+		end := min(int(submatch.End), len(text))
+		if start >= end {
+			continue
+		}
+		b.WriteString(textStyle.Render(clean(text[offset:start])))
+		b.WriteString(matchStyle.Render(clean(text[start:end])))
+		offset = end
+	}
+	if offset < len(text) {
+		b.WriteString(textStyle.Render(clean(text[offset:])))
+	}
+	return b.String()
+}
+
+func (g grepMatchItem) Selectable() bool {
+	return true
+}
+
+type grepDialog struct {
+	app          *app.App
+	width        int
+	height       int
+	dialogWidth  int
+	modal        *modal.Modal
+	searchDialog *SearchDialog
+	query        string
+	searching    bool
+	matches      []opencode.FindTextResponse
+	err          error
+}
+
+func (g *grepDialog) Init() tea.Cmd {
+	return g.searchDialog.Init()
+}
+
+func (g *grepDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		g.width = msg.Width
+		g.height = msg.Height
+	case SearchQueryChangedMsg:
+		g.query = strings.TrimSpace(msg.Query)
+		if g.query == "" {
+			g.searching = false
+			g.matches = nil
+			g.err = nil
+			g.updateListItems()
+			return g, nil
+		}
+		g.searching = true
+		query := g.query
+		return g, tea.Tick(grepDebounce, func(time.Time) tea.Msg {
+			return grepSearchMsg{query: query}
+		})
+	case grepSearchMsg:
+		// the query changed again while waiting, a newer search is pending
+		if msg.query != g.query {
+			return g, nil
+		}
+		return g, g.search(msg.query)
+	case grepResultsMsg:
+		// results of an outdated query are dropped
+		if msg.query != g.query {
+			return g, nil
+		}
+		g.searching = false
+		g.matches = msg.matches
+		g.err = msg.err
+		g.updateListItems()
+		return g, nil
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "tab":
+			if item, ok := g.selectedMatch(); ok {
+				return g, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					util.CmdHandler(GrepAttachMsg{
+						FilePath: item.path,
+						Line:     int(item.match.LineNumber) - 1,
+					}),
+				)
+			}
+			return g, nil
+		}
+	case SearchSelectionMsg:
+		if item, ok := msg.Item.(grepMatchItem); ok {
+			return g, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(GrepSelectedMsg{
+					FilePath: item.path,
+					Line:     int(item.match.LineNumber) - 1,
+				}),
+			)
+		}
+		return g, nil
+	case SearchCancelledMsg:
+		return g, util.CmdHandler(modal.CloseModalMsg{})
+	}
+
+	updated, cmd := g.searchDialog.Update(msg)
+	g.searchDialog = updated.(*SearchDialog)
+	return g, cmd
+}
+
+func (g *grepDialog) search(query string) tea.Cmd {
+	return func() tea.Msg {
+		matches, err := g.app.FindText(context.Background(), query)
+		return grepResultsMsg{query: query, matches: matches, err: err}
+	}
+}
+
+// updateListItems lists the matches grouped under a header for each file
+func (g *grepDialog) updateListItems() {
+	items := []list.Item{}
+	for _, file := range app.GroupMatchesByFile(g.matches, maxGrepMatches) {
+		items = append(items, list.HeaderItem(file.Path))
+		for _, match := range file.Matches {
+			items = append(items, grepMatchItem{path: file.Path, match: match})
+		}
+	}
+	g.searchDialog.SetItems(items)
+}
+
+func (g *grepDialog) selectedMatch() (grepMatchItem, bool) {
+	item, idx := g.searchDialog.list.GetSelectedItem()
+	if idx < 0 {
+		return grepMatchItem{}, false
+	}
+	selected, ok := item.(grepMatchItem)
+	return selected, ok
+}
+
+// status summarizes the results of the current query
+func (g *grepDialog) status() string {
+	switch {
+	case g.query == "":
+		return ""
+	case g.searching:
+		return "searching..."
+	case g.err != nil:
+		return "search failed"
+	}
+	files := len(app.GroupMatchesByFile(g.matches, 0))
+	status := fmt.Sprintf("%d matches in %d files", len(g.matches), files)
+	if len(g.matches) > maxGrepMatches {
+		status += fmt.Sprintf(", showing %d", maxGrepMatches)
+	}
+	return status
+}
+
+func (g *grepDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+	if g.err != nil {
+		mutedStyle = styles.NewStyle().Foreground(t.Error()).Background(t.BackgroundPanel()).Render
+	}
+
+	bgColor := t.BackgroundPanel()
+	helpText := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifySpaceBetween,
+		Width:      g.dialogWidth - 2,
+		Background: &bgColor,
+	},
+		layout.FlexItem{View: keyStyle("enter") + mutedStyle(" open")},
+		layout.FlexItem{View: keyStyle("tab") + mutedStyle(" attach")},
+		layout.FlexItem{View: mutedStyle(g.status())},
+	)
+	helpText = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpText)
+
+	return g.modal.Render(g.searchDialog.View()+"\n"+helpText, background)
+}
+
+func (g *grepDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewGrepDialog creates a dialog searching the contents of the project files
+func NewGrepDialog(app *app.App) GrepDialog {
+	width := layout.Current.Container.Width - 12
+
+	dialog := &grepDialog{
+		app:          app,
+		dialogWidth:  width,
+		searchDialog: NewSearchDialog("Search project...", numVisibleGrepResults),
+		modal: modal.New(
+			modal.WithTitle("Search Project"),
+			modal.WithMaxWidth(width+4),
+		),
+	}
+	dialog.searchDialog.SetWidth(width)
+	dialog.searchDialog.list.SetEmptyMessage(" No matches")
+	return dialog
+}
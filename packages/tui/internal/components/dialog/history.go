@@ -0,0 +1,241 @@
+package dialog
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const numVisiblePrompts = 10
+
+// HistoryDialog interface for the prompt history search dialog
+type HistoryDialog interface {
+	layout.Modal
+}
+
+// HistorySelectedMsg is sent when a prompt was picked from the history, to
+// put it back in the editor
+type HistorySelectedMsg struct {
+	Prompt app.Prompt
+}
+
+// historyItem is a list item for a prompt of the history, index is its
+// position in the history of the project
+type historyItem struct {
+	entry app.HistoryEntry
+	index int
+}
+
+func (h historyItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	itemStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	pinStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Warning())
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+
+	pin := "  "
+	if h.entry.Pinned {
+		pin = "★ "
+	}
+	text := strings.Join(strings.Fields(h.entry.Text), " ")
+	if text == "" {
+		text = "(attachments only)"
+	}
+	text = truncate.StringWithTail(text, uint(max(0, width-4)), "...")
+
+	return pinStyle.PaddingLeft(1).Render(pin) + itemStyle.Render(text)
+}
+
+func (h historyItem) Selectable() bool {
+	return true
+}
+
+type historyDialog struct {
+	app          *app.App
+	width        int
+	height       int
+	dialogWidth  int
+	modal        *modal.Modal
+	searchDialog *SearchDialog
+}
+
+func (h *historyDialog) Init() tea.Cmd {
+	return h.searchDialog.Init()
+}
+
+func (h *historyDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h.width = msg.Width
+		h.height = msg.Height
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "ctrl+r":
+			// like the reverse search of shells, pressing it again moves to
+			// the next match
+			items := h.searchDialog.list.GetItems()
+			if _, idx := h.searchDialog.list.GetSelectedItem(); idx >= 0 && len(items) > 0 {
+				h.searchDialog.list.SetSelectedIndex((idx + 1) % len(items))
+			}
+			return h, nil
+		case "ctrl+s":
+			if item, ok := h.selectedPrompt(); ok {
+				h.app.State.PinPrompt(h.app.Info.Path.Root, item.index, !item.entry.Pinned)
+				h.updateListItems()
+				return h, h.app.SaveState()
+			}
+			return h, nil
+		}
+	case SearchQueryChangedMsg:
+		h.updateListItems()
+		h.searchDialog.list.SetSelectedIndex(0)
+		return h, nil
+	case SearchSelectionMsg:
+		if item, ok := msg.Item.(historyItem); ok {
+			return h, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(HistorySelectedMsg{Prompt: item.entry.Prompt}),
+			)
+		}
+		return h, nil
+	case SearchRemoveItemMsg:
+		if item, ok := msg.Item.(historyItem); ok {
+			h.app.State.RemovePromptFromHistory(h.app.Info.Path.Root, item.index)
+			h.updateListItems()
+			return h, h.app.SaveState()
+		}
+		return h, nil
+	case SearchCancelledMsg:
+		return h, util.CmdHandler(modal.CloseModalMsg{})
+	}
+
+	updated, cmd := h.searchDialog.Update(msg)
+	h.searchDialog = updated.(*SearchDialog)
+	return h, cmd
+}
+
+func (h *historyDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
+
+	pin := " pin"
+	if item, ok := h.selectedPrompt(); ok && item.entry.Pinned {
+		pin = " unpin"
+	}
+	help := []string{
+		keyStyle("ctrl+r") + mutedStyle(" next match"),
+		keyStyle("ctrl+s") + mutedStyle(pin),
+		keyStyle("ctrl+x") + mutedStyle(" delete"),
+		keyStyle("enter") + mutedStyle(" restore"),
+	}
+
+	bgColor := t.BackgroundPanel()
+	items := []layout.FlexItem{}
+	for _, view := range help {
+		items = append(items, layout.FlexItem{View: view})
+	}
+	helpText := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifySpaceBetween,
+		Width:      h.dialogWidth - 2,
+		Background: &bgColor,
+	}, items...)
+	helpText = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpText)
+
+	return h.modal.Render(h.searchDialog.View()+"\n"+helpText, background)
+}
+
+func (h *historyDialog) selectedPrompt() (historyItem, bool) {
+	item, idx := h.searchDialog.list.GetSelectedItem()
+	if idx < 0 {
+		return historyItem{}, false
+	}
+	selected, ok := item.(historyItem)
+	return selected, ok
+}
+
+// updateListItems filters the history by the search query. Without a query
+// pinned prompts come first, otherwise the prompts are ranked by how well they
+// match, the latest first among equal matches.
+func (h *historyDialog) updateListItems() {
+	history := h.app.History()
+	items := make([]historyItem, 0, len(history))
+	for i, entry := range history {
+		items = append(items, historyItem{entry: entry, index: i})
+	}
+
+	query := strings.TrimSpace(h.searchDialog.GetQuery())
+	if query == "" {
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].entry.Pinned && !items[j].entry.Pinned
+		})
+	} else {
+		targets := make([]string, len(items))
+		for i, item := range items {
+			targets[i] = item.entry.Text
+		}
+		matches := fuzzy.RankFindFold(query, targets)
+		sort.Stable(matches)
+		matched := make([]historyItem, 0, len(matches))
+		for _, match := range matches {
+			matched = append(matched, items[match.OriginalIndex])
+		}
+		items = matched
+	}
+
+	listItems := make([]list.Item, 0, len(items))
+	for _, item := range items {
+		listItems = append(listItems, item)
+	}
+
+	_, currentIdx := h.searchDialog.list.GetSelectedItem()
+	h.searchDialog.SetItems(listItems)
+	if currentIdx >= 0 && currentIdx < len(listItems) {
+		h.searchDialog.list.SetSelectedIndex(currentIdx)
+	}
+}
+
+func (h *historyDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewHistoryDialog creates a dialog to search the prompt history of the
+// project, starting from a query
+func NewHistoryDialog(app *app.App, query string) HistoryDialog {
+	width := layout.Current.Container.Width - 12
+
+	dialog := &historyDialog{
+		app:          app,
+		dialogWidth:  width,
+		searchDialog: NewSearchDialog("Search history...", numVisiblePrompts),
+		modal: modal.New(
+			modal.WithTitle("Prompt History"),
+			modal.WithMaxWidth(width+4),
+		),
+	}
+	dialog.searchDialog.SetWidth(width)
+	dialog.searchDialog.SetQuery(query)
+	dialog.updateListItems()
+	return dialog
+}
@@ -0,0 +1,389 @@
+package dialog
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/commands"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const (
+	numVisibleKeybinds      = 12
+	keybindDescriptionWidth = 24
+	keybindSourceWidth      = 7
+)
+
+// KeybindsDialog interface for the dialog listing and editing the keybindings
+// of the commands
+type KeybindsDialog interface {
+	layout.Modal
+	layout.KeyCapturer
+}
+
+// keybindItem is a list item for a command with the keys bound to it
+type keybindItem struct {
+	command    commands.Command
+	keys       string
+	conflicted bool
+}
+
+func (k keybindItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	base := baseStyle.Background(t.BackgroundPanel())
+	textStyle := base.Foreground(t.Text())
+	keyStyle := base.Foreground(t.Text())
+	mutedStyle := base.Foreground(t.TextMuted())
+	if selected {
+		textStyle = textStyle.Foreground(t.Primary())
+		keyStyle = keyStyle.Foreground(t.Primary())
+	}
+
+	marker := "  "
+	if k.conflicted {
+		marker = "! "
+	}
+	description := truncate.StringWithTail(k.command.Description, keybindDescriptionWidth-1, "…")
+	keysWidth := max(0, width-keybindDescriptionWidth-keybindSourceWidth-4)
+	keys := keyStyle.Render(truncate.StringWithTail(k.keys, uint(keysWidth), "…"))
+	if k.keys == "" {
+		keys = mutedStyle.Render("none")
+	}
+	source := mutedStyle.Render("default")
+	if k.command.Custom != nil {
+		source = mutedStyle.Render("custom")
+	} else if k.command.IsConfigured() {
+		source = base.Foreground(t.Accent()).Render("config")
+	}
+
+	return base.Foreground(t.Warning()).PaddingLeft(1).Render(marker) +
+		textStyle.Width(keybindDescriptionWidth).Render(description) +
+		base.Width(keysWidth).Render(keys) +
+		base.Width(keybindSourceWidth).AlignHorizontal(lipgloss.Right).Render(source)
+}
+
+func (k keybindItem) Selectable() bool {
+	return true
+}
+
+type keybindsDialog struct {
+	app          *app.App
+	width        int
+	height       int
+	dialogWidth  int
+	modal        *modal.Modal
+	searchDialog *SearchDialog
+	// recording is the command waiting for a key combination, leader whether
+	// the leader key was pressed first, and rejected why the last key pressed
+	// can't be bound
+	recording *commands.Command
+	leader    bool
+	rejected  string
+}
+
+func (k *keybindsDialog) Init() tea.Cmd {
+	return k.searchDialog.Init()
+}
+
+// CapturesKeys returns whether a key combination is being recorded, esc then
+// cancels the recording instead of closing the dialog
+func (k *keybindsDialog) CapturesKeys() bool {
+	return k.recording != nil
+}
+
+func (k *keybindsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		k.width = msg.Width
+		k.height = msg.Height
+	case tea.KeyPressMsg:
+		if k.recording != nil {
+			return k, k.record(msg)
+		}
+		if msg.String() == "ctrl+r" {
+			if item, ok := k.selectedCommand(); ok {
+				return k, k.reset(item.command)
+			}
+			return k, nil
+		}
+	case SearchQueryChangedMsg:
+		k.updateListItems()
+		k.searchDialog.list.SetSelectedIndex(0)
+		return k, nil
+	case SearchSelectionMsg:
+		if item, ok := msg.Item.(keybindItem); ok {
+			if item.command.Custom != nil {
+				return k, customKeybindToast(item.command)
+			}
+			k.recording = &item.command
+			k.leader = false
+			k.searchDialog.Blur()
+		}
+		return k, nil
+	case SearchRemoveItemMsg:
+		if item, ok := msg.Item.(keybindItem); ok {
+			return k, k.save(item.command, nil)
+		}
+		return k, nil
+	case SearchCancelledMsg:
+		return k, util.CmdHandler(modal.CloseModalMsg{})
+	}
+
+	updated, cmd := k.searchDialog.Update(msg)
+	k.searchDialog = updated.(*SearchDialog)
+	return k, cmd
+}
+
+// record binds the command being recorded to a key press, the leader key
+// waits for the key following it
+func (k *keybindsDialog) record(msg tea.KeyPressMsg) tea.Cmd {
+	keyString := msg.String()
+	if keyString == "esc" {
+		k.stopRecording()
+		return nil
+	}
+	leader := k.app.Config.Keybinds.Leader
+	if !k.leader && leader != "" && keyString == leader {
+		k.leader = true
+		k.rejected = ""
+		return nil
+	}
+	// keys typing text go to the input before the keybindings are matched
+	if !k.leader && msg.Text != "" {
+		k.rejected = keyString + " types text, press a key with a modifier"
+		if leader != "" {
+			k.rejected = keyString + " types text, press " + leader + " first or a key with a modifier"
+		}
+		return nil
+	}
+
+	command := *k.recording
+	binding := commands.Keybinding{RequiresLeader: k.leader, Key: keyString}
+	k.stopRecording()
+	return k.save(command, []commands.Keybinding{binding})
+}
+
+func (k *keybindsDialog) stopRecording() {
+	k.recording = nil
+	k.leader = false
+	k.rejected = ""
+	k.searchDialog.Focus()
+}
+
+// save binds the command to the keys, or unbinds it without keys, and warns
+// about the commands already bound to the same keys
+func (k *keybindsDialog) save(command commands.Command, bindings []commands.Keybinding) tea.Cmd {
+	if command.Custom != nil {
+		return customKeybindToast(command)
+	}
+	if err := k.app.SaveKeybind(command.Name, bindings); err != nil {
+		slog.Error("Failed to save keybinding", "error", err)
+		return toast.NewErrorToast("Failed to save keybinding")
+	}
+	k.updateListItems()
+
+	if conflicts := k.app.Commands.ConflictsOf(command.Name); len(conflicts) > 0 {
+		return toast.NewWarningToast(fmt.Sprintf(
+			"%s is also bound to %s",
+			k.formatKeys(bindings),
+			k.describe(conflicts),
+		))
+	}
+	if len(bindings) == 0 {
+		return toast.NewSuccessToast(fmt.Sprintf("Unbound %s", command.Description))
+	}
+	return toast.NewSuccessToast(fmt.Sprintf("Bound %s to %s", command.Description, k.formatKeys(bindings)))
+}
+
+// reset binds the command to its default keys again
+func (k *keybindsDialog) reset(command commands.Command) tea.Cmd {
+	if command.Custom != nil {
+		return customKeybindToast(command)
+	}
+	if !command.IsConfigured() {
+		return nil
+	}
+	if err := k.app.ResetKeybind(command.Name); err != nil {
+		slog.Error("Failed to reset keybinding", "error", err)
+		return toast.NewErrorToast("Failed to reset keybinding")
+	}
+	k.updateListItems()
+	return toast.NewSuccessToast(fmt.Sprintf("Reset %s to its default keys", command.Description))
+}
+
+// customKeybindToast tells the keys of a custom command are set where it's
+// defined
+func customKeybindToast(command commands.Command) tea.Cmd {
+	return toast.NewInfoToast(fmt.Sprintf("The keys of %s are set in its command file or the config", command.Description))
+}
+
+// formatKeys formats keybindings the way they're pressed, with the leader key
+func (k *keybindsDialog) formatKeys(bindings []commands.Keybinding) string {
+	keys := make([]string, 0, len(bindings))
+	for _, binding := range bindings {
+		if binding.RequiresLeader {
+			keys = append(keys, k.app.Config.Keybinds.Leader+" "+binding.Key)
+		} else {
+			keys = append(keys, binding.Key)
+		}
+	}
+	return strings.Join(keys, ", ")
+}
+
+// describe lists the descriptions of commands
+func (k *keybindsDialog) describe(names []commands.CommandName) string {
+	descriptions := make([]string, 0, len(names))
+	for _, name := range names {
+		descriptions = append(descriptions, k.app.Commands[name].Description)
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+func (k *keybindsDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Background(t.BackgroundPanel())
+	keyStyle := base.Foreground(t.Text()).Render
+	mutedStyle := base.Foreground(t.TextMuted()).Render
+	warningStyle := base.Foreground(t.Warning()).Render
+	errorStyle := base.Foreground(t.Error()).Render
+
+	var status string
+	if k.recording != nil {
+		prompt := "Press the keys for " + k.recording.Description
+		if k.leader {
+			prompt = "Press the key following " + k.app.Config.Keybinds.Leader + " for " + k.recording.Description
+		} else if k.app.Config.Keybinds.Leader != "" {
+			prompt += ", " + k.app.Config.Keybinds.Leader + " first for a leader key"
+		}
+		status = keyStyle(prompt) + mutedStyle(", esc to cancel")
+		if k.rejected != "" {
+			status = errorStyle(k.rejected) + mutedStyle(", esc to cancel")
+		}
+	} else if item, ok := k.selectedCommand(); ok {
+		if conflicts := k.app.Commands.ConflictsOf(item.command.Name); len(conflicts) > 0 {
+			status = warningStyle("! also bound to " + k.describe(conflicts))
+		}
+	}
+	status = truncate.StringWithTail(status, uint(max(0, k.dialogWidth-2)), "…")
+
+	help := []string{
+		keyStyle("enter") + mutedStyle(" record keys"),
+		keyStyle("ctrl+x") + mutedStyle(" unbind"),
+		keyStyle("ctrl+r") + mutedStyle(" reset to default"),
+	}
+	bgColor := t.BackgroundPanel()
+	items := []layout.FlexItem{}
+	for _, view := range help {
+		items = append(items, layout.FlexItem{View: view})
+	}
+	helpText := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifySpaceBetween,
+		Width:      k.dialogWidth - 2,
+		Background: &bgColor,
+	}, items...)
+
+	footer := styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(
+		base.Width(k.dialogWidth-2).Render(status) + "\n" + helpText,
+	)
+	return k.modal.Render(k.searchDialog.View()+"\n"+footer, background)
+}
+
+func (k *keybindsDialog) selectedCommand() (keybindItem, bool) {
+	item, idx := k.searchDialog.list.GetSelectedItem()
+	if idx < 0 {
+		return keybindItem{}, false
+	}
+	selected, ok := item.(keybindItem)
+	return selected, ok
+}
+
+// updateListItems lists the commands matching the search query, by their
+// description, name or keys
+func (k *keybindsDialog) updateListItems() {
+	conflicted := map[commands.CommandName]bool{}
+	for _, conflict := range k.app.Commands.Conflicts() {
+		for _, name := range conflict.Commands {
+			conflicted[name] = true
+		}
+	}
+
+	var items []keybindItem
+	for _, command := range k.app.Commands.Sorted() {
+		items = append(items, keybindItem{
+			command:    command,
+			keys:       k.formatKeys(command.Keybindings),
+			conflicted: conflicted[command.Name],
+		})
+	}
+
+	query := strings.TrimSpace(k.searchDialog.GetQuery())
+	if query != "" {
+		targets := make([]string, len(items))
+		for i, item := range items {
+			targets[i] = item.command.Description + " " + string(item.command.Name) + " " + item.keys
+		}
+		matches := fuzzy.RankFindFold(query, targets)
+		sort.Stable(matches)
+		matched := make([]keybindItem, 0, len(matches))
+		for _, match := range matches {
+			matched = append(matched, items[match.OriginalIndex])
+		}
+		items = matched
+	}
+
+	listItems := make([]list.Item, 0, len(items))
+	for _, item := range items {
+		listItems = append(listItems, item)
+	}
+
+	selected, _ := k.selectedCommand()
+	k.searchDialog.SetItems(listItems)
+	if idx := slices.IndexFunc(items, func(item keybindItem) bool {
+		return item.command.Name == selected.command.Name
+	}); idx >= 0 {
+		k.searchDialog.list.SetSelectedIndex(idx)
+	}
+}
+
+func (k *keybindsDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewKeybindsDialog creates a dialog listing the keys bound to every command,
+// where they can be changed and saved to the global config
+func NewKeybindsDialog(app *app.App) KeybindsDialog {
+	width := min(80, layout.Current.Container.Width-12)
+
+	dialog := &keybindsDialog{
+		app:          app,
+		dialogWidth:  width,
+		searchDialog: NewSearchDialog("Search keybindings...", numVisibleKeybinds),
+		modal: modal.New(
+			modal.WithTitle("Keybindings"),
+			modal.WithMaxWidth(width+4),
+		),
+	}
+	dialog.searchDialog.SetWidth(width)
+	dialog.updateListItems()
+	return dialog
+}
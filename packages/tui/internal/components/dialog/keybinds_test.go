@@ -0,0 +1,43 @@
+package dialog
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/commands"
+	"github.com/sst/opencode/internal/theme"
+)
+
+func TestKeybindsDialogRejectsTextKeys(t *testing.T) {
+	// the search input is styled with the current theme
+	theme.LoadThemesFromJSON()
+	theme.SetTheme("opencode")
+
+	config := &opencode.Config{Keybinds: opencode.KeybindsConfig{Leader: "ctrl+x"}}
+	k := &keybindsDialog{
+		app: &app.App{
+			Info:     opencode.App{Path: opencode.AppPath{Config: t.TempDir()}},
+			Config:   config,
+			Commands: commands.LoadFromConfig(config),
+		},
+		searchDialog: NewSearchDialog("Search keybindings...", numVisibleKeybinds),
+	}
+	command := k.app.Commands[commands.SessionNewCommand]
+	k.recording = &command
+
+	k.record(tea.KeyPressMsg{Code: 'g', Text: "g"})
+	if k.recording == nil || k.rejected == "" {
+		t.Fatalf("expected a key typing text to be rejected")
+	}
+
+	k.record(tea.KeyPressMsg{Code: 'x', Mod: tea.ModCtrl})
+	k.record(tea.KeyPressMsg{Code: 'g', Text: "g"})
+	if k.recording != nil {
+		t.Fatalf("expected the key following the leader to be bound")
+	}
+	if got := commands.FormatBindings(k.app.Commands[commands.SessionNewCommand].Keybindings); got != "<leader>g" {
+		t.Errorf("expected <leader>g, got %s", got)
+	}
+}
@@ -0,0 +1,286 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+const maxPermissionMetadataLines = 8
+
+type Permission = opencode.EventListResponseEventPermissionUpdatedProperties
+
+// PermissionRespondedMsg is sent once the answer to a pending permission
+// reached the server, or failed to with Err
+type PermissionRespondedMsg struct {
+	Permission Permission
+	Response   opencode.SessionRespondPermissionParamsResponse
+	Err        error
+}
+
+// PermissionDialog interface for the permission approval dialog
+type PermissionDialog interface {
+	layout.Modal
+	Enqueue(permission Permission)
+	Remove(sessionID string)
+	Len() int
+}
+
+type permissionOption struct {
+	label    string
+	key      string
+	response opencode.SessionRespondPermissionParamsResponse
+}
+
+var permissionOptions = []permissionOption{
+	{label: "Allow once", key: "enter", response: opencode.SessionRespondPermissionParamsResponseOnce},
+	{label: "Allow always", key: "a", response: opencode.SessionRespondPermissionParamsResponseAlways},
+	{label: "Reject", key: "esc", response: opencode.SessionRespondPermissionParamsResponseReject},
+}
+
+type permissionDialog struct {
+	width    int
+	height   int
+	app      *app.App
+	modal    *modal.Modal
+	queue    []Permission
+	selected int
+	// responding is set while the answer to the head of the queue is sent
+	responding bool
+}
+
+func (p *permissionDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (p *permissionDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+	case PermissionRespondedMsg:
+		p.responding = false
+		if msg.Err != nil {
+			// the request stays at the head of the queue to be answered again
+			return p, toast.NewErrorToast("Failed to respond to permission: " + msg.Err.Error())
+		}
+		p.dequeue(msg.Permission)
+	case tea.KeyPressMsg:
+		if len(p.queue) == 0 || p.responding {
+			return p, nil
+		}
+		switch msg.String() {
+		case "tab", "right", "l":
+			p.selected = (p.selected + 1) % len(permissionOptions)
+		case "shift+tab", "left", "h":
+			p.selected = (p.selected - 1 + len(permissionOptions)) % len(permissionOptions)
+		case "enter":
+			return p, p.respond(permissionOptions[p.selected].response)
+		case "o", "y":
+			return p, p.respond(opencode.SessionRespondPermissionParamsResponseOnce)
+		case "a":
+			return p, p.respond(opencode.SessionRespondPermissionParamsResponseAlways)
+		case "esc", "r", "n":
+			return p, p.respond(opencode.SessionRespondPermissionParamsResponseReject)
+		}
+	}
+	return p, nil
+}
+
+// respond answers the permission at the head of the queue, it's dequeued once
+// the server received the answer
+func (p *permissionDialog) respond(
+	response opencode.SessionRespondPermissionParamsResponse,
+) tea.Cmd {
+	permission := p.queue[0]
+	p.responding = true
+	return func() tea.Msg {
+		err := p.app.RespondPermission(
+			context.Background(),
+			permission.SessionID,
+			permission.ID,
+			response,
+		)
+		return PermissionRespondedMsg{Permission: permission, Response: response, Err: err}
+	}
+}
+
+// dequeue drops an answered permission and advances to the next pending
+// request
+func (p *permissionDialog) dequeue(permission Permission) {
+	index := slices.IndexFunc(p.queue, func(existing Permission) bool {
+		return existing.ID == permission.ID && existing.SessionID == permission.SessionID
+	})
+	if index == -1 {
+		return
+	}
+	p.queue = slices.Delete(p.queue, index, index+1)
+	if index == 0 {
+		p.selected = 0
+	}
+}
+
+// Enqueue adds a permission request to the queue, replacing any pending
+// request with the same ID
+func (p *permissionDialog) Enqueue(permission Permission) {
+	index := slices.IndexFunc(p.queue, func(existing Permission) bool {
+		return existing.ID == permission.ID && existing.SessionID == permission.SessionID
+	})
+	if index > -1 {
+		p.queue[index] = permission
+		return
+	}
+	p.queue = append(p.queue, permission)
+}
+
+// Remove drops every pending request that belongs to the given session
+func (p *permissionDialog) Remove(sessionID string) {
+	head := len(p.queue) > 0 && p.queue[0].SessionID == sessionID
+	p.queue = slices.DeleteFunc(p.queue, func(permission Permission) bool {
+		return permission.SessionID == sessionID
+	})
+	if head {
+		p.selected = 0
+		p.responding = false
+	}
+}
+
+func (p *permissionDialog) Len() int {
+	return len(p.queue)
+}
+
+func (p *permissionDialog) Render(background string) string {
+	if len(p.queue) == 0 {
+		return background
+	}
+
+	t := theme.CurrentTheme()
+	permission := p.queue[0]
+	width := layout.Current.Container.Width - 12
+
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel())
+	accent := base.Foreground(t.Warning()).Bold(true)
+
+	title := "Permission required"
+	if len(p.queue) > 1 {
+		title = fmt.Sprintf("Permission required (1 of %d)", len(p.queue))
+	}
+	p.modal.SetTitle(title)
+
+	lines := []string{
+		accent.Width(width).Render(truncate.StringWithTail(permission.Title, uint(width), "...")),
+		"",
+	}
+
+	details := [][2]string{}
+	if permission.Type != "" {
+		details = append(details, [2]string{"tool", permission.Type})
+	}
+	if p.app.Session != nil && permission.SessionID != p.app.Session.ID {
+		details = append(details, [2]string{"session", permission.SessionID})
+	}
+	for _, detail := range details {
+		lines = append(lines, p.renderDetail(detail[0], detail[1], width))
+	}
+
+	metadata := p.renderMetadata(permission.Metadata, width)
+	if metadata != "" {
+		if len(details) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, muted.Width(width).Render(metadata))
+	}
+
+	buttons := []string{}
+	for i, option := range permissionOptions {
+		style := base.Padding(0, 2)
+		if i == p.selected {
+			style = style.Background(t.Primary()).Foreground(t.BackgroundPanel()).Bold(true)
+		}
+		buttons = append(buttons, style.Render(option.label))
+	}
+	lines = append(lines, "", strings.Join(buttons, base.Render(" ")))
+
+	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
+	mutedStyle := muted.Render
+	help := []string{}
+	for _, option := range permissionOptions {
+		help = append(help, keyStyle(option.key)+mutedStyle(" "+strings.ToLower(option.label)))
+	}
+	lines = append(lines, "", strings.Join(help, mutedStyle("  ")))
+
+	content := base.Width(width).Render(strings.Join(lines, "\n"))
+	return p.modal.Render(content, background)
+}
+
+func (p *permissionDialog) renderDetail(label, value string, width int) string {
+	t := theme.CurrentTheme()
+	labelStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel())
+	valueStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	label = fmt.Sprintf("%-8s", label)
+	value = truncate.StringWithTail(value, uint(max(0, width-lipgloss.Width(label))), "...")
+	return labelStyle.Render(label) + valueStyle.Render(value)
+}
+
+// renderMetadata formats the tool specific metadata attached to a permission
+// request, one key per line, capped to a handful of lines
+func (p *permissionDialog) renderMetadata(metadata map[string]any, width int) string {
+	if len(metadata) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	lines := []string{}
+	for _, key := range keys {
+		value := metadata[key]
+		if value == nil {
+			continue
+		}
+		text := strings.TrimSpace(fmt.Sprintf("%v", value))
+		for i, line := range strings.Split(text, "\n") {
+			prefix := key + ": "
+			if i > 0 {
+				prefix = strings.Repeat(" ", len(prefix))
+			}
+			lines = append(lines, truncate.StringWithTail(prefix+line, uint(width), "..."))
+		}
+	}
+	if len(lines) > maxPermissionMetadataLines {
+		hidden := len(lines) - maxPermissionMetadataLines
+		lines = append(lines[:maxPermissionMetadataLines], fmt.Sprintf("… %d more lines", hidden))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *permissionDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewPermissionDialog creates a new permission approval dialog
+func NewPermissionDialog(app *app.App) PermissionDialog {
+	return &permissionDialog{
+		app:   app,
+		queue: []Permission{},
+		modal: modal.New(
+			modal.WithTitle("Permission required"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
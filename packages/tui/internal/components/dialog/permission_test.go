@@ -0,0 +1,67 @@
+package dialog
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/theme"
+)
+
+func queueIDs(p *permissionDialog) []string {
+	ids := []string{}
+	for _, permission := range p.queue {
+		ids = append(ids, permission.ID)
+	}
+	return ids
+}
+
+func TestPermissionEnqueueKeepsOrder(t *testing.T) {
+	p := &permissionDialog{}
+	p.Enqueue(Permission{ID: "per_1", SessionID: "ses_a", Title: "first"})
+	p.Enqueue(Permission{ID: "per_2", SessionID: "ses_b"})
+	p.Enqueue(Permission{ID: "per_3", SessionID: "ses_a"})
+	p.Enqueue(Permission{ID: "per_1", SessionID: "ses_a", Title: "updated"})
+
+	if got := queueIDs(p); !slices.Equal(got, []string{"per_1", "per_2", "per_3"}) {
+		t.Fatalf("expected the requests in the order they arrived, got %v", got)
+	}
+	if p.queue[0].Title != "updated" {
+		t.Errorf("expected a request with the same ID to be replaced in place, got %q", p.queue[0].Title)
+	}
+
+	p.Remove("ses_a")
+	if got := queueIDs(p); !slices.Equal(got, []string{"per_2"}) {
+		t.Errorf("expected the requests of the session to be removed, got %v", got)
+	}
+}
+
+func TestPermissionDequeuedOnlyOnceAnswered(t *testing.T) {
+	// the failure is shown in a themed toast
+	theme.LoadThemesFromJSON()
+	theme.SetTheme("opencode")
+
+	p := &permissionDialog{}
+	first := Permission{ID: "per_1", SessionID: "ses_a"}
+	p.Enqueue(first)
+	p.Enqueue(Permission{ID: "per_2", SessionID: "ses_a"})
+	p.responding = true
+
+	p.Update(PermissionRespondedMsg{
+		Permission: first,
+		Response:   opencode.SessionRespondPermissionParamsResponseOnce,
+		Err:        errors.New("connection refused"),
+	})
+	if got := queueIDs(p); !slices.Equal(got, []string{"per_1", "per_2"}) || p.responding {
+		t.Fatalf("expected a failed answer to keep the request, got %v", got)
+	}
+
+	p.Update(PermissionRespondedMsg{
+		Permission: first,
+		Response:   opencode.SessionRespondPermissionParamsResponseOnce,
+	})
+	if got := queueIDs(p); !slices.Equal(got, []string{"per_2"}) {
+		t.Errorf("expected the answered request to be dequeued, got %v", got)
+	}
+}
@@ -2,11 +2,17 @@ package dialog
 
 import (
 	"context"
-	"strings"
-
+	"fmt"
+	"maps"
 	"slices"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/v2/textinput"
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lithammer/fuzzysearch/fuzzy"
 	"github.com/muesli/reflow/truncate"
 	"github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
@@ -19,14 +25,39 @@ import (
 	"github.com/sst/opencode/internal/util"
 )
 
-// SessionDialog interface for the session switching dialog
+const numVisibleSessions = 10
+
+const (
+	sessionSortUpdated = "updated"
+	sessionSortCreated = "created"
+)
+
+// SessionDialog interface for the session browser dialog
 type SessionDialog interface {
 	layout.Modal
 }
 
+// sessionSummariesMsg is sent once the metadata of the visible sessions has
+// been loaded in the background
+type sessionSummariesMsg map[string]app.SessionSummary
+
+// sessionRenamedMsg is sent when a session was renamed from the dialog
+type sessionRenamedMsg struct {
+	session opencode.Session
+}
+
+// sessionForkMessagesMsg carries the messages of the session being forked so
+// the message to fork from can be picked
+type sessionForkMessagesMsg struct {
+	session  opencode.Session
+	messages []app.Message
+}
+
 // sessionItem is a custom list item for sessions that can show delete confirmation
 type sessionItem struct {
-	title              string
+	session            opencode.Session
+	summary            *app.SessionSummary
+	timestamp          float64
 	isDeleteConfirming bool
 	isCurrentSession   bool
 }
@@ -34,191 +65,454 @@ type sessionItem struct {
 func (s sessionItem) Render(
 	selected bool,
 	width int,
-	isFirstInViewport bool,
 	baseStyle styles.Style,
 ) string {
 	t := theme.CurrentTheme()
 
-	var text string
+	itemStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	metaStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+	if s.isCurrentSession {
+		itemStyle = itemStyle.Bold(true)
+	}
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+
 	if s.isDeleteConfirming {
-		text = "Press again to confirm delete"
-	} else {
-		if s.isCurrentSession {
-			text = "● " + s.title
-		} else {
-			text = s.title
-		}
+		text := truncate.StringWithTail("Press again to confirm delete", uint(max(0, width-1)), "...")
+		return itemStyle.
+			Foreground(t.Error()).
+			Width(width).
+			PaddingLeft(1).
+			Render(text)
 	}
 
-	truncatedStr := truncate.StringWithTail(text, uint(width-1), "...")
+	meta := s.meta()
+	title := s.session.Title
+	if s.isCurrentSession {
+		title = "● " + title
+	}
+	title = truncate.StringWithTail(title, uint(max(0, width-lipgloss.Width(meta)-3)), "...")
+	gap := max(1, width-lipgloss.Width(title)-lipgloss.Width(meta)-2)
 
-	var itemStyle styles.Style
-	if selected {
-		if s.isDeleteConfirming {
-			// Red background for delete confirmation
-			itemStyle = baseStyle.
-				Background(t.Error()).
-				Foreground(t.BackgroundElement()).
-				Width(width).
-				PaddingLeft(1)
-		} else if s.isCurrentSession {
-			// Different style for current session when selected
-			itemStyle = baseStyle.
-				Background(t.Primary()).
-				Foreground(t.BackgroundElement()).
-				Width(width).
-				PaddingLeft(1).
-				Bold(true)
-		} else {
-			// Normal selection
-			itemStyle = baseStyle.
-				Background(t.Primary()).
-				Foreground(t.BackgroundElement()).
-				Width(width).
-				PaddingLeft(1)
+	return itemStyle.PaddingLeft(1).Render(title) +
+		metaStyle.Render(strings.Repeat(" ", gap)+meta+" ")
+}
+
+// meta describes the size and cost of the session, next to the time it was
+// created or last updated depending on the sort order
+func (s sessionItem) meta() string {
+	parts := []string{}
+	if s.session.Share.URL != "" {
+		parts = append(parts, "shared")
+	}
+	if s.summary != nil {
+		parts = append(parts, fmt.Sprintf("%d msgs", s.summary.Messages))
+		if s.summary.Cost > 0 {
+			parts = append(parts, fmt.Sprintf("$%.2f", s.summary.Cost))
 		}
+	}
+	timestamp := time.UnixMilli(int64(s.timestamp)).Local()
+	if timestamp.Year() == time.Now().Year() {
+		parts = append(parts, timestamp.Format("02 Jan 03:04 PM"))
 	} else {
-		if s.isDeleteConfirming {
-			// Red text for delete confirmation when not selected
-			itemStyle = baseStyle.
-				Foreground(t.Error()).
-				PaddingLeft(1)
-		} else if s.isCurrentSession {
-			// Highlight current session when not selected
-			itemStyle = baseStyle.
-				Foreground(t.Primary()).
-				PaddingLeft(1).
-				Bold(true)
-		} else {
-			itemStyle = baseStyle.
-				PaddingLeft(1)
-		}
+		parts = append(parts, timestamp.Format("02 Jan 2006"))
 	}
-
-	return itemStyle.Render(truncatedStr)
+	return strings.Join(parts, " · ")
 }
 
 func (s sessionItem) Selectable() bool {
 	return true
 }
 
+// forkItem is a custom list item for a user message a session can be forked from
+type forkItem struct {
+	messageID string
+	text      string
+	created   float64
+}
+
+func (f forkItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	itemStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.Text())
+	timeStyle := baseStyle.
+		Background(t.BackgroundPanel()).
+		Foreground(t.TextMuted())
+	if selected {
+		itemStyle = itemStyle.Foreground(t.Primary())
+	}
+
+	timestamp := " " + time.UnixMilli(int64(f.created)).Local().Format("03:04 PM")
+	text := strings.Join(strings.Fields(f.text), " ")
+	if text == "" {
+		text = "(no text)"
+	}
+	text = truncate.StringWithTail(text, uint(max(0, width-lipgloss.Width(timestamp)-2)), "...")
+
+	return itemStyle.PaddingLeft(1).Render(text) + timeStyle.Render(timestamp)
+}
+
+func (f forkItem) Selectable() bool {
+	return true
+}
+
 type sessionDialog struct {
+	app                *app.App
 	width              int
 	height             int
+	dialogWidth        int
 	modal              *modal.Modal
 	sessions           []opencode.Session
-	list               list.List[sessionItem]
-	app                *app.App
-	deleteConfirmation int // -1 means no confirmation, >= 0 means confirming deletion of session at this index
+	summaries          map[string]app.SessionSummary
+	requested          map[string]bool // ids of the sessions whose summary was requested
+	searchDialog       *SearchDialog
+	deleteConfirmation string // id of the session pending delete confirmation
+	renaming           *opencode.Session
+	renameInput        textinput.Model
+	forking            *opencode.Session
+	forkMessages       []app.Message
+	forkDialog         *SearchDialog
 }
 
 func (s *sessionDialog) Init() tea.Cmd {
-	return nil
+	return tea.Batch(s.searchDialog.Init(), s.loadSummaries())
+}
+
+// loadSummaries loads in the background the summaries of the sessions shown
+// in the list that weren't requested yet. Once the list is searched, all the
+// sessions are summarized, the search matches their first messages.
+func (s *sessionDialog) loadSummaries() tea.Cmd {
+	candidates := s.sessions
+	if strings.TrimSpace(s.searchDialog.GetQuery()) == "" {
+		items := s.searchDialog.list.GetItems()
+		start, end := s.searchDialog.list.VisibleRange()
+		candidates = nil
+		for _, item := range items[start:end] {
+			if item, ok := item.(sessionItem); ok {
+				candidates = append(candidates, item.session)
+			}
+		}
+	}
+	sessions := []opencode.Session{}
+	for _, session := range candidates {
+		if s.requested[session.ID] {
+			continue
+		}
+		s.requested[session.ID] = true
+		sessions = append(sessions, session)
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		return sessionSummariesMsg(s.app.SessionSummaries(context.Background(), sessions))
+	}
 }
 
 func (s *sessionDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := s.update(msg)
+	// the list scrolled or changed, summarize the sessions now shown
+	return model, tea.Batch(cmd, s.loadSummaries())
+}
+
+func (s *sessionDialog) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		s.width = msg.Width
 		s.height = msg.Height
-		s.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case sessionSummariesMsg:
+		if s.summaries == nil {
+			s.summaries = make(map[string]app.SessionSummary)
+		}
+		maps.Copy(s.summaries, msg)
+		s.updateListItems()
+		return s, nil
+	case sessionRenamedMsg:
+		for i, session := range s.sessions {
+			if session.ID == msg.session.ID {
+				s.sessions[i] = msg.session
+			}
+		}
+		s.updateListItems()
+		return s, nil
+	case sessionForkMessagesMsg:
+		session := msg.session
+		s.forking = &session
+		s.forkMessages = msg.messages
+		s.forkDialog = NewSearchDialog("Fork from message...", numVisibleSessions)
+		s.forkDialog.SetWidth(s.dialogWidth)
+		s.forkDialog.SetItems(s.forkItems(""))
+		s.modal.SetTitle("Fork Session")
+		return s, s.forkDialog.Init()
 	case tea.KeyPressMsg:
+		if s.renaming != nil {
+			return s.updateRename(msg)
+		}
+		if s.forking != nil {
+			break
+		}
 		switch msg.String() {
-		case "enter":
-			if s.deleteConfirmation >= 0 {
-				s.deleteConfirmation = -1
-				s.updateListItems()
-				return s, nil
+		case "ctrl+r":
+			if item, ok := s.selectedSession(); ok {
+				session := item.session
+				s.renaming = &session
+				s.deleteConfirmation = ""
+				s.renameInput.SetValue(session.Title)
+				s.renameInput.CursorEnd()
+				s.modal.SetTitle("Rename Session")
+				return s, s.renameInput.Focus()
 			}
-			if _, idx := s.list.GetSelectedItem(); idx >= 0 && idx < len(s.sessions) {
-				selectedSession := s.sessions[idx]
-				return s, tea.Sequence(
-					util.CmdHandler(modal.CloseModalMsg{}),
-					util.CmdHandler(app.SessionSelectedMsg(&selectedSession)),
-				)
+			return s, nil
+		case "ctrl+f":
+			if item, ok := s.selectedSession(); ok {
+				return s, s.loadForkMessages(item.session)
 			}
-		case "n":
-			s.app.Session = &opencode.Session{}
-			s.app.Messages = []app.Message{}
-			return s, tea.Sequence(
-				util.CmdHandler(modal.CloseModalMsg{}),
-				util.CmdHandler(app.SessionClearedMsg{}),
-			)
-		case "x", "delete", "backspace":
-			if _, idx := s.list.GetSelectedItem(); idx >= 0 && idx < len(s.sessions) {
-				if s.deleteConfirmation == idx {
-					// Second press - actually delete the session
-					sessionToDelete := s.sessions[idx]
-					return s, tea.Sequence(
-						func() tea.Msg {
-							s.sessions = slices.Delete(s.sessions, idx, idx+1)
-							s.deleteConfirmation = -1
-							s.updateListItems()
-							return nil
-						},
-						s.deleteSession(sessionToDelete.ID),
-					)
-				} else {
-					// First press - enter delete confirmation mode
-					s.deleteConfirmation = idx
-					s.updateListItems()
-					return s, nil
-				}
+			return s, nil
+		case "ctrl+l":
+			if item, ok := s.selectedSession(); ok && item.session.Share.URL != "" {
+				return s, util.CmdHandler(ShareSessionMsg{Session: item.session})
+			}
+			return s, nil
+		case "ctrl+s":
+			if s.app.State.SessionSort == sessionSortCreated {
+				s.app.State.SessionSort = sessionSortUpdated
+			} else {
+				s.app.State.SessionSort = sessionSortCreated
 			}
-		case "esc":
-			if s.deleteConfirmation >= 0 {
-				s.deleteConfirmation = -1
+			s.updateListItems()
+			return s, s.app.SaveState()
+		}
+	case SearchQueryChangedMsg:
+		if s.forking != nil {
+			s.forkDialog.SetItems(s.forkItems(msg.Query))
+			return s, nil
+		}
+		s.deleteConfirmation = ""
+		s.updateListItems()
+		return s, nil
+	case SearchSelectionMsg:
+		switch item := msg.Item.(type) {
+		case sessionItem:
+			if s.deleteConfirmation != "" {
+				s.deleteConfirmation = ""
 				s.updateListItems()
 				return s, nil
 			}
+			session := item.session
+			return s, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(app.SessionSelectedMsg(&session)),
+			)
+		case forkItem:
+			return s, s.forkSession(*s.forking, item.messageID)
+		}
+		return s, nil
+	case SearchRemoveItemMsg:
+		item, ok := msg.Item.(sessionItem)
+		if !ok {
+			return s, nil
+		}
+		if s.deleteConfirmation != item.session.ID {
+			// First press - enter delete confirmation mode
+			s.deleteConfirmation = item.session.ID
+			s.updateListItems()
+			return s, nil
+		}
+		// Second press - actually delete the session
+		s.sessions = slices.DeleteFunc(s.sessions, func(session opencode.Session) bool {
+			return session.ID == item.session.ID
+		})
+		s.deleteConfirmation = ""
+		s.updateListItems()
+		return s, s.deleteSession(item.session.ID)
+	case SearchCancelledMsg:
+		return s, util.CmdHandler(modal.CloseModalMsg{})
+	}
+
+	if s.renaming != nil {
+		var cmd tea.Cmd
+		s.renameInput, cmd = s.renameInput.Update(msg)
+		return s, cmd
+	}
+	if s.forking != nil {
+		updated, cmd := s.forkDialog.Update(msg)
+		s.forkDialog = updated.(*SearchDialog)
+		return s, cmd
+	}
+	updated, cmd := s.searchDialog.Update(msg)
+	s.searchDialog = updated.(*SearchDialog)
+	return s, cmd
+}
+
+// updateRename edits the title of the session being renamed, enter saves it
+func (s *sessionDialog) updateRename(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		session := *s.renaming
+		title := strings.TrimSpace(s.renameInput.Value())
+		s.renaming = nil
+		s.renameInput.Blur()
+		s.modal.SetTitle("Sessions")
+		// an empty or unchanged title cancels the rename
+		if title == "" || title == session.Title {
+			return s, nil
 		}
+		return s, s.renameSession(session.ID, title)
 	}
 
 	var cmd tea.Cmd
-	listModel, cmd := s.list.Update(msg)
-	s.list = listModel.(list.List[sessionItem])
+	s.renameInput, cmd = s.renameInput.Update(msg)
 	return s, cmd
 }
 
 func (s *sessionDialog) Render(background string) string {
-	listView := s.list.View()
-
 	t := theme.CurrentTheme()
 	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
 	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
 
-	leftHelp := keyStyle("n") + mutedStyle(" new session")
-	rightHelp := keyStyle("x/del") + mutedStyle(" delete session")
+	var content string
+	var help []string
+	switch {
+	case s.renaming != nil:
+		content = s.renameInput.View()
+		help = []string{
+			keyStyle("enter") + mutedStyle(" save title"),
+			keyStyle("esc") + mutedStyle(" close"),
+		}
+	case s.forking != nil:
+		content = s.forkDialog.View()
+		help = []string{
+			keyStyle("enter") + mutedStyle(" fork from message"),
+			keyStyle("esc") + mutedStyle(" close"),
+		}
+	default:
+		content = s.searchDialog.View()
+		sortOrder := "updated"
+		if s.app.State.SessionSort == sessionSortCreated {
+			sortOrder = "created"
+		}
+		help = []string{
+			keyStyle("ctrl+r") + mutedStyle(" rename"),
+			keyStyle("ctrl+f") + mutedStyle(" fork"),
+			keyStyle("ctrl+x") + mutedStyle(" delete"),
+			keyStyle("ctrl+s") + mutedStyle(" sort: "+sortOrder),
+		}
+		if item, ok := s.selectedSession(); ok && item.session.Share.URL != "" {
+			help = append(help, keyStyle("ctrl+l")+mutedStyle(" share"))
+		}
+	}
 
 	bgColor := t.BackgroundPanel()
+	items := []layout.FlexItem{}
+	for _, view := range help {
+		items = append(items, layout.FlexItem{View: view})
+	}
 	helpText := layout.Render(layout.FlexOptions{
 		Direction:  layout.Row,
 		Justify:    layout.JustifySpaceBetween,
-		Width:      layout.Current.Container.Width - 14,
+		Width:      s.dialogWidth - 2,
 		Background: &bgColor,
-	}, layout.FlexItem{View: leftHelp}, layout.FlexItem{View: rightHelp})
-
+	}, items...)
 	helpText = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpText)
 
-	content := strings.Join([]string{listView, helpText}, "\n")
+	return s.modal.Render(content+"\n"+helpText, background)
+}
 
-	return s.modal.Render(content, background)
+func (s *sessionDialog) selectedSession() (sessionItem, bool) {
+	item, idx := s.searchDialog.list.GetSelectedItem()
+	if idx < 0 {
+		return sessionItem{}, false
+	}
+	selected, ok := item.(sessionItem)
+	return selected, ok
 }
 
+// updateListItems filters the sessions by the search query, fuzzy matching
+// against the title and the first user message of those summarized, and
+// sorts them
 func (s *sessionDialog) updateListItems() {
-	_, currentIdx := s.list.GetSelectedItem()
+	sortKey := func(session opencode.Session) float64 {
+		if s.app.State.SessionSort == sessionSortCreated {
+			return session.Time.Created
+		}
+		return session.Time.Updated
+	}
+
+	sessions := slices.Clone(s.sessions)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sortKey(sessions[i]) > sortKey(sessions[j])
+	})
+
+	query := strings.TrimSpace(s.searchDialog.GetQuery())
+	if query != "" {
+		targets := make([]string, len(sessions))
+		for i, session := range sessions {
+			targets[i] = session.Title
+			if summary, ok := s.summaries[session.ID]; ok {
+				targets[i] += " " + summary.FirstMessage
+			}
+		}
+		matches := fuzzy.RankFindFold(query, targets)
+		sort.Stable(matches)
+		matched := make([]opencode.Session, 0, len(matches))
+		for _, match := range matches {
+			matched = append(matched, sessions[match.OriginalIndex])
+		}
+		sessions = matched
+	}
 
-	var items []sessionItem
-	for i, sess := range s.sessions {
+	items := make([]list.Item, 0, len(sessions))
+	for _, session := range sessions {
 		item := sessionItem{
-			title:              sess.Title,
-			isDeleteConfirming: s.deleteConfirmation == i,
-			isCurrentSession:   s.app.Session != nil && s.app.Session.ID == sess.ID,
+			session:            session,
+			timestamp:          sortKey(session),
+			isDeleteConfirming: s.deleteConfirmation == session.ID,
+			isCurrentSession:   s.app.Session != nil && s.app.Session.ID == session.ID,
+		}
+		if summary, ok := s.summaries[session.ID]; ok {
+			item.summary = &summary
 		}
 		items = append(items, item)
 	}
-	s.list.SetItems(items)
-	s.list.SetSelectedIndex(currentIdx)
+
+	_, currentIdx := s.searchDialog.list.GetSelectedItem()
+	s.searchDialog.SetItems(items)
+	if currentIdx >= 0 && currentIdx < len(items) {
+		s.searchDialog.list.SetSelectedIndex(currentIdx)
+	}
+}
+
+// forkItems lists the user messages of the session being forked, the latest
+// first, filtered by the query
+func (s *sessionDialog) forkItems(query string) []list.Item {
+	items := []list.Item{}
+	for _, message := range slices.Backward(s.forkMessages) {
+		info, ok := message.Info.(opencode.UserMessage)
+		if !ok {
+			continue
+		}
+		text := app.MessageText(message)
+		if query != "" && !fuzzy.MatchFold(query, text) {
+			continue
+		}
+		items = append(items, forkItem{
+			messageID: info.ID,
+			text:      text,
+			created:   info.Time.Created,
+		})
+	}
+	return items
 }
 
 func (s *sessionDialog) deleteSession(sessionID string) tea.Cmd {
@@ -231,52 +525,90 @@ func (s *sessionDialog) deleteSession(sessionID string) tea.Cmd {
 	}
 }
 
+func (s *sessionDialog) renameSession(sessionID string, title string) tea.Cmd {
+	return func() tea.Msg {
+		session, err := s.app.RenameSession(context.Background(), sessionID, title)
+		if err != nil {
+			return toast.NewErrorToast("Failed to rename session: " + err.Error())()
+		}
+		return sessionRenamedMsg{session: *session}
+	}
+}
+
+func (s *sessionDialog) loadForkMessages(session opencode.Session) tea.Cmd {
+	return func() tea.Msg {
+		messages, err := s.app.ListMessages(context.Background(), session.ID)
+		if err != nil {
+			return toast.NewErrorToast("Failed to load messages: " + err.Error())()
+		}
+		return sessionForkMessagesMsg{session: session, messages: messages}
+	}
+}
+
+// forkSession creates the fork and switches to it
+func (s *sessionDialog) forkSession(session opencode.Session, messageID string) tea.Cmd {
+	return func() tea.Msg {
+		forked, err := s.app.ForkSession(context.Background(), session.ID, messageID)
+		if err != nil {
+			return toast.NewErrorToast("Failed to fork session: " + err.Error())()
+		}
+		return tea.BatchMsg{
+			util.CmdHandler(modal.CloseModalMsg{}),
+			util.CmdHandler(app.SessionSelectedMsg(forked)),
+		}
+	}
+}
+
 func (s *sessionDialog) Close() tea.Cmd {
 	return nil
 }
 
-// NewSessionDialog creates a new session switching dialog
+// NewSessionDialog creates a new session browser dialog
 func NewSessionDialog(app *app.App) SessionDialog {
 	sessions, _ := app.ListSessions(context.Background())
 
 	var filteredSessions []opencode.Session
-	var items []sessionItem
 	for _, sess := range sessions {
 		if sess.ParentID != "" {
 			continue
 		}
 		filteredSessions = append(filteredSessions, sess)
-		items = append(items, sessionItem{
-			title:              sess.Title,
-			isDeleteConfirming: false,
-			isCurrentSession:   app.Session != nil && app.Session.ID == sess.ID,
-		})
 	}
 
-	listComponent := list.NewListComponent(
-		list.WithItems(items),
-		list.WithMaxVisibleHeight[sessionItem](10),
-		list.WithFallbackMessage[sessionItem]("No sessions available"),
-		list.WithAlphaNumericKeys[sessionItem](true),
-		list.WithRenderFunc(
-			func(item sessionItem, selected bool, width int, baseStyle styles.Style) string {
-				return item.Render(selected, width, false, baseStyle)
-			},
-		),
-		list.WithSelectableFunc(func(item sessionItem) bool {
-			return true
-		}),
-	)
-	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
-
-	return &sessionDialog{
-		sessions:           filteredSessions,
-		list:               listComponent,
-		app:                app,
-		deleteConfirmation: -1,
+	width := layout.Current.Container.Width - 12
+	t := theme.CurrentTheme()
+	renameInput := textinput.New()
+	renameInput.Placeholder = "Session title"
+	renameInput.Prompt = " "
+	renameInput.CharLimit = -1
+	renameInput.VirtualCursor = true
+	renameInput.SetWidth(width - 2)
+	renameInput.Styles.Focused.Text = styles.NewStyle().
+		Foreground(t.Text()).
+		Background(t.BackgroundElement()).
+		Lipgloss()
+	renameInput.Styles.Focused.Placeholder = styles.NewStyle().
+		Foreground(t.TextMuted()).
+		Background(t.BackgroundElement()).
+		Lipgloss()
+	renameInput.Styles.Focused.Prompt = styles.NewStyle().
+		Background(t.BackgroundElement()).
+		Lipgloss()
+	renameInput.Styles.Cursor.Color = t.Primary()
+
+	dialog := &sessionDialog{
+		app:          app,
+		dialogWidth:  width,
+		sessions:     filteredSessions,
+		requested:    map[string]bool{},
+		searchDialog: NewSearchDialog("Search sessions...", numVisibleSessions),
+		renameInput:  renameInput,
 		modal: modal.New(
-			modal.WithTitle("Switch Session"),
-			modal.WithMaxWidth(layout.Current.Container.Width-8),
+			modal.WithTitle("Sessions"),
+			modal.WithMaxWidth(width+4),
 		),
 	}
+	dialog.searchDialog.SetWidth(width)
+	dialog.updateListItems()
+	return dialog
 }
@@ -0,0 +1,39 @@
+package dialog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/theme"
+)
+
+func TestSessionSummariesLoadForSearch(t *testing.T) {
+	// the search input is styled with the current theme
+	theme.LoadThemesFromJSON()
+	theme.SetTheme("opencode")
+
+	s := &sessionDialog{
+		app:          &app.App{State: app.NewState()},
+		requested:    map[string]bool{},
+		searchDialog: NewSearchDialog("Search sessions...", 2),
+	}
+	for i := range 5 {
+		s.sessions = append(s.sessions, opencode.Session{ID: fmt.Sprintf("ses_%d", i)})
+	}
+	s.updateListItems()
+
+	if s.loadSummaries() == nil || len(s.requested) != 2 {
+		t.Fatalf("expected only the visible sessions to be summarized, got %v", s.requested)
+	}
+
+	// the search matches the first messages of every session
+	s.searchDialog.SetQuery("parser")
+	if s.loadSummaries() == nil || len(s.requested) != len(s.sessions) {
+		t.Errorf("expected every session to be summarized once searched, got %v", s.requested)
+	}
+	if s.loadSummaries() != nil {
+		t.Errorf("expected the summaries to be requested once")
+	}
+}
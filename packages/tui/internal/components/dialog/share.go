@@ -0,0 +1,148 @@
+package dialog
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/qr"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const shareDialogMinWidth = 44
+
+// ShareDialog interface for the dialog showing the share link of a session
+type ShareDialog interface {
+	layout.Modal
+}
+
+// ShareSessionMsg opens the share dialog of a shared session
+type ShareSessionMsg struct {
+	Session opencode.Session
+}
+
+// SessionUnsharedMsg is sent when a session was unshared from the dialog
+type SessionUnsharedMsg struct {
+	Session opencode.Session
+}
+
+type shareDialog struct {
+	app     *app.App
+	session opencode.Session
+	code    string
+	width   int
+	modal   *modal.Modal
+}
+
+func (s *shareDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (s *shareDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "c", "y":
+			return s, tea.Batch(
+				app.SetClipboard(s.session.Share.URL),
+				toast.NewSuccessToast("Share URL copied to clipboard!"),
+			)
+		case "u":
+			return s, s.unshare()
+		}
+	}
+	return s, nil
+}
+
+// unshare stops sharing the session and closes the dialog
+func (s *shareDialog) unshare() tea.Cmd {
+	sessionID := s.session.ID
+	return func() tea.Msg {
+		session, err := s.app.UnshareSession(context.Background(), sessionID)
+		if err != nil {
+			return toast.NewErrorToast("Failed to unshare session")()
+		}
+		return tea.BatchMsg{
+			util.CmdHandler(modal.CloseModalMsg{}),
+			util.CmdHandler(SessionUnsharedMsg{Session: *session}),
+			toast.NewSuccessToast("Session unshared successfully"),
+		}
+	}
+}
+
+func (s *shareDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Background(t.BackgroundPanel())
+	keyStyle := base.Foreground(t.Text()).Render
+	mutedStyle := base.Foreground(t.TextMuted()).Render
+
+	// the URL is plain text so it can be selected in terminals where the
+	// clipboard isn't available
+	url := ansi.Hardwrap(s.session.Share.URL, s.width, false)
+	sections := []string{
+		lipgloss.PlaceHorizontal(
+			s.width,
+			lipgloss.Center,
+			s.code,
+			styles.WhitespaceStyle(t.BackgroundPanel()),
+		),
+		base.Foreground(t.Text()).Width(s.width).Render(url),
+	}
+
+	bgColor := t.BackgroundPanel()
+	help := layout.Render(layout.FlexOptions{
+		Direction:  layout.Row,
+		Justify:    layout.JustifySpaceBetween,
+		Width:      s.width,
+		Background: &bgColor,
+	},
+		layout.FlexItem{View: keyStyle("c") + mutedStyle(" copy url")},
+		layout.FlexItem{View: keyStyle("u") + mutedStyle(" unshare")},
+	)
+	sections = append(sections, help)
+
+	return s.modal.Render(strings.Join(sections, "\n\n"), background)
+}
+
+func (s *shareDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewShareDialog creates a dialog showing the share link of a session as a
+// QR code to scan, along with the link itself
+func NewShareDialog(app *app.App, session opencode.Session) ShareDialog {
+	t := theme.CurrentTheme()
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel())
+
+	code, size, err := qr.Generate(session.Share.URL)
+	// the margin around the code helps scanners find it
+	code = styles.NewStyle().
+		Background(t.Background()).
+		Padding(1, 2).
+		Render(strings.TrimSuffix(code, "\n"))
+	width := max(shareDialogMinWidth, size+4)
+	available := layout.Current.Container.Width - 12
+	switch {
+	case err != nil || size == 0:
+		code = muted.Render("The QR code could not be generated")
+	case width > available || lipgloss.Height(code)+12 > layout.Current.Viewport.Height:
+		code = muted.Render("Enlarge the terminal to show the QR code")
+	}
+
+	return &shareDialog{
+		app:     app,
+		session: session,
+		code:    code,
+		width:   min(width, available),
+		modal:   modal.New(modal.WithTitle("Share Session")),
+	}
+}
@@ -32,6 +32,7 @@ type Model struct {
 	content       *string
 	isDiff        *bool
 	diffStyle     DiffStyle
+	scrollTo      *int
 }
 
 type fileRenderedMsg struct {
@@ -61,6 +62,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case fileRenderedMsg:
 		m.viewport.SetContent(msg.content)
+		if m.scrollTo != nil {
+			m.viewport.SetYOffset(*m.scrollTo)
+			m.scrollTo = nil
+		}
 		return m, util.CmdHandler(app.FileRenderedMsg{
 			FilePath: *m.filename,
 		})
@@ -130,6 +135,7 @@ func (m *Model) Clear() (Model, tea.Cmd) {
 	m.filename = nil
 	m.content = nil
 	m.isDiff = nil
+	m.scrollTo = nil
 	return *m, m.render()
 }
 
@@ -228,7 +234,10 @@ func (m *Model) render() tea.Cmd {
 	}
 }
 
+// ScrollTo moves the viewport to the given line, the offset is applied again
+// once the file has finished rendering in case the content is still pending
 func (m *Model) ScrollTo(line int) {
+	m.scrollTo = &line
 	m.viewport.SetYOffset(line)
 }
 
@@ -97,6 +97,7 @@ type List[T any] interface {
 	SetEmptyMessage(msg string)
 	IsEmpty() bool
 	GetMaxVisibleHeight() int
+	VisibleRange() (start, end int)
 }
 
 type listComponent[T any] struct {
@@ -246,6 +247,12 @@ func (c *listComponent[T]) GetMaxVisibleHeight() int {
 	return c.maxVisibleHeight
 }
 
+// VisibleRange returns the index of the first item shown and the index past
+// the last one
+func (c *listComponent[T]) VisibleRange() (start, end int) {
+	return c.calculateViewport()
+}
+
 func (c *listComponent[T]) View() string {
 	items := c.items
 	maxWidth := c.maxWidth
@@ -1,6 +1,7 @@
 package status
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/charmbracelet/lipgloss/v2/compat"
 	"github.com/fsnotify/fsnotify"
+	"github.com/muesli/reflow/truncate"
 	"github.com/sst/opencode/internal/app"
 	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/layout"
@@ -37,6 +39,7 @@ type statusComponent struct {
 	watcher    *fsnotify.Watcher
 	done       chan struct{}
 	lastUpdate time.Time
+	connection app.ConnectionStateMsg
 }
 
 func (m *statusComponent) Init() tea.Cmd {
@@ -48,6 +51,9 @@ func (m *statusComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		return m, nil
+	case app.ConnectionStateMsg:
+		m.connection = msg
+		return m, nil
 	case GitBranchUpdatedMsg:
 		if m.branch != msg.Branch {
 			m.branch = msg.Branch
@@ -81,6 +87,44 @@ func (m *statusComponent) logo() string {
 		Render(content)
 }
 
+// connectionState renders a warning while the event stream is down, nothing
+// is shown once it is connected
+func (m *statusComponent) connectionState() string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Background(t.BackgroundPanel())
+	switch m.connection.State {
+	case app.ConnectionConnecting:
+		return base.Foreground(t.TextMuted()).Render("connecting…") + base.Render("  ")
+	case app.ConnectionReconnecting:
+		label := fmt.Sprintf("⚠ reconnecting (attempt %d)", m.connection.Attempt)
+		return base.Foreground(t.Warning()).Render(label) + base.Render("  ")
+	}
+	return ""
+}
+
+// diagnostics renders the error and warning counts reported by the language
+// servers, nothing is shown while the workspace is clean
+func (m *statusComponent) diagnostics() string {
+	if m.app.Diagnostics == nil {
+		return ""
+	}
+	errors, warnings := m.app.Diagnostics.Counts()
+	if errors == 0 && warnings == 0 {
+		return ""
+	}
+
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Background(t.BackgroundPanel())
+	counts := []string{}
+	if errors > 0 {
+		counts = append(counts, base.Foreground(t.Error()).Render(fmt.Sprintf("✗ %d", errors)))
+	}
+	if warnings > 0 {
+		counts = append(counts, base.Foreground(t.Warning()).Render(fmt.Sprintf("⚠ %d", warnings)))
+	}
+	return strings.Join(counts, base.Render(" ")) + base.Render("  ")
+}
+
 func (m *statusComponent) collapsePath(path string, maxWidth int) string {
 	if lipgloss.Width(path) <= maxWidth {
 		return path
@@ -148,12 +192,7 @@ func (m *statusComponent) View() string {
 		modeForeground = t.BackgroundPanel()
 	}
 
-	command := m.app.Commands[commands.SwitchModeCommand]
-	kb := command.Keybindings[0]
-	key := kb.Key
-	if kb.RequiresLeader {
-		key = m.app.Config.Keybinds.Leader + " " + kb.Key
-	}
+	key := m.app.Keybind(commands.SwitchModeCommand)
 
 	modeStyle := styles.NewStyle().Background(modeBackground).Foreground(modeForeground)
 	modeNameStyle := modeStyle.Bold(true).Render
@@ -171,7 +210,10 @@ func (m *statusComponent) View() string {
 		Faint(true).
 		Background(t.BackgroundPanel()).
 		Foreground(t.TextMuted())
-	mode = faintStyle.Render(key+" ") + mode
+	if key != "" {
+		mode = faintStyle.Render(key+" ") + mode
+	}
+	mode = m.connectionState() + m.diagnostics() + mode
 	modeWidth := lipgloss.Width(mode)
 
 	availableWidth := m.width - logoWidth - modeWidth
@@ -211,9 +253,49 @@ func (m *statusComponent) View() string {
 	)
 
 	blank := styles.NewStyle().Background(t.Background()).Width(m.width).Render("")
+	if tabs := m.tabs(); tabs != "" {
+		blank = tabs
+	}
 	return blank + "\n" + status
 }
 
+// tabs renders a strip of the open session tabs with a busy indicator for
+// each, nothing is shown while a single session is open
+func (m *statusComponent) tabs() string {
+	tabs := m.app.ListTabs()
+	if len(tabs) < 2 {
+		return ""
+	}
+
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Background(t.Background())
+	maxTitleWidth := max(8, m.width/len(tabs)-6)
+
+	items := []string{}
+	for i, tab := range tabs {
+		title := tab.Session.Title
+		if tab.Session.ID == "" {
+			title = "New session"
+		}
+		title = truncate.StringWithTail(title, uint(maxTitleWidth), "…")
+
+		indicator := base.Foreground(t.TextMuted()).Render("○")
+		if tab.IsBusy() {
+			indicator = base.Foreground(t.Warning()).Render("●")
+		}
+
+		style := base.Foreground(t.TextMuted())
+		if i == m.app.ActiveTab {
+			style = style.Foreground(t.Text()).Bold(true)
+		}
+		items = append(items, indicator+style.Render(fmt.Sprintf(" %d:%s", i+1, title)))
+	}
+
+	strip := strings.Join(items, base.Render("  "))
+	strip = truncate.StringWithTail(strip, uint(max(0, m.width-2)), "…")
+	return base.Width(m.width).Padding(0, 1).Render(strip)
+}
+
 func (m *statusComponent) startGitWatcher() tea.Cmd {
 	cmd := util.CmdHandler(
 		GitBranchUpdatedMsg{Branch: getCurrentGitBranch(m.app.Info.Path.Root)},
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/sst/opencode/internal/attachment"
 )
 
 // BenchmarkHashingPerformance compares SHA256 vs FNV-1a hashing performance
@@ -40,7 +42,7 @@ func BenchmarkMemoryOptimizations(b *testing.B) {
 	for i := 0; i < 1000; i++ {
 		if i%10 == 0 {
 			// 10% attachments
-			testItems[i] = &Attachment{Display: fmt.Sprintf("attachment_%d", i)}
+			testItems[i] = &attachment.Attachment{Display: fmt.Sprintf("attachment_%d", i)}
 		} else {
 			// 90% runes
 			testItems[i] = rune('A' + (i % 26))
@@ -73,7 +75,7 @@ func BenchmarkMemoryOptimizations(b *testing.B) {
 				switch val := item.(type) {
 				case rune:
 					result = append(result, val)
-				case *Attachment:
+				case *attachment.Attachment:
 					result = append(result, []rune(val.Display)...)
 				}
 			}
@@ -90,7 +92,7 @@ func BenchmarkMemoryOptimizations(b *testing.B) {
 				switch val := item.(type) {
 				case rune:
 					s.WriteRune(val)
-				case *Attachment:
+				case *attachment.Attachment:
 					s.WriteString(val.Display)
 				}
 			}
@@ -147,68 +149,6 @@ func BenchmarkCachePerformance(b *testing.B) {
 	})
 }
 
-// BenchmarkCapacityEstimation tests the performance of our capacity estimation algorithms
-func BenchmarkCapacityEstimation(b *testing.B) {
-	// Create test data of various sizes
-	smallItems := make([]any, 10)
-	mediumItems := make([]any, 100)
-	largeItems := make([]any, 1000)
-
-	for i := range smallItems {
-		smallItems[i] = rune('A' + (i % 26))
-	}
-	for i := range mediumItems {
-		if i%5 == 0 {
-			mediumItems[i] = &Attachment{Display: "attachment"}
-		} else {
-			mediumItems[i] = rune('A' + (i % 26))
-		}
-	}
-	for i := range largeItems {
-		if i%10 == 0 {
-			largeItems[i] = &Attachment{Display: fmt.Sprintf("attachment_%d", i)}
-		} else {
-			largeItems[i] = rune('A' + (i % 26))
-		}
-	}
-
-	b.Run("EstimateRuneCapacity_Small", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_ = estimateRuneCapacity(smallItems)
-		}
-	})
-
-	b.Run("EstimateRuneCapacity_Medium", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_ = estimateRuneCapacity(mediumItems)
-		}
-	})
-
-	b.Run("EstimateRuneCapacity_Large", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_ = estimateRuneCapacity(largeItems)
-		}
-	})
-
-	b.Run("EstimateStringCapacity_Small", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_ = estimateStringCapacity(smallItems)
-		}
-	})
-
-	b.Run("EstimateStringCapacity_Medium", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_ = estimateStringCapacity(mediumItems)
-		}
-	})
-
-	b.Run("EstimateStringCapacity_Large", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_ = estimateStringCapacity(largeItems)
-		}
-	})
-}
-
 // BenchmarkObjectPooling tests the performance impact of object pooling
 func BenchmarkObjectPooling(b *testing.B) {
 	testData := make([]rune, 100)
@@ -112,17 +112,24 @@ func (m Model) isAttachmentAtCursor() (*attachment.Attachment, int, int) {
 	return nil, -1, -1
 }
 
-// renderLineWithAttachments renders a line with proper attachment highlighting
+// renderLineWithAttachments renders a line with proper attachment highlighting.
+// The row and column of the first item are used to highlight the visual
+// selection of vim mode.
 func (m Model) renderLineWithAttachments(
 	items []any,
 	style lipgloss.Style,
+	row, col int,
 ) string {
 	var s strings.Builder
 	currentAttachment, _, _ := m.isAttachmentAtCursor()
 
-	for _, item := range items {
+	for i, item := range items {
 		switch val := item.(type) {
 		case rune:
+			if m.vimSelected(row, col+i) {
+				s.WriteString(m.Styles.Selection.Render(string(val)))
+				break
+			}
 			s.WriteString(style.Render(string(val)))
 		case *attachment.Attachment:
 			// Check if this is the attachment the cursor is currently on
@@ -362,6 +369,8 @@ type Styles struct {
 	Cursor             CursorStyle
 	Attachment         lipgloss.Style
 	SelectedAttachment lipgloss.Style
+	// Selection is the style of the text selected in vim's visual mode.
+	Selection lipgloss.Style
 }
 
 // StyleState that will be applied to the text area.
@@ -487,6 +496,10 @@ type Model struct {
 	// there's no limit.
 	MaxWidth int
 
+	// UndoLimit is the maximum number of edits that can be undone. If 0 or
+	// less, there's no limit.
+	UndoLimit int
+
 	// If promptFunc is set, it replaces Prompt as a generator for
 	// prompt strings at the beginning of each line.
 	promptFunc func(line int) string
@@ -522,6 +535,12 @@ type Model struct {
 
 	// rune sanitizer for input.
 	rsan Sanitizer
+
+	// history holds the edits that can be undone and redone.
+	history history
+
+	// vim holds the state of vim mode, when enabled.
+	vim vimState
 }
 
 // New creates a new model with default settings.
@@ -534,6 +553,7 @@ func New() Model {
 		CharLimit:            defaultCharLimit,
 		MaxHeight:            defaultMaxHeight,
 		MaxWidth:             defaultMaxWidth,
+		UndoLimit:            defaultUndoLimit,
 		Prompt:               lipgloss.ThickBorder().Left + " ",
 		Styles:               styles,
 		cache:                NewMemoCache[line, [][]any](maxLines),
@@ -596,6 +616,7 @@ func DefaultStyles(isDark bool) Styles {
 	s.SelectedAttachment = lipgloss.NewStyle().
 		Background(lipgloss.Color("11")).
 		Foreground(lipgloss.Color("0"))
+	s.Selection = lipgloss.NewStyle().Reverse(true)
 	s.Cursor = CursorStyle{
 		Color: lipgloss.Color("7"),
 		Shape: tea.CursorBlock,
@@ -654,6 +675,7 @@ func (m *Model) InsertRune(r rune) {
 
 // InsertAttachment inserts an attachment at the cursor position.
 func (m *Model) InsertAttachment(att *attachment.Attachment) {
+	m.record(editExternal)
 	if m.CharLimit > 0 {
 		availSpace := m.CharLimit - m.Length()
 		// If the char limit's been reached, cancel.
@@ -676,6 +698,7 @@ func (m *Model) ReplaceRange(startCol, endCol int, replacement string) {
 	if m.row >= len(m.value) || startCol < 0 || endCol < startCol {
 		return
 	}
+	m.record(editExternal)
 
 	// Ensure bounds are within the current row
 	rowLen := len(m.value[m.row])
@@ -743,6 +766,13 @@ func (m Model) GetAttachments() []*attachment.Attachment {
 
 // InsertRunesFromUserInput inserts runes at the current cursor position.
 func (m *Model) InsertRunesFromUserInput(runes []rune) {
+	m.record(editExternal)
+	m.insertRunes(runes)
+}
+
+// insertRunes inserts runes at the current cursor position, as part of the
+// current edit.
+func (m *Model) insertRunes(runes []rune) {
 	// Clean up any special characters in the input provided by the
 	// clipboard. This avoids bugs due to e.g. tab characters and
 	// whatnot.
@@ -901,7 +931,13 @@ func (m Model) LastRuneIndex(r rune) int {
 	return -1
 }
 
+// Newline splits the line at the cursor.
 func (m *Model) Newline() {
+	m.record(editExternal)
+	m.newline()
+}
+
+func (m *Model) newline() {
 	if m.MaxHeight > 0 && len(m.value) >= m.MaxHeight {
 		return
 	}
@@ -1155,12 +1191,15 @@ func (m *Model) Blur() {
 	m.virtualCursor.Blur()
 }
 
-// Reset sets the input to its default state with no input.
+// Reset sets the input to its default state with no input. The input that's
+// cleared can be brought back with Undo.
 func (m *Model) Reset() {
+	m.record(editExternal)
 	m.value = make([][]any, minHeight, maxLines)
 	m.col = 0
 	m.row = 0
 	m.SetCursorColumn(0)
+	m.resetVim()
 }
 
 // san initializes or retrieves the rune sanitizer.
@@ -1561,6 +1600,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
+		if m.vim.enabled && m.vimKey(msg) {
+			break
+		}
+		m.record(m.keyEditKind(msg))
 		switch {
 		case key.Matches(msg, m.KeyMap.DeleteAfterCursor):
 			m.col = clamp(m.col, 0, len(m.value[m.row]))
@@ -1608,7 +1651,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 			m.deleteWordRight()
 		case key.Matches(msg, m.KeyMap.InsertNewline):
-			m.Newline()
+			m.newline()
 		case key.Matches(msg, m.KeyMap.LineEnd):
 			m.CursorEnd()
 		case key.Matches(msg, m.KeyMap.LineStart):
@@ -1639,7 +1682,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.transposeLeft()
 
 		default:
-			m.InsertRunesFromUserInput([]rune(msg.Text))
+			m.insertRunes([]rune(msg.Text))
 		}
 
 	case pasteMsg:
@@ -1648,6 +1691,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case pasteErrMsg:
 		m.Err = msg
 	}
+	m.vimClamp()
 
 	var cmd tea.Cmd
 	newRow, newCol := m.cursorLineNumber(), m.col
@@ -1688,6 +1732,7 @@ func (m Model) View() string {
 			style = styles.computedText()
 		}
 
+		offset := 0
 		for wl, wrappedLine := range wrappedLines {
 			prompt := m.promptView(displayLine)
 			prompt = styles.computedPrompt().Render(prompt)
@@ -1732,6 +1777,7 @@ func (m Model) View() string {
 					m.renderLineWithAttachments(
 						wrappedLine[:lineInfo.ColumnOffset],
 						style,
+						l, offset,
 					),
 				)
 
@@ -1752,15 +1798,20 @@ func (m Model) View() string {
 					}
 
 					// Render the part of the line after the cursor
-					s.WriteString(m.renderLineWithAttachments(wrappedLine[lineInfo.ColumnOffset+1:], style))
+					s.WriteString(m.renderLineWithAttachments(
+						wrappedLine[lineInfo.ColumnOffset+1:],
+						style,
+						l, offset+lineInfo.ColumnOffset+1,
+					))
 				} else {
 					// Cursor is at the end of the line
 					m.virtualCursor.SetChar(" ")
 					s.WriteString(style.Render(m.virtualCursor.View()))
 				}
 			} else {
-				s.WriteString(m.renderLineWithAttachments(wrappedLine, style))
+				s.WriteString(m.renderLineWithAttachments(wrappedLine, style, l, offset))
 			}
+			offset += len(wrappedLine)
 
 			s.WriteString(style.Render(strings.Repeat(" ", max(0, padding))))
 			s.WriteRune('\n')
@@ -0,0 +1,205 @@
+package textarea
+
+import (
+	"slices"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// defaultUndoLimit is the number of edits that can be undone by default.
+const defaultUndoLimit = 100
+
+// editKind is the kind of an edit. Consecutive edits of some kinds, like
+// typing a word, are undone together.
+type editKind int
+
+const (
+	// editNone is moving the cursor, it ends the current edit.
+	editNone editKind = iota
+	// editInsert and editSpace are typing, a word and the spaces after it
+	// are undone together.
+	editInsert
+	editSpace
+	// editDelete is deleting with the keyboard.
+	editDelete
+	// editKey is any other edit made with a key, it's undone on its own.
+	editKey
+	// editExternal is an edit made through the methods of the textarea, like
+	// a paste or clearing the input.
+	editExternal
+)
+
+// continues returns whether an edit of a kind is undone along with the edit
+// before it.
+func (k editKind) continues(previous editKind) bool {
+	switch k {
+	case editNone:
+		return previous == editNone
+	case editInsert:
+		return previous == editInsert
+	case editSpace:
+		return previous == editInsert || previous == editSpace
+	case editDelete:
+		return previous == editDelete
+	case editExternal:
+		return previous == editExternal
+	}
+	return false
+}
+
+// snapshot is a copy of the value and cursor, restored by undo and redo.
+// Attachments are kept in the value, so they come back along with the text
+// around them.
+type snapshot struct {
+	value [][]any
+	row   int
+	col   int
+}
+
+// history is the undo and redo stacks of the textarea.
+type history struct {
+	undo []snapshot
+	redo []snapshot
+	// base is the value before the current edit, saved to the undo stack
+	// once the edit ends if it changed anything.
+	base snapshot
+	kind editKind
+}
+
+// record starts an edit of a kind, unless it continues the current one.
+func (m *Model) record(kind editKind) {
+	// vim undoes what's typed in insert mode all at once
+	if m.vim.enabled && m.vim.mode == VimInsert && kind != editNone && kind != editExternal {
+		kind = editInsert
+	}
+	if kind != editNone {
+		m.vimLeaveVisual()
+	}
+	if !kind.continues(m.history.kind) {
+		m.commit()
+	}
+	m.history.kind = kind
+}
+
+// commit ends the current edit, saving the value before it to the undo stack
+// when it changed anything.
+func (m *Model) commit() {
+	h := &m.history
+	if h.base.value == nil {
+		h.base = m.snapshot()
+		return
+	}
+	if sameValue(h.base.value, m.value) {
+		h.base.row, h.base.col = m.row, m.col
+		return
+	}
+	h.undo = append(h.undo, h.base)
+	if m.UndoLimit > 0 && len(h.undo) > m.UndoLimit {
+		h.undo = slices.Delete(h.undo, 0, len(h.undo)-m.UndoLimit)
+	}
+	h.redo = nil
+	h.base = m.snapshot()
+}
+
+// Undo reverts the latest edit, returning false when there is nothing to
+// undo.
+func (m *Model) Undo() bool {
+	return m.travel(&m.history.undo, &m.history.redo)
+}
+
+// Redo applies the latest edit reverted by Undo again, returning false when
+// there is nothing to redo.
+func (m *Model) Redo() bool {
+	return m.travel(&m.history.redo, &m.history.undo)
+}
+
+// travel restores the latest snapshot of a stack, saving the current value to
+// the other one.
+func (m *Model) travel(from, to *[]snapshot) bool {
+	m.commit()
+	m.history.kind = editNone
+	if len(*from) == 0 {
+		return false
+	}
+	s := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, m.history.base)
+
+	m.value = cloneValue(s.value)
+	m.row = clamp(s.row, 0, len(m.value)-1)
+	m.SetCursorColumn(s.col)
+	m.history.base = s
+	m.vimLeaveVisual()
+	m.vimClamp()
+	return true
+}
+
+// keyEditKind returns the kind of edit a key press makes.
+func (m Model) keyEditKind(msg tea.KeyPressMsg) editKind {
+	switch {
+	case key.Matches(
+		msg,
+		m.KeyMap.DeleteAfterCursor,
+		m.KeyMap.DeleteBeforeCursor,
+		m.KeyMap.DeleteCharacterBackward,
+		m.KeyMap.DeleteCharacterForward,
+		m.KeyMap.DeleteWordBackward,
+		m.KeyMap.DeleteWordForward,
+	):
+		return editDelete
+	case key.Matches(
+		msg,
+		m.KeyMap.InsertNewline,
+		m.KeyMap.LowercaseWordForward,
+		m.KeyMap.UppercaseWordForward,
+		m.KeyMap.CapitalizeWordForward,
+		m.KeyMap.TransposeCharacterBackward,
+	):
+		return editKey
+	case key.Matches(
+		msg,
+		m.KeyMap.CharacterBackward,
+		m.KeyMap.CharacterForward,
+		m.KeyMap.LineEnd,
+		m.KeyMap.LineNext,
+		m.KeyMap.LinePrevious,
+		m.KeyMap.LineStart,
+		m.KeyMap.WordBackward,
+		m.KeyMap.WordForward,
+		m.KeyMap.InputBegin,
+		m.KeyMap.InputEnd,
+	):
+		return editNone
+	}
+	if msg.Text == "" {
+		return editNone
+	}
+	for _, r := range msg.Text {
+		if !unicode.IsSpace(r) {
+			return editInsert
+		}
+	}
+	return editSpace
+}
+
+func (m *Model) snapshot() snapshot {
+	return snapshot{value: cloneValue(m.value), row: m.row, col: m.col}
+}
+
+// cloneValue returns a deep copy of the rows of a value.
+func cloneValue(value [][]any) [][]any {
+	clone := make([][]any, len(value), max(len(value), minHeight))
+	for i, row := range value {
+		clone[i] = copyInterfaceSlice(row)
+	}
+	return clone
+}
+
+// sameValue returns whether two values hold the same text and attachments.
+func sameValue(a, b [][]any) bool {
+	return slices.EqualFunc(a, b, func(x, y []any) bool {
+		return slices.Equal(x, y)
+	})
+}
@@ -0,0 +1,99 @@
+package textarea
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/attachment"
+)
+
+func newUndoModel() Model {
+	m := New()
+	m.Focus()
+	return m
+}
+
+// typeText types text into the textarea a key at a time
+func typeText(m Model, text string) Model {
+	for _, r := range text {
+		m, _ = m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	return m
+}
+
+func TestUndoGroupsWordsAndDeletions(t *testing.T) {
+	m := typeText(newUndoModel(), "one two")
+	for range 3 {
+		m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	}
+	if m.Value() != "one " {
+		t.Fatalf("unexpected value %q", m.Value())
+	}
+
+	for _, expected := range []string{"one two", "one ", ""} {
+		if !m.Undo() {
+			t.Fatalf("expected an edit to undo before %q", expected)
+		}
+		if got := m.Value(); got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	}
+	if m.Undo() {
+		t.Error("expected nothing left to undo")
+	}
+
+	if !m.Redo() || m.Value() != "one " {
+		t.Errorf("expected the first word to be redone, got %q", m.Value())
+	}
+	m = typeText(m, "x")
+	if m.Redo() {
+		t.Error("expected a new edit to clear the redo stack")
+	}
+}
+
+func TestUndoRestoresAttachments(t *testing.T) {
+	m := typeText(newUndoModel(), "see ")
+	file := &attachment.Attachment{ID: "att_1", Display: "@main.go"}
+	m.InsertAttachment(file)
+	m = typeText(m, " now")
+	m.Reset()
+	if m.Value() != "" {
+		t.Fatalf("expected the input to be cleared, got %q", m.Value())
+	}
+
+	if !m.Undo() {
+		t.Fatal("expected the clear to be undone")
+	}
+	attachments := m.GetAttachments()
+	if len(attachments) != 1 || attachments[0].ID != file.ID {
+		t.Fatalf("expected the attachment to be restored, got %v", attachments)
+	}
+	if attachments[0].StartIndex != 4 || attachments[0].EndIndex != 12 {
+		t.Errorf("expected the attachment to span 4 to 12, got %d to %d",
+			attachments[0].StartIndex, attachments[0].EndIndex)
+	}
+	if got := m.Value(); got != "see @main.go now" {
+		t.Errorf("expected the text around the attachment, got %q", got)
+	}
+}
+
+func TestUndoLimit(t *testing.T) {
+	m := newUndoModel()
+	m.UndoLimit = 2
+	for _, text := range []string{"a", "b", "c", "d"} {
+		m.InsertString(text)
+		// a cursor key ends the edit
+		m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyRight})
+	}
+
+	undone := 0
+	for m.Undo() {
+		undone++
+	}
+	if undone != 2 {
+		t.Errorf("expected 2 edits to be undone, got %d", undone)
+	}
+	if got := m.Value(); got != "ab" {
+		t.Errorf("expected the oldest edits to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,692 @@
+package textarea
+
+import (
+	"slices"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/attachment"
+)
+
+// VimMode is the editing mode of the textarea when vim mode is enabled.
+type VimMode int
+
+const (
+	VimInsert VimMode = iota
+	VimNormal
+	VimVisual
+	VimVisualLine
+)
+
+// String returns the name of the mode, as vim shows it.
+func (v VimMode) String() string {
+	switch v {
+	case VimNormal:
+		return "NORMAL"
+	case VimVisual:
+		return "VISUAL"
+	case VimVisualLine:
+		return "V-LINE"
+	}
+	return "INSERT"
+}
+
+// position is a location in the value of the textarea. The column one past
+// the end of a row stands for the line break ending it.
+type position struct {
+	row int
+	col int
+}
+
+func (p position) before(other position) bool {
+	return p.row < other.row || (p.row == other.row && p.col < other.col)
+}
+
+// register holds the text yanked or deleted by the last operator.
+type register struct {
+	text     [][]any
+	linewise bool
+}
+
+// motion is where a motion moves the cursor, and how an operator applied to
+// it treats the text in between.
+type motion struct {
+	to        position
+	linewise  bool
+	inclusive bool
+}
+
+// vimState is the state of vim mode.
+type vimState struct {
+	enabled bool
+	mode    VimMode
+
+	// count is the count typed before a command, 0 when there is none.
+	count int
+	// operator is the operator (d, c or y) waiting for a motion, with the
+	// count typed before it.
+	operator      string
+	operatorCount int
+	// prefix is the first key of a two key command, like the g of gg.
+	prefix string
+
+	// anchor is the end of the visual selection opposite to the cursor.
+	anchor   position
+	register register
+}
+
+// character classes that delimit words
+const (
+	classSpace = iota
+	classWord
+	classPunctuation
+	classAttachment
+)
+
+// SetVimEnabled turns vim mode on or off. The textarea is left in insert mode
+// either way.
+func (m *Model) SetVimEnabled(enabled bool) {
+	m.vim = vimState{enabled: enabled, register: m.vim.register}
+}
+
+// VimEnabled returns whether vim mode is on.
+func (m Model) VimEnabled() bool {
+	return m.vim.enabled
+}
+
+// VimMode returns the vim mode the textarea is in, always insert mode when vim
+// mode is off.
+func (m Model) VimMode() VimMode {
+	return m.vim.mode
+}
+
+// VimCaptures reports whether vim mode handles a key press, rather than the
+// key bindings of the textarea or of the application around it.
+func (m Model) VimCaptures(msg tea.KeyPressMsg) bool {
+	if !m.vim.enabled {
+		return false
+	}
+	key := msg.String()
+	if m.vim.mode == VimInsert {
+		return key == "esc"
+	}
+	if key == "esc" {
+		return m.vimPending() || m.vim.mode != VimNormal
+	}
+	return msg.Text != "" || key == "ctrl+r" || key == "backspace"
+}
+
+// resetVim returns vim mode to insert mode.
+func (m *Model) resetVim() {
+	if !m.vim.enabled {
+		return
+	}
+	m.SetVimEnabled(true)
+}
+
+func (m *Model) vimPending() bool {
+	return m.vim.count > 0 || m.vim.operator != "" || m.vim.prefix != ""
+}
+
+func (m *Model) vimClearPending() {
+	m.vim.count = 0
+	m.vim.operator = ""
+	m.vim.operatorCount = 0
+	m.vim.prefix = ""
+}
+
+// vimKey handles a key press in vim mode, returning false for the keys left
+// to the regular key bindings.
+func (m *Model) vimKey(msg tea.KeyPressMsg) bool {
+	key := msg.String()
+	if m.vim.mode == VimInsert {
+		if key != "esc" {
+			return false
+		}
+		m.vim.mode = VimNormal
+		m.record(editNone)
+		// like vim, the cursor steps back onto the last character typed
+		m.SetCursorColumn(m.col - 1)
+		return true
+	}
+
+	if key == "esc" {
+		m.vimClearPending()
+		m.vim.mode = VimNormal
+		return true
+	}
+	if m.vimCommand(key) {
+		return true
+	}
+	m.vimClearPending()
+	// printable keys without a command are ignored rather than typed
+	return msg.Text != ""
+}
+
+// vimCommand runs the command of a key in normal or visual mode, returning
+// false when the key has none.
+func (m *Model) vimCommand(key string) bool {
+	v := &m.vim
+	if v.prefix == "g" {
+		v.prefix = ""
+		if key != "g" {
+			m.vimClearPending()
+			return true
+		}
+		key = "gg"
+	} else if key == "g" {
+		v.prefix = key
+		return true
+	}
+
+	if len(key) == 1 && (key[0] >= '1' && key[0] <= '9' || key == "0" && v.count > 0) {
+		v.count = v.count*10 + int(key[0]-'0')
+		return true
+	}
+
+	if v.operator != "" {
+		operator := v.operator
+		count := max(1, v.operatorCount) * max(1, v.count)
+		explicit := v.operatorCount > 0 || v.count > 0
+		m.vimClearPending()
+		// doubling the operator applies it to whole lines, like dd
+		if key == operator {
+			to := position{min(m.row+count-1, len(m.value)-1), 0}
+			m.vimOperate(operator, m.cursor(), motion{to: to, linewise: true})
+			return true
+		}
+		if mo, ok := m.vimMotion(key, count, explicit, operator); ok {
+			m.vimOperate(operator, m.cursor(), mo)
+		}
+		return true
+	}
+
+	count, explicit := max(1, v.count), v.count > 0
+	v.count = 0
+
+	if v.mode == VimVisual || v.mode == VimVisualLine {
+		return m.vimVisualCommand(key, count, explicit)
+	}
+
+	row := m.value[m.row]
+	switch key {
+	case "d", "c", "y":
+		v.operator = key
+		if explicit {
+			v.operatorCount = count
+		}
+	case "x", "delete":
+		if len(row) > 0 {
+			to := position{m.row, min(m.col+count, len(row))}
+			m.vimOperate("d", m.cursor(), motion{to: to})
+		}
+	case "X":
+		m.vimOperate("d", m.cursor(), motion{to: position{m.row, max(0, m.col-count)}})
+	case "D", "C":
+		operator := "d"
+		if key == "C" {
+			operator = "c"
+		}
+		mo, _ := m.vimMotion("$", count, explicit, operator)
+		m.vimOperate(operator, m.cursor(), mo)
+	case "s":
+		to := position{m.row, min(m.col+count, len(row))}
+		m.vimOperate("c", m.cursor(), motion{to: to})
+	case "S", "Y":
+		operator := "c"
+		if key == "Y" {
+			operator = "y"
+		}
+		to := position{min(m.row+count-1, len(m.value)-1), 0}
+		m.vimOperate(operator, m.cursor(), motion{to: to, linewise: true})
+	case "p", "P":
+		m.vimPaste(key == "p", count)
+	case "i":
+		m.vimInsert()
+	case "a":
+		m.SetCursorColumn(m.col + 1)
+		m.vimInsert()
+	case "I":
+		m.SetCursorColumn(m.firstNonBlank(m.row))
+		m.vimInsert()
+	case "A":
+		m.CursorEnd()
+		m.vimInsert()
+	case "o", "O":
+		m.record(editKey)
+		at := m.row
+		if key == "o" {
+			at++
+		}
+		m.value = slices.Insert(m.value, at, []any{})
+		m.row = at
+		m.SetCursorColumn(0)
+		m.vimInsert()
+	case "v", "V":
+		v.mode = VimVisual
+		if key == "V" {
+			v.mode = VimVisualLine
+		}
+		v.anchor = m.cursor()
+	case "u":
+		for range count {
+			m.Undo()
+		}
+	case "ctrl+r":
+		for range count {
+			m.Redo()
+		}
+	default:
+		mo, ok := m.vimMotion(key, count, explicit, "")
+		if !ok {
+			return false
+		}
+		m.moveTo(mo.to)
+	}
+	return true
+}
+
+// vimVisualCommand runs the command of a key in visual mode, the operators
+// apply to the selection right away.
+func (m *Model) vimVisualCommand(key string, count int, explicit bool) bool {
+	v := &m.vim
+	switch key {
+	case "d", "x", "delete", "c", "s", "y":
+		operator := key
+		switch key {
+		case "x", "delete":
+			operator = "d"
+		case "s":
+			operator = "c"
+		}
+		linewise := v.mode == VimVisualLine
+		v.mode = VimNormal
+		m.vimOperate(operator, v.anchor, motion{to: m.cursor(), linewise: linewise, inclusive: true})
+	case "v", "V":
+		mode := VimVisual
+		if key == "V" {
+			mode = VimVisualLine
+		}
+		if v.mode == mode {
+			v.mode = VimNormal
+		} else {
+			v.mode = mode
+		}
+	case "o":
+		anchor := v.anchor
+		v.anchor = m.cursor()
+		m.moveTo(anchor)
+	default:
+		mo, ok := m.vimMotion(key, count, explicit, "")
+		if !ok {
+			return false
+		}
+		m.moveTo(mo.to)
+	}
+	return true
+}
+
+// vimMotion returns where the motion of a key moves the cursor, the operator
+// it's applied to changes how some motions behave, like vim does.
+func (m *Model) vimMotion(key string, count int, explicit bool, operator string) (motion, bool) {
+	p := m.cursor()
+	last := len(m.value) - 1
+	switch key {
+	case "h", "left", "backspace":
+		return motion{to: position{p.row, max(0, p.col-count)}}, true
+	case "l", "right", "space":
+		return motion{to: position{p.row, min(p.col+count, len(m.value[p.row]))}}, true
+	case "j", "down":
+		return motion{to: position{min(p.row+count, last), p.col}, linewise: true}, true
+	case "k", "up":
+		return motion{to: position{max(p.row-count, 0), p.col}, linewise: true}, true
+	case "0", "home":
+		return motion{to: position{p.row, 0}}, true
+	case "^":
+		return motion{to: position{p.row, m.firstNonBlank(p.row)}}, true
+	case "$", "end":
+		row := min(p.row+count-1, last)
+		return motion{to: position{row, len(m.value[row])}}, true
+	case "gg", "G":
+		row := 0
+		if key == "G" {
+			row = last
+		}
+		if explicit {
+			row = clamp(count-1, 0, last)
+		}
+		return motion{to: position{row, m.firstNonBlank(row)}, linewise: true}, true
+	case "w":
+		// cw changes to the end of the word, like ce
+		if operator == "c" && m.classAt(p) != classSpace {
+			return m.vimMotion("e", count, explicit, operator)
+		}
+		for range count {
+			p = m.wordStartForward(p)
+		}
+		// an operator doesn't carry over to the line of the next word
+		if operator != "" && p.row > m.row && m.firstNonBlank(p.row) >= p.col {
+			p = position{p.row - 1, len(m.value[p.row-1])}
+		}
+		return motion{to: p}, true
+	case "b":
+		for range count {
+			p = m.wordStartBackward(p)
+		}
+		return motion{to: p}, true
+	case "e":
+		for range count {
+			p = m.wordEndForward(p)
+		}
+		return motion{to: p, inclusive: true}, true
+	}
+	return motion{}, false
+}
+
+// vimOperate applies an operator to the text between a position and the end
+// of a motion.
+func (m *Model) vimOperate(operator string, from position, mo motion) {
+	start, end := from, mo.to
+	if end.before(start) {
+		start, end = end, start
+	}
+	if operator != "y" {
+		m.record(editKey)
+	}
+
+	if mo.linewise {
+		text := make([][]any, 0, end.row-start.row+1)
+		for _, row := range m.value[start.row : end.row+1] {
+			text = append(text, copyInterfaceSlice(row))
+		}
+		m.vim.register = register{text: text, linewise: true}
+
+		switch operator {
+		case "y":
+			m.row = start.row
+		case "d":
+			m.value = slices.Delete(m.value, start.row, end.row+1)
+			if len(m.value) == 0 {
+				m.value = append(m.value, []any{})
+			}
+			m.row = min(start.row, len(m.value)-1)
+			m.SetCursorColumn(m.firstNonBlank(m.row))
+		case "c":
+			m.value = slices.Replace(m.value, start.row, end.row+1, []any{})
+			m.row = start.row
+			m.SetCursorColumn(0)
+			m.vimInsert()
+		}
+		return
+	}
+
+	if mo.inclusive {
+		if next, ok := m.next(end); ok {
+			end = next
+		}
+	}
+	m.vim.register = register{text: m.textBetween(start, end)}
+
+	switch operator {
+	case "y":
+		m.moveTo(start)
+	case "d":
+		m.deleteBetween(start, end)
+		m.moveTo(start)
+	case "c":
+		m.deleteBetween(start, end)
+		m.moveTo(start)
+		m.vimInsert()
+	}
+}
+
+// vimPaste puts the register after or before the cursor.
+func (m *Model) vimPaste(after bool, count int) {
+	text := m.vim.register.text
+	if len(text) == 0 {
+		return
+	}
+	m.record(editKey)
+
+	if m.vim.register.linewise {
+		at := m.row
+		if after {
+			at++
+		}
+		rows := make([][]any, 0, len(text)*count)
+		for range count {
+			for _, row := range text {
+				rows = append(rows, copyInterfaceSlice(row))
+			}
+		}
+		m.value = slices.Insert(m.value, at, rows...)
+		m.row = at
+		m.SetCursorColumn(m.firstNonBlank(at))
+		return
+	}
+
+	p := m.cursor()
+	if after && len(m.value[p.row]) > 0 {
+		p.col = min(p.col+1, len(m.value[p.row]))
+	}
+	for range count {
+		p = m.insertBetween(p, text)
+	}
+	// the cursor ends on the last character put
+	m.moveTo(position{p.row, max(0, p.col-1)})
+}
+
+// vimInsert enters insert mode. What's typed until leaving it is undone along
+// with the change entering it, like the deletion of c.
+func (m *Model) vimInsert() {
+	if m.history.kind != editKey {
+		m.record(editNone)
+	}
+	m.vim.mode = VimInsert
+	m.history.kind = editInsert
+}
+
+// vimClamp keeps the cursor on a character, outside of insert mode it can't
+// be past the end of a line.
+func (m *Model) vimClamp() {
+	if !m.vim.enabled || m.vim.mode == VimInsert {
+		return
+	}
+	if length := len(m.value[m.row]); m.col >= length {
+		m.SetCursorColumn(length - 1)
+	}
+}
+
+// vimLeaveVisual returns from visual mode to normal mode. The value was
+// edited by something other than a vim command, so the anchor of the
+// selection may no longer be in it.
+func (m *Model) vimLeaveVisual() {
+	if m.vim.mode == VimVisual || m.vim.mode == VimVisualLine {
+		m.vim.mode = VimNormal
+	}
+}
+
+// vimSelected returns whether the item at a row and column is part of the
+// visual selection.
+func (m Model) vimSelected(row, col int) bool {
+	if m.vim.mode != VimVisual && m.vim.mode != VimVisualLine {
+		return false
+	}
+	start, end := m.vim.anchor, m.cursor()
+	if end.before(start) {
+		start, end = end, start
+	}
+	if row < start.row || row > end.row {
+		return false
+	}
+	if m.vim.mode == VimVisualLine {
+		return true
+	}
+	p := position{row, col}
+	return !p.before(start) && !end.before(p)
+}
+
+func (m Model) cursor() position {
+	return position{m.row, m.col}
+}
+
+func (m *Model) moveTo(p position) {
+	m.row = clamp(p.row, 0, len(m.value)-1)
+	m.SetCursorColumn(p.col)
+}
+
+// next returns the position after another, false at the end of the value.
+func (m *Model) next(p position) (position, bool) {
+	if p.col < len(m.value[p.row]) {
+		return position{p.row, p.col + 1}, true
+	}
+	if p.row < len(m.value)-1 {
+		return position{p.row + 1, 0}, true
+	}
+	return p, false
+}
+
+// previous returns the position before another, false at the start of the
+// value.
+func (m *Model) previous(p position) (position, bool) {
+	if p.col > 0 {
+		return position{p.row, p.col - 1}, true
+	}
+	if p.row > 0 {
+		return position{p.row - 1, len(m.value[p.row-1])}, true
+	}
+	return p, false
+}
+
+// classAt returns the character class of the item at a position, line breaks
+// are spaces.
+func (m *Model) classAt(p position) int {
+	row := m.value[p.row]
+	if p.col >= len(row) {
+		return classSpace
+	}
+	switch item := row[p.col].(type) {
+	case rune:
+		switch {
+		case unicode.IsSpace(item):
+			return classSpace
+		case item == '_' || unicode.IsLetter(item) || unicode.IsDigit(item):
+			return classWord
+		}
+		return classPunctuation
+	case *attachment.Attachment:
+		return classAttachment
+	}
+	return classSpace
+}
+
+// isEmptyLine returns whether a position is the start of an empty line, which
+// the word motions stop on.
+func (m *Model) isEmptyLine(p position) bool {
+	return p.col == 0 && len(m.value[p.row]) == 0
+}
+
+// wordStartForward returns the start of the word after a position.
+func (m *Model) wordStartForward(p position) position {
+	start := p
+	if class := m.classAt(p); class != classSpace {
+		for m.classAt(p) == class {
+			next, ok := m.next(p)
+			if !ok {
+				return next
+			}
+			p = next
+		}
+	}
+	for m.classAt(p) == classSpace {
+		if p != start && m.isEmptyLine(p) {
+			return p
+		}
+		next, ok := m.next(p)
+		if !ok {
+			return next
+		}
+		p = next
+	}
+	return p
+}
+
+// wordEndForward returns the end of the word after a position.
+func (m *Model) wordEndForward(p position) position {
+	p, ok := m.next(p)
+	for ok && m.classAt(p) == classSpace {
+		p, ok = m.next(p)
+	}
+	class := m.classAt(p)
+	for {
+		next, ok := m.next(p)
+		if !ok || m.classAt(next) != class {
+			return p
+		}
+		p = next
+	}
+}
+
+// wordStartBackward returns the start of the word before a position.
+func (m *Model) wordStartBackward(p position) position {
+	p, ok := m.previous(p)
+	for ok && m.classAt(p) == classSpace && !m.isEmptyLine(p) {
+		p, ok = m.previous(p)
+	}
+	if m.isEmptyLine(p) {
+		return p
+	}
+	class := m.classAt(p)
+	for {
+		previous, ok := m.previous(p)
+		if !ok || m.classAt(previous) != class {
+			return p
+		}
+		p = previous
+	}
+}
+
+// firstNonBlank returns the column of the first character of a row that
+// isn't a space.
+func (m *Model) firstNonBlank(row int) int {
+	for col := range m.value[row] {
+		if !isSpaceAt(m.value[row], col) {
+			return col
+		}
+	}
+	return max(0, len(m.value[row])-1)
+}
+
+// textBetween returns a copy of the text between two positions, by row.
+func (m *Model) textBetween(start, end position) [][]any {
+	if start.row == end.row {
+		return [][]any{copyInterfaceSlice(m.value[start.row][start.col:end.col])}
+	}
+	text := [][]any{copyInterfaceSlice(m.value[start.row][start.col:])}
+	for _, row := range m.value[start.row+1 : end.row] {
+		text = append(text, copyInterfaceSlice(row))
+	}
+	return append(text, copyInterfaceSlice(m.value[end.row][:end.col]))
+}
+
+// deleteBetween deletes the text between two positions, joining their rows.
+func (m *Model) deleteBetween(start, end position) {
+	row := copyInterfaceSlice(m.value[start.row][:start.col])
+	row = append(row, m.value[end.row][end.col:]...)
+	m.value = slices.Replace(m.value, start.row, end.row+1, row)
+}
+
+// insertBetween inserts text at a position, returning the position after it.
+func (m *Model) insertBetween(p position, text [][]any) position {
+	line := m.value[p.row]
+	rows := make([][]any, len(text))
+	for i, row := range text {
+		rows[i] = copyInterfaceSlice(row)
+	}
+	rows[0] = append(copyInterfaceSlice(line[:p.col]), rows[0]...)
+	last := len(rows) - 1
+	end := position{p.row + last, len(rows[last])}
+	rows[last] = append(rows[last], line[p.col:]...)
+	m.value = slices.Replace(m.value, p.row, p.row+1, rows...)
+	return end
+}
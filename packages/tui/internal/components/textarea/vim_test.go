@@ -0,0 +1,109 @@
+package textarea
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// newVimModel returns a focused textarea in vim normal mode holding a value,
+// with the cursor at its start
+func newVimModel(value string) Model {
+	m := New()
+	m.Focus()
+	m.SetVimEnabled(true)
+	m.SetValue(value)
+	m = press(m, "esc")
+	m.moveTo(position{0, 0})
+	return m
+}
+
+// press sends key presses to the textarea, printable keys are typed
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		var msg tea.KeyPressMsg
+		switch k {
+		case "esc":
+			msg = tea.KeyPressMsg{Code: tea.KeyEscape}
+		case "enter":
+			msg = tea.KeyPressMsg{Code: tea.KeyEnter}
+		case "ctrl+r":
+			msg = tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl}
+		default:
+			msg = tea.KeyPressMsg{Code: []rune(k)[0], Text: k}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func TestVimOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		keys     []string
+		expected string
+	}{
+		{"delete word", "one two three", []string{"d", "w"}, "two three"},
+		{"delete words with a count", "one two three", []string{"2", "d", "w"}, "three"},
+		{"delete line", "one\ntwo\nthree", []string{"j", "d", "d"}, "one\nthree"},
+		{"delete to end of line", "one two", []string{"w", "D"}, "one "},
+		{"delete character", "one", []string{"x", "x"}, "e"},
+		{"change word", "one two", []string{"c", "w", "s", "i", "x", "esc"}, "six two"},
+		{"yank and put line", "one\ntwo", []string{"y", "y", "j", "p"}, "one\ntwo\none"},
+		{"open line below", "one", []string{"o", "t", "w", "o", "esc"}, "one\ntwo"},
+		{"delete to last line", "one\ntwo\nthree", []string{"j", "d", "G"}, "one"},
+		{"delete to first line", "one\ntwo\nthree", []string{"j", "d", "g", "g"}, "three"},
+		{"undo and redo", "one two", []string{"d", "w", "x", "u", "u", "ctrl+r"}, "two"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := press(newVimModel(test.value), test.keys...)
+			if got := m.Value(); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestVimVisualMode(t *testing.T) {
+	m := press(newVimModel("one two three"), "w", "v", "e", "d")
+	if got := m.Value(); got != "one  three" {
+		t.Errorf("expected the selection to be deleted, got %q", got)
+	}
+	if m.VimMode() != VimNormal {
+		t.Errorf("expected normal mode after the operator, got %s", m.VimMode())
+	}
+
+	m = press(newVimModel("one\ntwo\nthree"), "j", "V", "j", "y", "g", "g", "P")
+	if got := m.Value(); got != "two\nthree\none\ntwo\nthree" {
+		t.Errorf("expected the selected lines to be put, got %q", got)
+	}
+
+	m = press(newVimModel("one two"), "v", "e", "o")
+	if m.cursor() != (position{0, 0}) || m.vim.anchor != (position{0, 2}) {
+		t.Errorf("expected o to swap the cursor and anchor, got %v and %v", m.cursor(), m.vim.anchor)
+	}
+}
+
+func TestVimVisualModeEndsOnOtherEdits(t *testing.T) {
+	// undoing from outside vim mode, like ctrl+z in the editor
+	m := press(newVimModel(""), "i", "o", "n", "e", "esc", "v")
+	m.Undo()
+	m = press(m, "d")
+	if m.VimMode() != VimNormal || m.Value() != "" {
+		t.Errorf("expected the undo to leave visual mode, got %s with %q", m.VimMode(), m.Value())
+	}
+
+	// a newline from the key bindings of the textarea
+	m = press(newVimModel(""), "i", "u", "r", "esc", "v", "G", "enter", "d")
+	if m.VimMode() != VimNormal {
+		t.Errorf("expected the newline to leave visual mode, got %s", m.VimMode())
+	}
+
+	m = press(newVimModel("one"), "v")
+	m.InsertString("two ")
+	if m.VimMode() != VimNormal {
+		t.Errorf("expected the insert to leave visual mode, got %s", m.VimMode())
+	}
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatJSON     Format = "json"
+	FormatHTML     Format = "html"
+)
+
+var Formats = []Format{FormatMarkdown, FormatJSON, FormatHTML}
+
+// ParseFormat accepts a format name or its file extension
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(value, ".")) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "json":
+		return FormatJSON, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	}
+	return "", fmt.Errorf("unknown export format %q, expected markdown, json or html", value)
+}
+
+func (f Format) Extension() string {
+	switch f {
+	case FormatMarkdown:
+		return "md"
+	case FormatJSON:
+		return "json"
+	case FormatHTML:
+		return "html"
+	}
+	return "txt"
+}
+
+func (f Format) Title() string {
+	switch f {
+	case FormatMarkdown:
+		return "Markdown"
+	case FormatJSON:
+		return "JSON"
+	case FormatHTML:
+		return "HTML"
+	}
+	return string(f)
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// DefaultPath returns the file name used when no destination is given, based
+// on the session title and relative to the working directory
+func DefaultPath(session *opencode.Session, format Format) string {
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(session.Title), "-"), "-")
+	if len(slug) > 48 {
+		slug = strings.TrimRight(slug[:48], "-")
+	}
+	if slug == "" {
+		slug = "session"
+	}
+	return slug + "." + format.Extension()
+}
+
+// Render produces the contents of the export for the session
+func Render(
+	ctx context.Context,
+	a *app.App,
+	session *opencode.Session,
+	messages []app.Message,
+	format Format,
+) ([]byte, error) {
+	switch format {
+	case FormatMarkdown:
+		return []byte(Markdown(session, messages)), nil
+	case FormatHTML:
+		return HTML(session, messages)
+	case FormatJSON:
+		return JSON(ctx, a.Client, session.ID)
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// Write renders the session in the given format and writes it to path,
+// relative paths resolve against the working directory. The absolute path of
+// the written file is returned.
+func Write(
+	ctx context.Context,
+	a *app.App,
+	session *opencode.Session,
+	messages []app.Message,
+	format Format,
+	path string,
+) (string, error) {
+	if path == "" {
+		path = DefaultPath(session, format)
+	}
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(a.Info.Path.Cwd, path)
+	}
+
+	content, err := Render(ctx, a, session, messages, format)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// JSON fetches the messages of the session and returns the response exactly as
+// sent by the server, indented for readability
+func JSON(ctx context.Context, client *opencode.Client, sessionID string) ([]byte, error) {
+	response, err := client.Session.Messages(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	raw := []string{}
+	if response != nil {
+		for _, message := range *response {
+			raw = append(raw, message.JSON.RawJSON())
+		}
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte("["+strings.Join(raw, ",")+"]"), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
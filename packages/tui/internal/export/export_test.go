@@ -0,0 +1,97 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]Format{
+		"md":       FormatMarkdown,
+		"Markdown": FormatMarkdown,
+		".json":    FormatJSON,
+		"htm":      FormatHTML,
+	}
+	for input, expected := range tests {
+		format, err := ParseFormat(input)
+		if err != nil || format != expected {
+			t.Errorf("ParseFormat(%q) = %q, %v; expected %q", input, format, err, expected)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	session := &opencode.Session{Title: "Fix the: flaky *tests*!"}
+	if path := DefaultPath(session, FormatHTML); path != "fix-the-flaky-tests.html" {
+		t.Errorf("unexpected default path %q", path)
+	}
+	if path := DefaultPath(&opencode.Session{}, FormatJSON); path != "session.json" {
+		t.Errorf("unexpected default path for untitled session %q", path)
+	}
+}
+
+func TestMarkdownIncludesToolCalls(t *testing.T) {
+	session := &opencode.Session{ID: "ses_1", Title: "Export"}
+	messages := []app.Message{
+		{
+			Info: opencode.UserMessage{ID: "msg_1"},
+			Parts: []opencode.PartUnion{
+				opencode.TextPart{Text: "Rename the function"},
+				opencode.FilePart{Filename: "main.go", Mime: "text/plain"},
+			},
+		},
+		{
+			Info: opencode.AssistantMessage{ID: "msg_2", ProviderID: "anthropic", ModelID: "claude"},
+			Parts: []opencode.PartUnion{
+				opencode.ToolPart{
+					Tool: "edit",
+					State: opencode.ToolPartState{
+						Status:   opencode.ToolPartStateStatusCompleted,
+						Title:    "main.go",
+						Input:    map[string]any{"filePath": "main.go"},
+						Metadata: map[string]any{"diff": "-old\n+new\n"},
+					},
+				},
+				opencode.ToolPart{
+					Tool: "bash",
+					State: opencode.ToolPartState{
+						Status: opencode.ToolPartStateStatusCompleted,
+						Output: "```\nnested fence\n```",
+					},
+				},
+				opencode.PartPatchPart{Files: []string{"main.go"}},
+			},
+		},
+	}
+
+	markdown := Markdown(session, messages)
+	for _, expected := range []string{
+		"# Export",
+		"Rename the function",
+		"**File:** `main.go` (text/plain)",
+		"## Assistant",
+		"anthropic/claude",
+		"**Tool:** `edit` main.go",
+		"```diff\n-old\n+new\n```",
+		"````\n```\nnested fence\n```\n````",
+		"**Patch:** changed 1 file",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", expected, markdown)
+		}
+	}
+
+	html, err := HTML(session, messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(html), "<title>Export</title>") || !strings.Contains(string(html), "Rename the function") {
+		t.Errorf("expected a standalone html page, got:\n%s", html)
+	}
+}
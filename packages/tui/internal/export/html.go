@@ -0,0 +1,66 @@
+package export
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var htmlTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+  :root { color-scheme: light dark; }
+  body { max-width: 860px; margin: 2rem auto; padding: 0 1rem; font: 15px/1.6 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; }
+  h1 { font-size: 1.6rem; }
+  h2 { font-size: 1.1rem; margin-top: 2rem; }
+  hr { border: 0; border-top: 1px solid #8884; margin: 2rem 0; }
+  pre { padding: 0.75rem 1rem; overflow-x: auto; border-radius: 6px; background: #8881; font-size: 13px; line-height: 1.45; }
+  code { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+  :not(pre) > code { padding: 0.1rem 0.3rem; border-radius: 4px; background: #8882; }
+  blockquote { margin: 0; padding-left: 1rem; border-left: 3px solid #d44; }
+  table { border-collapse: collapse; }
+  th, td { border: 1px solid #8884; padding: 0.25rem 0.5rem; }
+</style>
+</head>
+<body>
+{{.Body}}
+</body>
+</html>
+`))
+
+// HTML renders the conversation as a standalone page, the markdown export is
+// converted so both formats carry the same content
+func HTML(session *opencode.Session, messages []app.Message) ([]byte, error) {
+	markdown := goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	var body bytes.Buffer
+	if err := markdown.Convert([]byte(Markdown(session, messages)), &body); err != nil {
+		return nil, err
+	}
+
+	title := session.Title
+	if title == "" {
+		title = "Conversation History"
+	}
+
+	var out bytes.Buffer
+	err := htmlTemplate.Execute(&out, struct {
+		Title string
+		Body  template.HTML
+	}{
+		Title: title,
+		Body:  template.HTML(body.String()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
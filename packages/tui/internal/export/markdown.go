@@ -0,0 +1,143 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+const timestampFormat = "2006-01-02 15:04:05"
+
+// Markdown renders the conversation including tool calls, patches and file
+// attachments
+func Markdown(session *opencode.Session, messages []app.Message) string {
+	var builder strings.Builder
+
+	title := session.Title
+	if title == "" {
+		title = "Conversation History"
+	}
+	builder.WriteString("# " + title + "\n\n")
+	if session.ID != "" {
+		builder.WriteString(fmt.Sprintf("- Session: `%s`\n", session.ID))
+	}
+	if session.Share.URL != "" {
+		builder.WriteString(fmt.Sprintf("- Shared: %s\n", session.Share.URL))
+	}
+	builder.WriteString(fmt.Sprintf("- Exported: %s\n\n", time.Now().Format(timestampFormat)))
+
+	for _, message := range messages {
+		switch info := message.Info.(type) {
+		case opencode.UserMessage:
+			timestamp := time.UnixMilli(int64(info.Time.Created))
+			builder.WriteString("---\n\n")
+			builder.WriteString(fmt.Sprintf("## User (*%s*)\n\n", timestamp.Format(timestampFormat)))
+		case opencode.AssistantMessage:
+			timestamp := time.UnixMilli(int64(info.Time.Created))
+			builder.WriteString("---\n\n")
+			builder.WriteString(fmt.Sprintf(
+				"## Assistant (*%s*, %s/%s)\n\n",
+				timestamp.Format(timestampFormat),
+				info.ProviderID,
+				info.ModelID,
+			))
+		default:
+			continue
+		}
+
+		for _, part := range message.Parts {
+			switch p := part.(type) {
+			case opencode.TextPart:
+				if p.Synthetic || strings.TrimSpace(p.Text) == "" {
+					continue
+				}
+				builder.WriteString(strings.TrimSpace(p.Text) + "\n\n")
+			case opencode.FilePart:
+				name := p.Filename
+				if name == "" {
+					name = p.URL
+				}
+				builder.WriteString(fmt.Sprintf("**File:** `%s` (%s)\n\n", name, p.Mime))
+			case opencode.ToolPart:
+				writeTool(&builder, p)
+			case opencode.PartPatchPart:
+				if len(p.Files) == 0 {
+					continue
+				}
+				builder.WriteString("**Patch:** changed " + pluralize(len(p.Files), "file") + "\n\n")
+				for _, file := range p.Files {
+					builder.WriteString(fmt.Sprintf("- `%s`\n", file))
+				}
+				builder.WriteString("\n")
+			}
+		}
+
+		if info, ok := message.Info.(opencode.AssistantMessage); ok && info.Error.Name != "" {
+			builder.WriteString(fmt.Sprintf("> **Error:** %s\n\n", info.Error.Name))
+		}
+	}
+
+	return builder.String()
+}
+
+func writeTool(builder *strings.Builder, part opencode.ToolPart) {
+	heading := fmt.Sprintf("**Tool:** `%s`", part.Tool)
+	if part.State.Title != "" {
+		heading += " " + part.State.Title
+	}
+	if part.State.Status != opencode.ToolPartStateStatusCompleted {
+		heading += fmt.Sprintf(" (%s)", part.State.Status)
+	}
+	builder.WriteString(heading + "\n\n")
+
+	if input, ok := part.State.Input.(map[string]any); ok && len(input) > 0 {
+		if data, err := json.MarshalIndent(input, "", "  "); err == nil {
+			builder.WriteString(codeBlock("json", string(data)))
+		}
+	}
+
+	if metadata, ok := part.State.Metadata.(map[string]any); ok {
+		if diff, ok := metadata["diff"].(string); ok && strings.TrimSpace(diff) != "" {
+			builder.WriteString(codeBlock("diff", diff))
+			return
+		}
+	}
+
+	switch part.State.Status {
+	case opencode.ToolPartStateStatusCompleted:
+		if strings.TrimSpace(part.State.Output) != "" {
+			builder.WriteString(codeBlock("", part.State.Output))
+		}
+	case opencode.ToolPartStateStatusError:
+		builder.WriteString(fmt.Sprintf("> **Error:** %s\n\n", part.State.Error))
+	}
+}
+
+// codeBlock fences the content with enough backticks that any fence inside
+// the content can't terminate the block early
+func codeBlock(language string, content string) string {
+	longest := 0
+	current := 0
+	for _, r := range content {
+		if r == '`' {
+			current++
+			longest = max(longest, current)
+			continue
+		}
+		current = 0
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	content = strings.TrimRight(content, "\n")
+	return fence + language + "\n" + content + "\n" + fence + "\n\n"
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
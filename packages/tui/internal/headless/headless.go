@@ -0,0 +1,260 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+func (f Format) IsKnown() bool {
+	switch f {
+	case FormatText, FormatJSON:
+		return true
+	}
+	return false
+}
+
+// runner streams the output of a single prompt to a writer
+type runner struct {
+	app     *app.App
+	out     io.Writer
+	format  Format
+	prompt  string
+	printed map[string]int
+	lastID  string
+}
+
+// Run sends the prompt without starting the TUI, in the session opened in
+// the app or in a new one when there is none. It writes the assistant output
+// to out as it streams in and returns once the session is idle. An error is
+// returned if the prompt could not be sent or the session reports an error.
+func Run(
+	ctx context.Context,
+	a *app.App,
+	prompt string,
+	format Format,
+	out io.Writer,
+) error {
+	if strings.TrimSpace(prompt) == "" {
+		return errors.New("a prompt is required, pass --prompt or pipe it to stdin")
+	}
+
+	provider, model, err := a.ResolveModel(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve model: %w", err)
+	}
+	a.Provider = provider
+	a.Model = model
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// subscribe before sending the prompt so no events are missed
+	stream := a.Client.Event.ListStreaming(ctx)
+	defer stream.Close()
+
+	r := &runner{
+		app:     a,
+		out:     out,
+		format:  format,
+		printed: make(map[string]int),
+	}
+
+	_, cmd, err := a.SendPrompt(ctx, app.Prompt{Text: prompt})
+	if err != nil {
+		return fmt.Errorf("failed to send prompt: %w", err)
+	}
+	r.prompt = a.Messages[len(a.Messages)-1].Info.(opencode.UserMessage).ID
+
+	// the chat request only returns once the response is complete, so it runs
+	// alongside the event loop
+	sent := make(chan error, 1)
+	go func() {
+		sent <- runCmd(cmd)
+	}()
+
+	events := make(chan opencode.EventListResponse)
+	go func() {
+		defer close(events)
+		for stream.Next() {
+			select {
+			case events <- stream.Current():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case err := <-sent:
+			if err != nil {
+				r.finish()
+				return err
+			}
+			sent = nil
+		case event, ok := <-events:
+			if !ok {
+				if err := stream.Err(); err != nil {
+					return fmt.Errorf("event stream closed: %w", err)
+				}
+				return errors.New("event stream closed before the session finished")
+			}
+			done, err := r.handle(event)
+			if err != nil || done {
+				r.finish()
+				return err
+			}
+		}
+	}
+}
+
+// handle processes a single event, reporting whether the session is done
+func (r *runner) handle(event opencode.EventListResponse) (bool, error) {
+	sessionID := r.app.Session.ID
+
+	switch evt := event.AsUnion().(type) {
+	case opencode.EventListResponseEventMessagePartUpdated:
+		part := evt.Properties.Part
+		if part.SessionID != sessionID || part.MessageID == r.prompt {
+			return false, nil
+		}
+		if r.format == FormatJSON {
+			return false, r.writeJSON(event)
+		}
+		switch casted := part.AsUnion().(type) {
+		case opencode.TextPart:
+			r.writeText(casted)
+		case opencode.ToolPart:
+			r.writeTool(casted)
+		}
+	case opencode.EventListResponseEventSessionIdle:
+		if evt.Properties.SessionID != sessionID {
+			return false, nil
+		}
+		if r.format == FormatJSON {
+			return true, r.writeJSON(event)
+		}
+		return true, nil
+	case opencode.EventListResponseEventSessionError:
+		if evt.Properties.SessionID != "" && evt.Properties.SessionID != sessionID {
+			return false, nil
+		}
+		if r.format == FormatJSON {
+			if err := r.writeJSON(event); err != nil {
+				return true, err
+			}
+		}
+		return true, sessionError(evt.Properties.Error)
+	}
+	return false, nil
+}
+
+// writeText prints the portion of a text part that hasn't been written yet,
+// text parts are resent in full every time they grow
+func (r *runner) writeText(part opencode.TextPart) {
+	if part.Synthetic {
+		return
+	}
+	printed := r.printed[part.ID]
+	if len(part.Text) <= printed {
+		return
+	}
+	r.separate(part.ID)
+	fmt.Fprint(r.out, part.Text[printed:])
+	r.printed[part.ID] = len(part.Text)
+}
+
+// writeTool prints a single line for each tool call once it has finished
+func (r *runner) writeTool(part opencode.ToolPart) {
+	if _, ok := r.printed[part.ID]; ok {
+		return
+	}
+	switch part.State.Status {
+	case opencode.ToolPartStateStatusCompleted:
+		title := part.State.Title
+		if title == "" {
+			title = part.Tool
+		}
+		r.separate(part.ID)
+		fmt.Fprintf(r.out, "[%s] %s\n", part.Tool, title)
+	case opencode.ToolPartStateStatusError:
+		r.separate(part.ID)
+		fmt.Fprintf(r.out, "[%s] error: %s\n", part.Tool, part.State.Error)
+	default:
+		return
+	}
+	r.printed[part.ID] = 0
+}
+
+// separate starts a new line when output moves on to a different part
+func (r *runner) separate(partID string) {
+	if r.lastID != "" && r.lastID != partID {
+		if r.printed[r.lastID] > 0 {
+			fmt.Fprint(r.out, "\n\n")
+		}
+	}
+	r.lastID = partID
+}
+
+func (r *runner) finish() {
+	if r.format == FormatText && r.printed[r.lastID] > 0 {
+		fmt.Fprintln(r.out)
+	}
+}
+
+func (r *runner) writeJSON(event opencode.EventListResponse) error {
+	_, err := fmt.Fprintln(r.out, event.JSON.RawJSON())
+	return err
+}
+
+func sessionError(sessionErr opencode.EventListResponseEventSessionErrorPropertiesError) error {
+	switch err := sessionErr.AsUnion().(type) {
+	case opencode.ProviderAuthError:
+		return fmt.Errorf("provider error: %s", err.Data.Message)
+	case opencode.UnknownError:
+		return errors.New(err.Data.Message)
+	}
+	data, _ := json.Marshal(sessionErr.Data)
+	if len(data) == 0 || string(data) == "null" {
+		return errors.New(string(sessionErr.Name))
+	}
+	return fmt.Errorf("%s: %s", sessionErr.Name, data)
+}
+
+// runCmd executes a command synchronously, including any batched commands,
+// and turns toasts into errors since they are the only way commands report
+// failures
+func runCmd(cmd tea.Cmd) error {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		var errs []error
+		for _, cmd := range msg {
+			errs = append(errs, runCmd(cmd))
+		}
+		return errors.Join(errs...)
+	case toast.ShowToastMsg:
+		slog.Error("headless run failed", "error", msg.Message)
+		return errors.New(msg.Message)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package headless
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+func newTestRunner(format Format) (*runner, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return &runner{
+		app:     &app.App{Session: &opencode.Session{ID: "ses_1"}},
+		out:     out,
+		format:  format,
+		prompt:  "msg_user",
+		printed: make(map[string]int),
+	}, out
+}
+
+func mustEvent(t *testing.T, raw string) opencode.EventListResponse {
+	t.Helper()
+	var event opencode.EventListResponse
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	return event
+}
+
+func textEvent(t *testing.T, messageID, partID, text string) opencode.EventListResponse {
+	return mustEvent(t, `{"type":"message.part.updated","properties":{"part":{"id":"`+partID+`","messageID":"`+messageID+`","sessionID":"ses_1","type":"text","text":"`+text+`"}}}`)
+}
+
+func TestRunnerStreamsTextDeltas(t *testing.T) {
+	r, out := newTestRunner(FormatText)
+
+	events := []opencode.EventListResponse{
+		textEvent(t, "msg_user", "prt_user", "ignored prompt"),
+		textEvent(t, "msg_1", "prt_1", "Hello"),
+		textEvent(t, "msg_1", "prt_1", "Hello, world"),
+		mustEvent(t, `{"type":"session.idle","properties":{"sessionID":"ses_1"}}`),
+	}
+
+	for i, event := range events {
+		done, err := r.handle(event)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if done != (i == len(events)-1) {
+			t.Fatalf("event %d: expected done=%v, got %v", i, i == len(events)-1, done)
+		}
+	}
+	r.finish()
+
+	if got := out.String(); got != "Hello, world\n" {
+		t.Errorf("expected streamed text, got %q", got)
+	}
+}
+
+func TestRunnerSessionError(t *testing.T) {
+	r, out := newTestRunner(FormatJSON)
+
+	raw := `{"type":"session.error","properties":{"sessionID":"ses_1","error":{"name":"UnknownError","data":{"message":"boom"}}}}`
+	done, err := r.handle(mustEvent(t, raw))
+	if !done {
+		t.Fatal("expected session error to finish the run")
+	}
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("expected error %q, got %v", "boom", err)
+	}
+	if out.String() != raw+"\n" {
+		t.Errorf("expected the raw event as a JSON line, got %q", out.String())
+	}
+}
+
+func TestRunnerIgnoresOtherSessions(t *testing.T) {
+	r, out := newTestRunner(FormatText)
+
+	done, err := r.handle(mustEvent(t, `{"type":"session.idle","properties":{"sessionID":"ses_2"}}`))
+	if done || err != nil {
+		t.Fatalf("expected other sessions to be ignored, got done=%v err=%v", done, err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no output, got %q", out.String())
+	}
+}
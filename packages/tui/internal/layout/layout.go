@@ -30,3 +30,9 @@ type Modal interface {
 	Render(background string) string
 	Close() tea.Cmd
 }
+
+// KeyCapturer is a modal that takes every key press while it's capturing,
+// esc and ctrl+c included, e.g. to record a key combination
+type KeyCapturer interface {
+	CapturesKeys() bool
+}
@@ -0,0 +1,154 @@
+package templates
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Template is a reusable prompt loaded from a markdown file, the file name
+// without extension is the name of the template
+type Template struct {
+	Name        string
+	Description string
+	Content     string
+	Path        string
+}
+
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// LoadFromDirectories loads templates from user directories in the correct
+// override order, a template overrides those of the same name loaded before.
+// The hierarchy is (from lowest to highest priority):
+// 1. USER_CONFIG/opencode/templates/*.md
+// 2. PROJECT_ROOT/.opencode/templates/*.md
+// 3. CWD/.opencode/templates/*.md
+func LoadFromDirectories(userConfig, projectRoot, cwd string) []Template {
+	dirs := []string{
+		filepath.Join(userConfig, "templates"),
+		filepath.Join(projectRoot, ".opencode", "templates"),
+	}
+	if cwd != projectRoot {
+		dirs = append(dirs, filepath.Join(cwd, ".opencode", "templates"))
+	}
+
+	loaded := map[string]Template{}
+	for _, dir := range dirs {
+		templates, err := loadFromDirectory(dir)
+		if err != nil {
+			slog.Warn("Failed to load templates", "dir", dir, "error", err)
+			continue
+		}
+		for _, template := range templates {
+			loaded[template.Name] = template
+		}
+	}
+
+	templates := make([]Template, 0, len(loaded))
+	for _, template := range loaded {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+func loadFromDirectory(dir string) ([]Template, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil // Directory doesn't exist, which is fine
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	templates := []Template{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+
+		filePath := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			slog.Warn("Failed to read template file", "file", filePath, "error", err)
+			continue
+		}
+
+		templates = append(templates, Parse(strings.TrimSuffix(entry.Name(), ".md"), string(data), filePath))
+	}
+	return templates, nil
+}
+
+// Parse builds a template from the contents of its file. An optional front
+// matter block may set the description, otherwise the first line of the
+// template is used.
+func Parse(name string, data string, path string) Template {
+	template := Template{Name: name, Path: path}
+	content := strings.ReplaceAll(data, "\r\n", "\n")
+
+	if rest, ok := strings.CutPrefix(content, "---\n"); ok {
+		if frontMatter, body, ok := strings.Cut(rest, "\n---\n"); ok {
+			content = body
+			for _, line := range strings.Split(frontMatter, "\n") {
+				key, value, ok := strings.Cut(line, ":")
+				if ok && strings.TrimSpace(key) == "description" {
+					template.Description = strings.Trim(strings.TrimSpace(value), `"'`)
+				}
+			}
+		}
+	}
+
+	template.Content = strings.TrimSpace(content)
+	if template.Description == "" {
+		for _, line := range strings.Split(template.Content, "\n") {
+			if line = strings.TrimSpace(strings.TrimLeft(line, "# ")); line != "" {
+				template.Description = line
+				break
+			}
+		}
+	}
+	return template
+}
+
+// Variables returns the names of the {{placeholder}} variables of the
+// template in the order they first appear
+func (t Template) Variables() []string {
+	variables := []string{}
+	for _, match := range variablePattern.FindAllStringSubmatch(t.Content, -1) {
+		if !slices.Contains(variables, match[1]) {
+			variables = append(variables, match[1])
+		}
+	}
+	return variables
+}
+
+// Render replaces the variables of the template with the given values,
+// variables without a value are left as is
+func (t Template) Render(values map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(t.Content, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// Find returns the template with the given name
+func Find(templates []Template, name string) (Template, bool) {
+	index := slices.IndexFunc(templates, func(t Template) bool {
+		return t.Name == name
+	})
+	if index == -1 {
+		return Template{}, false
+	}
+	return templates[index], true
+}
@@ -0,0 +1,68 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	template := Parse("review", "---\ndescription: \"Review a file\"\n---\nReview @{{file}}\n", "")
+
+	if template.Description != "Review a file" {
+		t.Errorf("expected the description from the front matter, got %q", template.Description)
+	}
+	if template.Content != "Review @{{file}}" {
+		t.Errorf("expected the front matter to be stripped, got %q", template.Content)
+	}
+}
+
+func TestParseDescriptionFromFirstLine(t *testing.T) {
+	template := Parse("fix", "\n# Fix the failing test\n\nRun {{ command }} first", "")
+
+	if template.Description != "Fix the failing test" {
+		t.Errorf("expected the first line as description, got %q", template.Description)
+	}
+}
+
+func TestVariablesAndRender(t *testing.T) {
+	template := Template{Content: "Port {{ file }} to {{lang}}, keep {{file}} tested. {{missing}}"}
+
+	variables := template.Variables()
+	if !reflect.DeepEqual(variables, []string{"file", "lang", "missing"}) {
+		t.Errorf("unexpected variables %v", variables)
+	}
+
+	rendered := template.Render(map[string]string{"file": "main.go", "lang": "rust"})
+	expected := "Port main.go to rust, keep main.go tested. {{missing}}"
+	if rendered != expected {
+		t.Errorf("expected %q, got %q", expected, rendered)
+	}
+}
+
+func TestLoadFromDirectoriesOverrides(t *testing.T) {
+	config := t.TempDir()
+	root := t.TempDir()
+	write := func(dir string, name string, content string) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(config, "templates"), "review.md", "global review")
+	write(filepath.Join(config, "templates"), "explain.md", "explain this")
+	write(filepath.Join(root, ".opencode", "templates"), "review.md", "project review")
+	write(filepath.Join(root, ".opencode", "templates"), "notes.txt", "ignored")
+
+	templates := LoadFromDirectories(config, root, root)
+	if len(templates) != 2 {
+		t.Fatalf("expected 2 templates, got %d", len(templates))
+	}
+	review, ok := Find(templates, "review")
+	if !ok || review.Content != "project review" {
+		t.Errorf("expected the project template to override the global one, got %q", review.Content)
+	}
+}
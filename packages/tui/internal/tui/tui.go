@@ -21,6 +21,7 @@ import (
 	"github.com/sst/opencode/internal/app"
 	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/completions"
+	"github.com/sst/opencode/internal/components/changes"
 	"github.com/sst/opencode/internal/components/chat"
 	cmdcomp "github.com/sst/opencode/internal/components/commands"
 	"github.com/sst/opencode/internal/components/dialog"
@@ -28,8 +29,10 @@ import (
 	"github.com/sst/opencode/internal/components/modal"
 	"github.com/sst/opencode/internal/components/status"
 	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/export"
 	"github.com/sst/opencode/internal/layout"
 	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/templates"
 	"github.com/sst/opencode/internal/theme"
 	"github.com/sst/opencode/internal/util"
 )
@@ -40,6 +43,12 @@ type InterruptDebounceTimeoutMsg struct{}
 // ExitDebounceTimeoutMsg is sent when the exit key debounce timeout expires
 type ExitDebounceTimeoutMsg struct{}
 
+// KeySequenceTimeoutMsg is sent when no key followed the keys of a pending key
+// sequence in time, ID tells the pending sequences apart
+type KeySequenceTimeoutMsg struct {
+	ID int
+}
+
 // InterruptKeyState tracks the state of interrupt key presses for debouncing
 type InterruptKeyState int
 
@@ -63,6 +72,7 @@ type Model struct {
 	width, height        int
 	app                  *app.App
 	modal                layout.Modal
+	permissions          dialog.PermissionDialog
 	status               status.StatusComponent
 	editor               chat.EditorComponent
 	messages             chat.MessagesComponent
@@ -75,8 +85,10 @@ type Model struct {
 	toastManager         *toast.ToastManager
 	interruptKeyState    InterruptKeyState
 	exitKeyState         ExitKeyState
+	keySequenceID        int
 	messagesRight        bool
 	fileViewer           fileviewer.Model
+	changes              changes.Model
 }
 
 func (a Model) Init() tea.Cmd {
@@ -87,12 +99,17 @@ func (a Model) Init() tea.Cmd {
 		cmds = append(cmds, tea.RequestBackgroundColor)
 	}
 	cmds = append(cmds, a.app.InitializeProvider())
+	// a session resumed from the command line is already loaded
+	if a.app.Session.ID != "" {
+		cmds = append(cmds, util.CmdHandler(app.SessionLoadedMsg{}))
+	}
 	cmds = append(cmds, a.editor.Init())
 	cmds = append(cmds, a.messages.Init())
 	cmds = append(cmds, a.status.Init())
 	cmds = append(cmds, a.completions.Init())
 	cmds = append(cmds, a.toastManager.Init())
 	cmds = append(cmds, a.fileViewer.Init())
+	cmds = append(cmds, a.app.WatchCustomCommands())
 
 	// Check if we should show the init dialog
 	cmds = append(cmds, func() tea.Msg {
@@ -114,8 +131,20 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyPressMsg:
 		keyString := msg.String()
 
+		// 0. Pending permission requests block everything except exiting
+		if a.permissions.Len() > 0 && keyString != "ctrl+c" {
+			updated, cmd := a.permissions.Update(msg)
+			a.permissions = updated.(dialog.PermissionDialog)
+			return a, cmd
+		}
+
 		// 1. Handle active modal
 		if a.modal != nil {
+			if capturer, ok := a.modal.(layout.KeyCapturer); ok && capturer.CapturesKeys() {
+				updatedModal, cmd := a.modal.Update(msg)
+				a.modal = updatedModal.(layout.Modal)
+				return a, cmd
+			}
 			switch keyString {
 			// Escape always closes current modal
 			case "esc":
@@ -140,13 +169,65 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, cmd
 		}
 
-		// 2. Check for commands that require leader
-		if a.app.IsLeaderSequence {
-			matches := a.app.Commands.Matches(msg, a.app.IsLeaderSequence)
-			a.app.IsLeaderSequence = false
+		// 1a. Route keys to the conversation search bar, n/N step through the
+		// matches once the query is confirmed and typing returns to the editor
+		if a.messages.Searching() && !a.app.KeySequencePending() {
+			if a.messages.SearchFocused() ||
+				slices.Contains([]string{"n", "N", "shift+n", "/", "esc"}, keyString) {
+				updated, cmd := a.messages.Update(msg)
+				a.messages = updated.(chat.MessagesComponent)
+				return a, cmd
+			}
+			if msg.Text != "" {
+				updated, cmd := a.messages.CloseSearch()
+				a.messages = updated.(chat.MessagesComponent)
+				cmds = append(cmds, cmd)
+			}
+		}
+
+		// 1b. Navigate the changed files sidebar while it has focus, typing
+		// returns to the editor
+		if a.changes.Focused() && !a.app.KeySequencePending() {
+			if slices.Contains([]string{"up", "down", "k", "j", "ctrl+p", "ctrl+n", "enter", "esc"}, keyString) {
+				var cmd tea.Cmd
+				a.changes, cmd = a.changes.Update(msg)
+				return a, cmd
+			}
+			if msg.Text != "" {
+				a.changes.Blur()
+			}
+		}
+
+		// 2. Continue a pending key sequence, after the leader key or the
+		// first keys of a keybinding with several keys. A key that doesn't
+		// continue any keybinding ends the sequence and is handled as usual,
+		// after the commands bound to the keys before it.
+		if a.app.KeySequencePending() {
+			keys := append(slices.Clone(a.app.KeySequence), keyString)
+			matches, pending := a.app.Commands.MatchSequence(keys, a.app.IsLeaderSequence)
+			if pending {
+				a.app.KeySequence = keys
+				return a, a.waitForKeySequence()
+			}
+			previous, _ := a.app.Commands.MatchSequence(a.app.KeySequence, a.app.IsLeaderSequence)
+			a.app.ResetKeySequence()
 			if len(matches) > 0 {
 				return a, util.CmdHandler(commands.ExecuteCommandsMsg(matches))
 			}
+			if len(previous) > 0 {
+				return a, tea.Sequence(
+					util.CmdHandler(commands.ExecuteCommandsMsg(previous)),
+					util.CmdHandler(msg),
+				)
+			}
+		}
+
+		// 2a. Vim mode keeps the keys of normal and visual mode, and esc to
+		// leave insert mode, for the editor
+		if !a.showCompletionDialog && a.editor.VimCaptures(msg) {
+			updated, cmd := a.editor.Update(msg)
+			a.editor = updated.(chat.EditorComponent)
+			return a, cmd
 		}
 
 		// 3. Handle completions trigger
@@ -219,7 +300,7 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			!a.app.IsLeaderSequence &&
 			key.Matches(msg, *a.leaderBinding) {
 			a.app.IsLeaderSequence = true
-			return a, nil
+			return a, a.waitForKeySequence()
 		}
 
 		// 6 Handle input clear command
@@ -266,7 +347,14 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// 9. Check again for commands that don't require leader (excluding interrupt when busy and exit when in debounce)
+		// 9. Start a key sequence without leader, the commands bound to the
+		// key alone run if no other key follows in time
+		if _, pending := a.app.Commands.MatchSequence([]string{keyString}, false); pending {
+			a.app.KeySequence = []string{keyString}
+			return a, a.waitForKeySequence()
+		}
+
+		// 10. Check again for commands that don't require leader (excluding interrupt when busy and exit when in debounce)
 		matches := a.app.Commands.Matches(msg, a.app.IsLeaderSequence)
 		if len(matches) > 0 {
 			// Skip interrupt key if we're in debounce mode and app is busy
@@ -276,7 +364,8 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, util.CmdHandler(commands.ExecuteCommandsMsg(matches))
 		}
 
-		// Fallback: suspend if ctrl+z is pressed and no user keybind matched
+		// Fallback: suspend if ctrl+z is pressed and no user keybind matched,
+		// input_undo has to be set to another key or "none" for it
 		if keyString == "ctrl+z" {
 			return a, tea.Suspend
 		}
@@ -332,9 +421,38 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case error:
 		return a, toast.NewErrorToast(msg.Error())
+	case app.EventsReconnectedMsg:
+		cmds = append(cmds, a.app.ResyncSessions(context.Background()))
+	case app.SessionResyncedMsg:
+		a.app.UpdateMessages(msg.SessionID, func([]app.Message) []app.Message {
+			return msg.Messages
+		})
+		if msg.SessionID == a.app.Session.ID {
+			cmds = append(cmds, util.CmdHandler(app.SessionLoadedMsg{}))
+		}
+	case app.CustomCommandsChangedMsg:
+		a.app.LoadCustomCommands()
+		cmds = append(cmds, a.app.WatchCustomCommands())
+	case app.CustomCommandOutputMsg:
+		if msg.Err != nil {
+			return a, toast.NewErrorToast(fmt.Sprintf("/%s failed: %s", msg.Command.Name, msg.Err))
+		}
+		if msg.Command.Prompt != "" {
+			return a, util.CmdHandler(app.SendPrompt(msg.Prompt()))
+		}
+		if strings.TrimSpace(msg.Output) == "" {
+			return a, toast.NewInfoToast(fmt.Sprintf("/%s produced no output", msg.Command.Name))
+		}
+		updated, cmd := a.editor.InsertAttachment(msg.Attachment())
+		a.editor = updated.(chat.EditorComponent)
+		return a, cmd
 	case app.SendPrompt:
 		a.showCompletionDialog = false
-		a.app, cmd = a.app.SendPrompt(context.Background(), msg)
+		var err error
+		a.app, cmd, err = a.app.SendPrompt(context.Background(), msg)
+		if err != nil {
+			cmd = toast.NewErrorToast(err.Error())
+		}
 		cmds = append(cmds, cmd)
 	case app.SetEditorContentMsg:
 		// Set the editor content without sending
@@ -354,20 +472,42 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			"Installed the opencode extension in "+msg.Properties.Ide,
 			toast.WithTitle(msg.Properties.Ide+" extension installed"),
 		)
+	case opencode.EventListResponseEventPermissionUpdated:
+		a.permissions.Enqueue(msg.Properties)
+	case dialog.PermissionRespondedMsg:
+		updated, cmd := a.permissions.Update(msg)
+		a.permissions = updated.(dialog.PermissionDialog)
+		return a, cmd
+	case opencode.EventListResponseEventSessionIdle:
+		// an idle session can no longer be waiting on a permission
+		a.permissions.Remove(msg.Properties.SessionID)
 	case opencode.EventListResponseEventSessionDeleted:
-		if a.app.Session != nil && msg.Properties.Info.ID == a.app.Session.ID {
-			a.app.Session = &opencode.Session{}
-			a.app.Messages = []app.Message{}
+		a.permissions.Remove(msg.Properties.Info.ID)
+		if index := a.app.TabIndex(msg.Properties.Info.ID); index > -1 {
+			active := index == a.app.ActiveTab
+			a.app.CloseTab(index)
+			if active {
+				return a, tea.Batch(
+					util.CmdHandler(app.SessionLoadedMsg{}),
+					toast.NewSuccessToast("Session deleted successfully"),
+				)
+			}
 		}
 		return a, toast.NewSuccessToast("Session deleted successfully")
+	case opencode.EventListResponseEventLspClientDiagnostics:
+		a.app.Diagnostics.Set(
+			msg.Properties.Path,
+			app.ParseDiagnostics(msg.Properties.Diagnostics),
+		)
 	case opencode.EventListResponseEventSessionUpdated:
-		if msg.Properties.Info.ID == a.app.Session.ID {
-			a.app.Session = &msg.Properties.Info
-		}
+		a.app.UpdateSession(msg.Properties.Info)
 	case opencode.EventListResponseEventMessagePartUpdated:
 		slog.Info("message part updated", "message", msg.Properties.Part.MessageID, "part", msg.Properties.Part.ID)
-		if msg.Properties.Part.SessionID == a.app.Session.ID {
-			messageIndex := slices.IndexFunc(a.app.Messages, func(m app.Message) bool {
+		if part, ok := msg.Properties.Part.AsUnion().(opencode.ToolPart); ok {
+			a.updateDiagnostics(part)
+		}
+		a.app.UpdateMessages(msg.Properties.Part.SessionID, func(messages []app.Message) []app.Message {
+			messageIndex := slices.IndexFunc(messages, func(m app.Message) bool {
 				switch casted := m.Info.(type) {
 				case opencode.UserMessage:
 					return casted.ID == msg.Properties.Part.MessageID
@@ -377,7 +517,7 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return false
 			})
 			if messageIndex > -1 {
-				message := a.app.Messages[messageIndex]
+				message := messages[messageIndex]
 				partIndex := slices.IndexFunc(message.Parts, func(p opencode.PartUnion) bool {
 					switch casted := p.(type) {
 					case opencode.TextPart:
@@ -399,13 +539,14 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if partIndex == -1 {
 					message.Parts = append(message.Parts, msg.Properties.Part.AsUnion())
 				}
-				a.app.Messages[messageIndex] = message
+				messages[messageIndex] = message
 			}
-		}
+			return messages
+		})
 	case opencode.EventListResponseEventMessagePartRemoved:
 		slog.Info("message part removed", "session", msg.Properties.SessionID, "message", msg.Properties.MessageID, "part", msg.Properties.PartID)
-		if msg.Properties.SessionID == a.app.Session.ID {
-			messageIndex := slices.IndexFunc(a.app.Messages, func(m app.Message) bool {
+		a.app.UpdateMessages(msg.Properties.SessionID, func(messages []app.Message) []app.Message {
+			messageIndex := slices.IndexFunc(messages, func(m app.Message) bool {
 				switch casted := m.Info.(type) {
 				case opencode.UserMessage:
 					return casted.ID == msg.Properties.MessageID
@@ -415,7 +556,7 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return false
 			})
 			if messageIndex > -1 {
-				message := a.app.Messages[messageIndex]
+				message := messages[messageIndex]
 				partIndex := slices.IndexFunc(message.Parts, func(p opencode.PartUnion) bool {
 					switch casted := p.(type) {
 					case opencode.TextPart:
@@ -434,14 +575,15 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if partIndex > -1 {
 					// Remove the part at partIndex
 					message.Parts = append(message.Parts[:partIndex], message.Parts[partIndex+1:]...)
-					a.app.Messages[messageIndex] = message
+					messages[messageIndex] = message
 				}
 			}
-		}
+			return messages
+		})
 	case opencode.EventListResponseEventMessageRemoved:
 		slog.Info("message removed", "session", msg.Properties.SessionID, "message", msg.Properties.MessageID)
-		if msg.Properties.SessionID == a.app.Session.ID {
-			messageIndex := slices.IndexFunc(a.app.Messages, func(m app.Message) bool {
+		a.app.UpdateMessages(msg.Properties.SessionID, func(messages []app.Message) []app.Message {
+			messageIndex := slices.IndexFunc(messages, func(m app.Message) bool {
 				switch casted := m.Info.(type) {
 				case opencode.UserMessage:
 					return casted.ID == msg.Properties.MessageID
@@ -451,12 +593,13 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return false
 			})
 			if messageIndex > -1 {
-				a.app.Messages = append(a.app.Messages[:messageIndex], a.app.Messages[messageIndex+1:]...)
+				messages = append(messages[:messageIndex], messages[messageIndex+1:]...)
 			}
-		}
+			return messages
+		})
 	case opencode.EventListResponseEventMessageUpdated:
-		if msg.Properties.Info.SessionID == a.app.Session.ID {
-			matchIndex := slices.IndexFunc(a.app.Messages, func(m app.Message) bool {
+		a.app.UpdateMessages(msg.Properties.Info.SessionID, func(messages []app.Message) []app.Message {
+			matchIndex := slices.IndexFunc(messages, func(m app.Message) bool {
 				switch casted := m.Info.(type) {
 				case opencode.UserMessage:
 					return casted.ID == msg.Properties.Info.ID
@@ -467,19 +610,23 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 
 			if matchIndex > -1 {
-				match := a.app.Messages[matchIndex]
-				a.app.Messages[matchIndex] = app.Message{
+				match := messages[matchIndex]
+				messages[matchIndex] = app.Message{
 					Info:  msg.Properties.Info.AsUnion(),
 					Parts: match.Parts,
 				}
 			}
 
 			if matchIndex == -1 {
-				a.app.Messages = append(a.app.Messages, app.Message{
+				messages = append(messages, app.Message{
 					Info:  msg.Properties.Info.AsUnion(),
 					Parts: []opencode.PartUnion{},
 				})
 			}
+			return messages
+		})
+		if assistant, ok := msg.Properties.Info.AsUnion().(opencode.AssistantMessage); ok {
+			cmds = append(cmds, a.app.RecordUsage(assistant))
 		}
 	case opencode.EventListResponseEventSessionError:
 		switch err := msg.Properties.Error.AsUnion().(type) {
@@ -491,10 +638,16 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			slog.Error("Server error", "name", err.Name, "message", err.Data.Message)
 			return a, toast.NewErrorToast(err.Data.Message, toast.WithTitle(string(err.Name)))
 		}
+	case opencode.EventListResponseEventFileEdited:
+		a.changes, cmd = a.changes.Refresh()
+		cmds = append(cmds, cmd)
 	case opencode.EventListResponseEventFileWatcherUpdated:
+		a.changes, cmd = a.changes.Refresh()
+		cmds = append(cmds, cmd)
 		if a.fileViewer.HasFile() {
 			if a.fileViewer.Filename() == msg.Properties.File {
-				return a.openFile(msg.Properties.File)
+				updated, openCmd := a.openFile(msg.Properties.File)
+				return updated, tea.Batch(append(cmds, openCmd)...)
 			}
 		}
 	case tea.WindowSizeMsg:
@@ -510,14 +663,18 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Width: container,
 			},
 		}
+		cmds = append(cmds, a.resize())
 	case app.SessionSelectedMsg:
+		if a.app.TabIndex(msg.ID) > -1 {
+			a.app.OpenTab(msg, nil)
+			return a, util.CmdHandler(app.SessionLoadedMsg{})
+		}
 		messages, err := a.app.ListMessages(context.Background(), msg.ID)
 		if err != nil {
 			slog.Error("Failed to list messages", "error", err.Error())
 			return a, toast.NewErrorToast("Failed to open session")
 		}
-		a.app.Session = msg
-		a.app.Messages = messages
+		a.app.OpenTab(msg, messages)
 		return a, util.CmdHandler(app.SessionLoadedMsg{})
 	case app.SessionCreatedMsg:
 		a.app.Session = msg.Session
@@ -554,8 +711,49 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Reset exit key state after timeout
 		a.exitKeyState = ExitKeyIdle
 		a.editor.SetExitKeyInDebounce(false)
+	case KeySequenceTimeoutMsg:
+		// A later key press waits again
+		if msg.ID != a.keySequenceID || !a.app.KeySequencePending() {
+			return a, nil
+		}
+		// the keys pressed so far may be a keybinding of their own, that
+		// waited for the longer keybindings starting with them
+		matches, _ := a.app.Commands.MatchSequence(a.app.KeySequence, a.app.IsLeaderSequence)
+		a.app.ResetKeySequence()
+		if len(matches) > 0 {
+			return a, util.CmdHandler(commands.ExecuteCommandsMsg(matches))
+		}
+		return a, nil
 	case dialog.FindSelectedMsg:
 		return a.openFile(msg.FilePath)
+	case changes.ChangeSelectedMsg:
+		return a.openFile(msg.Path)
+	case dialog.GrepSelectedMsg:
+		updated, cmd := a.openFile(msg.FilePath)
+		model := updated.(Model)
+		model.fileViewer.ScrollTo(msg.Line)
+		return model, cmd
+	case dialog.ExportSelectedMsg:
+		if msg.Editor {
+			return a, a.openExportInEditor()
+		}
+		return a, a.exportSession(msg.Format, msg.Path)
+	case dialog.ShareSessionMsg:
+		a.modal = dialog.NewShareDialog(a.app, msg.Session)
+		return a, nil
+	case dialog.SessionUnsharedMsg:
+		a.app.UpdateSession(msg.Session)
+		return a, nil
+	case dialog.HistorySelectedMsg:
+		a.editor.RestoreFromPrompt(msg.Prompt)
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		return a, cmd
+	case dialog.DiagnosticSelectedMsg:
+		updated, cmd := a.openFile(util.Relative(msg.FilePath))
+		model := updated.(Model)
+		model.fileViewer.ScrollTo(msg.Line)
+		return model, cmd
 
 	// API
 	case api.Request:
@@ -594,6 +792,9 @@ func (a Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	a.messages = u.(chat.MessagesComponent)
 	cmds = append(cmds, cmd)
 
+	a.changes, cmd = a.changes.Update(msg)
+	cmds = append(cmds, cmd)
+
 	if a.modal != nil {
 		u, cmd := a.modal.Update(msg)
 		a.modal = u.(layout.Modal)
@@ -630,11 +831,14 @@ func (a Model) View() string {
 		Padding(0, 2).
 		Render(mainLayout)
 	mainLayout = lipgloss.PlaceHorizontal(
-		a.width,
+		a.mainWidth(),
 		lipgloss.Center,
 		mainLayout,
 		styles.WhitespaceStyle(t.Background()),
 	)
+	if a.changes.Visible() {
+		mainLayout = lipgloss.JoinHorizontal(lipgloss.Top, mainLayout, a.changes.View())
+	}
 
 	mainStyle := styles.NewStyle().Background(t.Background())
 	mainLayout = mainStyle.Render(mainLayout)
@@ -642,6 +846,9 @@ func (a Model) View() string {
 	if a.modal != nil {
 		mainLayout = a.modal.Render(mainLayout)
 	}
+	if a.permissions.Len() > 0 {
+		mainLayout = a.permissions.Render(mainLayout)
+	}
 	mainLayout = a.toastManager.RenderOverlay(mainLayout)
 
 	if theme.CurrentThemeUsesAnsiColors() {
@@ -654,6 +861,24 @@ func (a Model) Cleanup() {
 	a.status.Cleanup()
 }
 
+// mainWidth is the width left for the chat next to the changed files sidebar
+// resize lays out the components in the size of the terminal, the chat and
+// the file viewer take the width left next to the changed files sidebar
+func (a *Model) resize() tea.Cmd {
+	var cmd tea.Cmd
+	a.fileViewer, cmd = a.fileViewer.SetSize(a.mainWidth()-4, a.height-5)
+	a.changes.SetHeight(a.height)
+	a.editor.SetWidth(a.mainWidth())
+	return tea.Batch(cmd, a.messages.SetSize(a.mainWidth(), a.height))
+}
+
+func (a Model) mainWidth() int {
+	if a.changes.Visible() {
+		return max(0, a.width-changes.Width)
+	}
+	return a.width
+}
+
 func (a Model) openFile(filepath string) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	response, err := a.app.Client.File.Read(
@@ -674,11 +899,82 @@ func (a Model) openFile(filepath string) (tea.Model, tea.Cmd) {
 	return a, cmd
 }
 
+// updateDiagnostics records the diagnostics reported in the metadata of a
+// completed tool call, keyed by file path
+func (a Model) updateDiagnostics(part opencode.ToolPart) {
+	if part.State.Status != opencode.ToolPartStateStatusCompleted {
+		return
+	}
+	metadata, ok := part.State.Metadata.(map[string]any)
+	if !ok {
+		return
+	}
+	diagnostics, ok := metadata["diagnostics"].(map[string]any)
+	if !ok {
+		return
+	}
+	for path, value := range diagnostics {
+		a.app.Diagnostics.Set(path, app.ParseDiagnostics(value))
+	}
+}
+
+// exportSession writes the active session to path in the given format
+func (a Model) exportSession(format export.Format, path string) tea.Cmd {
+	session := a.app.Session
+	messages := a.app.Messages
+	return func() tea.Msg {
+		written, err := export.Write(context.Background(), a.app, session, messages, format, path)
+		if err != nil {
+			slog.Error("Failed to export session", "format", format, "error", err)
+			return toast.NewErrorToast("Failed to export session: " + err.Error())()
+		}
+		return toast.NewSuccessToast("Exported session to " + util.Relative(written))()
+	}
+}
+
+// openExportInEditor opens the markdown export of the active session in
+// $EDITOR, the temporary file is removed once the editor exits
+func (a Model) openExportInEditor() tea.Cmd {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		return toast.NewErrorToast("No EDITOR set, can't open editor")
+	}
+
+	tmpfile, err := os.CreateTemp("", "conversation-*.md")
+	if err != nil {
+		slog.Error("Failed to create temp file", "error", err)
+		return toast.NewErrorToast("Failed to create temporary file.")
+	}
+
+	_, err = tmpfile.WriteString(export.Markdown(a.app.Session, a.app.Messages))
+	if err != nil {
+		slog.Error("Failed to write to temp file", "error", err)
+		tmpfile.Close()
+		os.Remove(tmpfile.Name())
+		return toast.NewErrorToast("Failed to write conversation to file.")
+	}
+	tmpfile.Close()
+
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], tmpfile.Name())...) //nolint:gosec
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		if err != nil {
+			slog.Error("Failed to open editor for conversation", "error", err)
+		}
+		// Clean up the file after editor closes
+		os.Remove(tmpfile.Name())
+		return nil
+	})
+}
+
 func (a Model) home() string {
 	measure := util.Measure("home.View")
 	defer measure()
 	t := theme.CurrentTheme()
-	effectiveWidth := a.width - 4
+	effectiveWidth := a.mainWidth() - 4
 	baseStyle := styles.NewStyle().Background(t.Background())
 	_ = baseStyle.Render
 	_ = styles.NewStyle().Foreground(t.TextMuted()).Background(t.Background()).Render
@@ -788,6 +1084,13 @@ func (a Model) home() string {
 			overlay,
 			mainLayout,
 		)
+	} else if overlay := cmdcomp.WhichKey(a.app, editorWidth); overlay != "" {
+		mainLayout = layout.PlaceOverlay(
+			editorX,
+			max(0, editorY-lipgloss.Height(overlay)+1),
+			overlay,
+			mainLayout,
+		)
 	}
 
 	return mainLayout
@@ -796,11 +1099,14 @@ func (a Model) home() string {
 func (a Model) chat() string {
 	measure := util.Measure("chat.View")
 	defer measure()
-	effectiveWidth := a.width - 4
+	effectiveWidth := a.mainWidth() - 4
 	t := theme.CurrentTheme()
 	editorView := a.editor.View()
 	lines := a.editor.Lines()
 	messagesView := a.messages.View()
+	if a.fileViewer.HasFile() {
+		messagesView = a.fileViewer.View()
+	}
 
 	editorWidth := lipgloss.Width(editorView)
 	editorHeight := max(lines, 5)
@@ -836,20 +1142,88 @@ func (a Model) chat() string {
 			overlay,
 			mainLayout,
 		)
+	} else if overlay := cmdcomp.WhichKey(a.app, editorWidth); overlay != "" {
+		editorY := a.height - editorHeight + 1
+		mainLayout = layout.PlaceOverlay(
+			editorX,
+			max(0, editorY-lipgloss.Height(overlay)),
+			overlay,
+			mainLayout,
+		)
 	}
 
 	return mainLayout
 }
 
+// waitForKeySequence waits for the next key of the pending key sequence, a
+// timeout ends the sequence unless it's disabled. The leader key alone waits
+// until a key is pressed, the timeout starts with the first key after it.
+func (a *Model) waitForKeySequence() tea.Cmd {
+	a.keySequenceID++
+	timeout := a.app.KeySequenceTimeout()
+	if timeout == 0 || len(a.app.KeySequence) == 0 {
+		return nil
+	}
+	id := a.keySequenceID
+	return tea.Tick(timeout, func(t time.Time) tea.Msg {
+		return KeySequenceTimeoutMsg{ID: id}
+	})
+}
+
+// executeCustomCommand runs the built-in commands of a custom command one
+// after another, or runs its shell command, or sends its prompt
+func (a Model) executeCustomCommand(command commands.Command) (tea.Model, tea.Cmd) {
+	custom := command.Custom
+	if len(custom.Commands) > 0 {
+		var cmds []tea.Cmd
+		for _, entry := range custom.Commands {
+			chained, ok := a.app.Commands[commands.CommandName(entry)]
+			if !ok {
+				chained, ok = a.app.Commands.Parse("/" + strings.TrimPrefix(entry, "/"))
+			}
+			// custom commands can't be chained, so they never run in a loop
+			if !ok || chained.Custom != nil {
+				return a, toast.NewErrorToast(fmt.Sprintf("/%s: unknown command %q", custom.Name, entry))
+			}
+			cmds = append(cmds, util.CmdHandler(commands.ExecuteCommandMsg(chained)))
+		}
+		return a, tea.Sequence(cmds...)
+	}
+
+	// without arguments, e.g. when picked from the completions, the command is
+	// left in the editor for the arguments to be typed
+	if custom.TakesArguments() && len(command.Args) == 0 {
+		a.editor.SetValue("/" + custom.Name + " ")
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		return a, cmd
+	}
+	if custom.Shell != "" {
+		return a, tea.Batch(
+			a.app.RunCustomShell(*custom, command.Args),
+			toast.NewInfoToast(fmt.Sprintf("Running /%s...", custom.Name)),
+		)
+	}
+	return a, util.CmdHandler(app.SendPrompt(app.Prompt{Text: custom.ExpandPrompt(command.Args)}))
+}
+
 func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	cmds := []tea.Cmd{
 		util.CmdHandler(commands.CommandExecutedMsg(command)),
 	}
+	if command.Custom != nil {
+		updated, cmd := a.executeCustomCommand(command)
+		return updated, tea.Batch(append(cmds, cmd)...)
+	}
 	switch command.Name {
 	case commands.AppHelpCommand:
 		helpDialog := dialog.NewHelpDialog(a.app)
 		a.modal = helpDialog
+	case commands.KeybindsListCommand:
+		keybindsDialog := dialog.NewKeybindsDialog(a.app)
+		cmds = append(cmds, keybindsDialog.Init())
+		a.modal = keybindsDialog
 	case commands.SwitchModeCommand:
 		updated, cmd := a.app.SwitchMode()
 		a.app = updated
@@ -905,38 +1279,68 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 			}
 		})
 		cmds = append(cmds, cmd)
+	case commands.PromptTemplateCommand:
+		if len(a.app.Templates) == 0 {
+			return a, toast.NewInfoToast("No prompt templates found in .opencode/templates")
+		}
+		if len(command.Args) == 0 {
+			names := []string{}
+			for _, template := range a.app.Templates {
+				names = append(names, template.Name)
+			}
+			return a, toast.NewInfoToast("Usage: /template <name>, available: " + strings.Join(names, ", "))
+		}
+		template, ok := templates.Find(a.app.Templates, command.Args[0])
+		if !ok {
+			return a, toast.NewErrorToast(fmt.Sprintf("Prompt template %q not found", command.Args[0]))
+		}
+		updated, cmd := a.editor.StartTemplate(template)
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
 	case commands.SessionNewCommand:
-		if a.app.Session.ID == "" {
+		if !a.app.NewTab() {
 			return a, nil
 		}
-		a.app.Session = &opencode.Session{}
-		a.app.Messages = []app.Message{}
 		cmds = append(cmds, util.CmdHandler(app.SessionClearedMsg{}))
+	case commands.SessionTabNextCommand:
+		if !a.app.CycleTab(true) {
+			return a, nil
+		}
+		cmds = append(cmds, util.CmdHandler(app.SessionLoadedMsg{}))
+	case commands.SessionTabPreviousCommand:
+		if !a.app.CycleTab(false) {
+			return a, nil
+		}
+		cmds = append(cmds, util.CmdHandler(app.SessionLoadedMsg{}))
+	case commands.SessionTabCloseCommand:
+		if len(a.app.Tabs) == 1 && a.app.Session.ID == "" {
+			return a, nil
+		}
+		a.app.CloseTab(a.app.ActiveTab)
+		cmds = append(cmds, util.CmdHandler(app.SessionLoadedMsg{}))
 	case commands.SessionListCommand:
 		sessionDialog := dialog.NewSessionDialog(a.app)
+		cmds = append(cmds, sessionDialog.Init())
 		a.modal = sessionDialog
 	case commands.SessionShareCommand:
 		if a.app.Session.ID == "" {
 			return a, nil
 		}
-		response, err := a.app.Client.Session.Share(context.Background(), a.app.Session.ID)
+		session, err := a.app.ShareSession(context.Background(), a.app.Session.ID)
 		if err != nil {
-			slog.Error("Failed to share session", "error", err)
 			return a, toast.NewErrorToast("Failed to share session")
 		}
-		shareUrl := response.Share.URL
-		cmds = append(cmds, app.SetClipboard(shareUrl))
-		cmds = append(cmds, toast.NewSuccessToast("Share URL copied to clipboard!"))
+		a.app.UpdateSession(*session)
+		a.modal = dialog.NewShareDialog(a.app, *session)
 	case commands.SessionUnshareCommand:
 		if a.app.Session.ID == "" {
 			return a, nil
 		}
-		_, err := a.app.Client.Session.Unshare(context.Background(), a.app.Session.ID)
+		session, err := a.app.UnshareSession(context.Background(), a.app.Session.ID)
 		if err != nil {
-			slog.Error("Failed to unshare session", "error", err)
 			return a, toast.NewErrorToast("Failed to unshare session")
 		}
-		a.app.Session.Share.URL = ""
+		a.app.UpdateSession(*session)
 		cmds = append(cmds, toast.NewSuccessToast("Session unshared successfully"))
 	case commands.SessionInterruptCommand:
 		if a.app.Session.ID == "" {
@@ -954,53 +1358,20 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		if a.app.Session.ID == "" {
 			return a, toast.NewErrorToast("No active session to export.")
 		}
-
-		// Use current conversation history
-		messages := a.app.Messages
-		if len(messages) == 0 {
+		if len(a.app.Messages) == 0 {
 			return a, toast.NewInfoToast("No messages to export.")
 		}
-
-		// Format to Markdown
-		markdownContent := formatConversationToMarkdown(messages)
-
-		// Check if EDITOR is set
-		editor := os.Getenv("EDITOR")
-		if editor == "" {
-			return a, toast.NewErrorToast("No EDITOR set, can't open editor")
+		if len(command.Args) == 0 {
+			exportDialog := dialog.NewExportDialog(a.app)
+			cmds = append(cmds, exportDialog.Init())
+			a.modal = exportDialog
+			break
 		}
-
-		// Create and write to temp file
-		tmpfile, err := os.CreateTemp("", "conversation-*.md")
+		format, err := export.ParseFormat(command.Args[0])
 		if err != nil {
-			slog.Error("Failed to create temp file", "error", err)
-			return a, toast.NewErrorToast("Failed to create temporary file.")
+			return a, toast.NewErrorToast(err.Error())
 		}
-
-		_, err = tmpfile.WriteString(markdownContent)
-		if err != nil {
-			slog.Error("Failed to write to temp file", "error", err)
-			tmpfile.Close()
-			os.Remove(tmpfile.Name())
-			return a, toast.NewErrorToast("Failed to write conversation to file.")
-		}
-		tmpfile.Close()
-
-		// Open in editor
-		parts := strings.Fields(editor)
-		c := exec.Command(parts[0], append(parts[1:], tmpfile.Name())...) //nolint:gosec
-		c.Stdin = os.Stdin
-		c.Stdout = os.Stdout
-		c.Stderr = os.Stderr
-		cmd = tea.ExecProcess(c, func(err error) tea.Msg {
-			if err != nil {
-				slog.Error("Failed to open editor for conversation", "error", err)
-			}
-			// Clean up the file after editor closes
-			os.Remove(tmpfile.Name())
-			return nil
-		})
-		cmds = append(cmds, cmd)
+		cmds = append(cmds, a.exportSession(format, strings.Join(command.Args[1:], " ")))
 	case commands.ToolDetailsCommand:
 		message := "Tool details are now visible"
 		if a.messages.ToolDetailsVisible() {
@@ -1028,7 +1399,26 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		a.app.State.SplitDiff = a.fileViewer.DiffStyle() == fileviewer.DiffStyleSplit
 		cmds = append(cmds, a.app.SaveState())
 	case commands.FileSearchCommand:
-		return a, nil
+		if a.app.Session.ID == "" {
+			return a, nil
+		}
+		updated, cmd := a.messages.StartSearch()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
+	case commands.ChangesToggleCommand:
+		a.changes, cmd = a.changes.Toggle()
+		cmds = append(cmds, cmd, a.resize())
+	case commands.FileGrepCommand:
+		grepDialog := dialog.NewGrepDialog(a.app)
+		cmds = append(cmds, grepDialog.Init())
+		a.modal = grepDialog
+	case commands.DiagnosticsToggleCommand:
+		if _, ok := a.modal.(dialog.DiagnosticsDialog); ok {
+			a.modal = nil
+			return a, nil
+		}
+		diagnosticsDialog := dialog.NewDiagnosticsDialog(a.app)
+		a.modal = diagnosticsDialog
 	case commands.ProjectInitCommand:
 		cmds = append(cmds, a.app.InitializeProject(context.Background()))
 	case commands.InputClearCommand:
@@ -1050,6 +1440,31 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		updated, cmd := a.editor.Newline()
 		a.editor = updated.(chat.EditorComponent)
 		cmds = append(cmds, cmd)
+	case commands.InputUndoCommand:
+		updated, cmd := a.editor.Undo()
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
+	case commands.InputRedoCommand:
+		updated, cmd := a.editor.Redo()
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
+	case commands.InputHistoryCommand:
+		if len(a.app.History()) == 0 {
+			cmds = append(cmds, toast.NewInfoToast("No prompts in history"))
+			break
+		}
+		historyDialog := dialog.NewHistoryDialog(a.app, strings.TrimSpace(a.editor.Value()))
+		cmds = append(cmds, historyDialog.Init())
+		a.modal = historyDialog
+	case commands.InputVimToggleCommand:
+		a.app.State.VimMode = !a.app.State.VimMode
+		a.editor.SetVimEnabled(a.app.State.VimMode)
+		cmds = append(cmds, a.app.SaveState())
+		if a.app.State.VimMode {
+			cmds = append(cmds, toast.NewInfoToast("Vim mode enabled, esc for normal mode"))
+		} else {
+			cmds = append(cmds, toast.NewInfoToast("Vim mode disabled"))
+		}
 	case commands.MessagesFirstCommand:
 		updated, cmd := a.messages.GotoTop()
 		a.messages = updated.(chat.MessagesComponent)
@@ -1058,6 +1473,18 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		updated, cmd := a.messages.GotoBottom()
 		a.messages = updated.(chat.MessagesComponent)
 		cmds = append(cmds, cmd)
+	case commands.MessagesPreviousCommand:
+		updated, cmd := a.messages.PreviousBlock()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
+	case commands.MessagesNextCommand:
+		updated, cmd := a.messages.NextBlock()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
+	case commands.MessagesBlockToggleCommand:
+		updated, cmd := a.messages.ToggleBlock()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
 	case commands.MessagesPageUpCommand:
 		if a.fileViewer.HasFile() {
 			a.fileViewer, cmd = a.fileViewer.PageUp()
@@ -1102,6 +1529,26 @@ func (a Model) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 		updated, cmd := a.messages.CopyLastMessage()
 		a.messages = updated.(chat.MessagesComponent)
 		cmds = append(cmds, cmd)
+	case commands.MessagesCopyCodeCommand:
+		blocks := a.messages.CodeBlocks()
+		if len(blocks) == 0 {
+			cmds = append(cmds, toast.NewInfoToast("No code blocks to copy"))
+			break
+		}
+		a.modal = dialog.NewCodeBlocksDialog(blocks)
+	case commands.MessagesActionsCommand:
+		// without a cursor the actions are for the last block in view
+		if partID, _ := a.messages.Actions(); partID == "" {
+			updated, cmd := a.messages.PreviousBlock()
+			a.messages = updated.(chat.MessagesComponent)
+			cmds = append(cmds, cmd)
+		}
+		partID, actions := a.messages.Actions()
+		if len(actions) == 0 {
+			cmds = append(cmds, toast.NewInfoToast("No actions for this message"))
+			break
+		}
+		a.modal = dialog.NewMessageActionsDialog(partID, actions)
 	case commands.MessagesUndoCommand:
 		updated, cmd := a.messages.UndoLastMessage()
 		a.messages = updated.(chat.MessagesComponent)
@@ -1133,6 +1580,7 @@ func NewModel(app *app.App) tea.Model {
 
 	model := &Model{
 		status:               status.NewStatusCmp(app),
+		permissions:          dialog.NewPermissionDialog(app),
 		app:                  app,
 		editor:               editor,
 		messages:             messages,
@@ -1146,53 +1594,13 @@ func NewModel(app *app.App) tea.Model {
 		interruptKeyState:    InterruptKeyIdle,
 		exitKeyState:         ExitKeyIdle,
 		fileViewer:           fileviewer.New(app),
+		changes:              changes.New(app),
 		messagesRight:        app.State.MessagesRight,
 	}
 
 	return model
 }
 
-func formatConversationToMarkdown(messages []app.Message) string {
-	var builder strings.Builder
-
-	builder.WriteString("# Conversation History\n\n")
-
-	for _, msg := range messages {
-		builder.WriteString("---\n\n")
-
-		var role string
-		var timestamp time.Time
-
-		switch info := msg.Info.(type) {
-		case opencode.UserMessage:
-			role = "User"
-			timestamp = time.UnixMilli(int64(info.Time.Created))
-		case opencode.AssistantMessage:
-			role = "Assistant"
-			timestamp = time.UnixMilli(int64(info.Time.Created))
-		default:
-			continue
-		}
-
-		builder.WriteString(
-			fmt.Sprintf("**%s** (*%s*)\n\n", role, timestamp.Format("2006-01-02 15:04:05")),
-		)
-
-		for _, part := range msg.Parts {
-			switch p := part.(type) {
-			case opencode.TextPart:
-				builder.WriteString(p.Text + "\n\n")
-			case opencode.FilePart:
-				builder.WriteString(fmt.Sprintf("[File: %s]\n\n", p.Filename))
-			case opencode.ToolPart:
-				builder.WriteString(fmt.Sprintf("[Tool: %s]\n\n", p.Tool))
-			}
-		}
-	}
-
-	return builder.String()
-}
-
 // ColorTheme represents different color themes for the logo
 type ColorTheme int
 
@@ -0,0 +1,91 @@
+package util
+
+import "strings"
+
+// CodeBlock is a fenced code block of a markdown document
+type CodeBlock struct {
+	Language string
+	Code     string
+	// Line is the index of the line opening the block
+	Line int
+}
+
+// FirstLine returns the first non-empty line of the code
+func (b CodeBlock) FirstLine() string {
+	for line := range strings.SplitSeq(b.Code, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// CodeBlocks returns the fenced code blocks of a markdown document in order,
+// with their contents as written. A fence left open runs to the end of the
+// document.
+func CodeBlocks(markdown string) []CodeBlock {
+	blocks := []CodeBlock{}
+	var current *CodeBlock
+	var lines []string
+	fence, indent := "", 0
+
+	for index, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimLeft(line, " ")
+		lineIndent := len(line) - len(trimmed)
+
+		if current == nil {
+			if lineIndent > 3 {
+				continue
+			}
+			marker := fenceMarker(trimmed)
+			if marker == "" {
+				continue
+			}
+			info := strings.TrimSpace(trimmed[len(marker):])
+			// backtick fences can't have backticks in their info string
+			if marker[0] == '`' && strings.Contains(info, "`") {
+				continue
+			}
+			language, _, _ := strings.Cut(info, " ")
+			current = &CodeBlock{Language: language, Line: index}
+			fence, indent, lines = marker, lineIndent, nil
+			continue
+		}
+
+		if lineIndent <= 3 {
+			marker := fenceMarker(trimmed)
+			if marker != "" && marker[0] == fence[0] && len(marker) >= len(fence) &&
+				strings.TrimSpace(trimmed[len(marker):]) == "" {
+				current.Code = strings.Join(lines, "\n")
+				blocks = append(blocks, *current)
+				current = nil
+				continue
+			}
+		}
+		// the indentation of the opening fence is removed from the contents
+		lines = append(lines, line[min(indent, lineIndent):])
+	}
+
+	if current != nil {
+		current.Code = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+// fenceMarker returns the run of at least three backticks or tildes opening
+// a line, if any
+func fenceMarker(line string) string {
+	if line == "" || (line[0] != '`' && line[0] != '~') {
+		return ""
+	}
+	end := 0
+	for end < len(line) && line[end] == line[0] {
+		end++
+	}
+	if end < 3 {
+		return ""
+	}
+	return line[:end]
+}
@@ -0,0 +1,28 @@
+package util_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sst/opencode/internal/util"
+)
+
+func TestCodeBlocks(t *testing.T) {
+	markdown := "Run this:\n\n" +
+		"```bash\nnpm install\nnpm test\n```\n\n" +
+		"  ~~~~ go title=\"main.go\"\n  func main() {}\n  ```\n  ~~~~\n\n" +
+		"``not a fence``\n\n" +
+		"```\nleft open\n"
+
+	expected := []util.CodeBlock{
+		{Language: "bash", Code: "npm install\nnpm test", Line: 2},
+		{Language: "go", Code: "func main() {}\n```", Line: 7},
+		{Language: "", Code: "left open", Line: 14},
+	}
+	if blocks := util.CodeBlocks(markdown); !reflect.DeepEqual(blocks, expected) {
+		t.Errorf("expected %#v, got %#v", expected, blocks)
+	}
+	if line := expected[0].FirstLine(); line != "npm install" {
+		t.Errorf("expected the first line, got %q", line)
+	}
+}
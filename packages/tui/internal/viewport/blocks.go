@@ -0,0 +1,77 @@
+package viewport
+
+import "slices"
+
+// Block is a block of lines of the content, see [Model.SetContentBlocks].
+type Block struct {
+	// Lines are the lines of the block, they must not contain line breaks.
+	Lines []string
+	// Width is the width of the longest line of the block.
+	Width int
+}
+
+// SetContentBlocks sets the content to blocks of lines. Unlike
+// [Model.SetContent] the blocks aren't joined or measured, only the lines in
+// view are copied when rendering, so replacing a block of long content is
+// cheap. The blocks are kept as they are and must not be modified afterwards.
+func (m *Model) SetContentBlocks(blocks []Block) {
+	m.lines = nil
+	m.blocks = blocks
+	m.offsets = make([]int, len(blocks))
+	m.blockLines = 0
+	m.longestLineWidth = 0
+	for i, block := range blocks {
+		m.offsets[i] = m.blockLines
+		m.blockLines += len(block.Lines)
+		m.longestLineWidth = max(m.longestLineWidth, block.Width)
+	}
+	m.ClearHighlights()
+
+	if m.YOffset > m.maxYOffset() {
+		m.GotoBottom()
+	}
+	m.memo.Invalidate()
+}
+
+// realLineCount returns the number of lines of the content, without soft
+// wrapping.
+func (m Model) realLineCount() int {
+	if m.blocks != nil {
+		return m.blockLines
+	}
+	return len(m.lines)
+}
+
+// linesBetween returns a copy of the lines of the content from top up to
+// bottom.
+func (m Model) linesBetween(top, bottom int) []string {
+	if m.blocks == nil {
+		lines := make([]string, bottom-top)
+		copy(lines, m.lines[top:bottom])
+		return lines
+	}
+
+	lines := make([]string, 0, bottom-top)
+	// the first block starting at top, or else the block holding it
+	i, found := slices.BinarySearch(m.offsets, top)
+	if !found {
+		i--
+	}
+	for i = max(0, i); i < len(m.blocks) && len(lines) < bottom-top; i++ {
+		block := m.blocks[i].Lines
+		start := max(0, top-m.offsets[i])
+		end := min(len(block), bottom-m.offsets[i])
+		if start < end {
+			lines = append(lines, block[start:end]...)
+		}
+	}
+	return lines
+}
+
+// allLines returns all the lines of the content.
+func (m Model) allLines() []string {
+	if m.blocks == nil {
+		return m.lines
+	}
+	return m.linesBetween(0, m.blockLines)
+}
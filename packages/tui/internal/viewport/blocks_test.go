@@ -0,0 +1,38 @@
+package viewport
+
+import "testing"
+
+func TestContentBlocks(t *testing.T) {
+	blocks := []Block{
+		{Lines: []string{"", "one"}, Width: 3},
+		{},
+		{Lines: []string{"two", "three", "four"}, Width: 5},
+		{Lines: []string{"", "five"}, Width: 4},
+	}
+	lines := New(WithWidth(10), WithHeight(3))
+	lines.SetContent("\none\ntwo\nthree\nfour\n\nfive")
+	m := New(WithWidth(10), WithHeight(3))
+	m.SetContentBlocks(blocks)
+
+	if m.TotalLineCount() != 7 {
+		t.Fatalf("expected 7 lines, got %d", m.TotalLineCount())
+	}
+	if m.GetContent() != lines.GetContent() {
+		t.Errorf("expected the blocks to be joined, got %q", m.GetContent())
+	}
+	for offset := range m.TotalLineCount() {
+		m.SetYOffset(offset)
+		lines.SetYOffset(offset)
+		if m.View() != lines.View() {
+			t.Errorf("offset %d: expected %q, got %q", offset, lines.View(), m.View())
+		}
+	}
+	if !m.AtBottom() || m.YOffset != 4 {
+		t.Errorf("expected the offset to stop at the last page, got %d", m.YOffset)
+	}
+
+	m.SetContent("replaced")
+	if m.TotalLineCount() != 1 || m.GetContent() != "replaced" {
+		t.Errorf("expected the blocks to be replaced by the content")
+	}
+}
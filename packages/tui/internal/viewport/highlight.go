@@ -10,7 +10,7 @@ import (
 //
 // Assumptions:
 // - matches are measured in bytes, e.g. what [regex.FindAllStringIndex] would return
-// - matches were made against the given content
+// - matches were made against the given content with ANSI sequences stripped
 // - matches are in order
 // - matches do not overlap
 // - content is line terminated with \n only
@@ -25,13 +25,15 @@ func parseMatches(
 		return nil
 	}
 
+	content = ansi.Strip(content)
+
 	line := 0
 	graphemePos := 0
 	previousLinesOffset := 0
 	bytePos := 0
 
 	highlights := make([]highlightInfo, 0, len(matches))
-	gr := uniseg.NewGraphemes(ansi.Strip(content))
+	gr := uniseg.NewGraphemes(content)
 
 	for _, match := range matches {
 		byteStart, byteEnd := match[0], match[1]
@@ -0,0 +1,64 @@
+package viewport
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParseMatchesStyledContent(t *testing.T) {
+	content := "\x1b[1mfirst\x1b[0m line\n\x1b[31msecond\x1b[0m line with a match\nmatch"
+	matches := regexp.MustCompile("match").FindAllStringIndex(ansi.Strip(content), -1)
+
+	highlights := parseMatches(content, matches)
+	if len(highlights) != 2 {
+		t.Fatalf("expected 2 highlights, got %d", len(highlights))
+	}
+
+	expected := []struct {
+		line       int
+		start, end int
+	}{
+		{line: 1, start: 19, end: 24},
+		{line: 2, start: 0, end: 5},
+	}
+	for i, want := range expected {
+		line, start, end := highlights[i].coords()
+		if line != want.line || start != want.start || end != want.end {
+			t.Errorf(
+				"highlight %d: expected line %d columns %d-%d, got line %d columns %d-%d",
+				i, want.line, want.start, want.end, line, start, end,
+			)
+		}
+	}
+}
+
+func TestHighlightIndex(t *testing.T) {
+	m := New()
+	m.SetHeight(2)
+	m.SetContent("one match\ntwo\nthree match\nfour match")
+	m.SetHighlights(regexp.MustCompile("match").FindAllStringIndex(m.GetContent(), -1))
+
+	if m.HighlightCount() != 3 {
+		t.Fatalf("expected 3 highlights, got %d", m.HighlightCount())
+	}
+	if m.HighlightIndex() != 0 {
+		t.Errorf("expected the first highlight to be focused, got %d", m.HighlightIndex())
+	}
+
+	m.HighlightPrevious()
+	if m.HighlightIndex() != 2 {
+		t.Errorf("expected navigation to wrap to the last highlight, got %d", m.HighlightIndex())
+	}
+
+	m.SetHighlightIndex(10)
+	if m.HighlightIndex() != 2 {
+		t.Errorf("expected the index to be clamped, got %d", m.HighlightIndex())
+	}
+
+	m.SetContent("replaced")
+	if m.HighlightCount() != 0 || m.HighlightIndex() != -1 {
+		t.Errorf("expected highlights to be cleared with the content")
+	}
+}
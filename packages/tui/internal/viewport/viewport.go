@@ -116,6 +116,12 @@ type Model struct {
 	lines            []string
 	longestLineWidth int
 
+	// blocks hold the content instead of lines when it's set with
+	// [Model.SetContentBlocks], offsets are the first line of each block.
+	blocks     []Block
+	offsets    []int
+	blockLines int
+
 	// HighlightStyle highlights the ranges set with [SetHighligths].
 	HighlightStyle lipgloss.Style
 
@@ -251,6 +257,9 @@ func (m *Model) SetContent(s string) {
 func (m *Model) SetContentLines(lines []string) {
 	// if there's no content, set content to actual nil instead of one empty
 	// line.
+	m.blocks = nil
+	m.offsets = nil
+	m.blockLines = 0
 	m.lines = lines
 	if len(m.lines) == 1 && ansi.StringWidth(m.lines[0]) == 0 {
 		m.lines = nil
@@ -267,12 +276,16 @@ func (m *Model) SetContentLines(lines []string) {
 // GetContent returns the entire content as a single string.
 // Line endings are normalized to '\n'.
 func (m Model) GetContent() string {
-	return strings.Join(m.lines, "\n")
+	return strings.Join(m.allLines(), "\n")
 }
 
 // calculateLine taking soft wrapping into account, returns the total viewable
 // lines and the real-line index for the given yoffset.
 func (m Model) calculateLine(yoffset int) (total, idx int) {
+	if !m.SoftWrap && m.blocks != nil {
+		// the lines of blocks don't have line breaks
+		return m.blockLines, min(yoffset, m.blockLines)
+	}
 	if !m.SoftWrap {
 		for i, line := range m.lines {
 			adjust := max(1, lipgloss.Height(line))
@@ -292,7 +305,8 @@ func (m Model) calculateLine(yoffset int) (total, idx int) {
 	if m.LeftGutterFunc != nil {
 		gutterSize = lipgloss.Width(m.LeftGutterFunc(GutterContext{}))
 	}
-	for i, line := range m.lines {
+	lines := m.allLines()
+	for i, line := range lines {
 		adjust := max(1, lipgloss.Width(line)/(maxWidth-gutterSize))
 		if yoffset >= total && yoffset < total+adjust {
 			idx = i
@@ -300,7 +314,7 @@ func (m Model) calculateLine(yoffset int) (total, idx int) {
 		total += adjust
 	}
 	if yoffset >= total {
-		idx = len(m.lines)
+		idx = len(lines)
 	}
 	return total, idx
 }
@@ -354,9 +368,8 @@ func (m Model) visibleLines() (lines []string) {
 	if m.lineCount() > 0 {
 		pos := m.lineToIndex(m.YOffset)
 		top := max(0, pos)
-		bottom := clamp(pos+maxHeight, top, len(m.lines))
-		lines = make([]string, bottom-top)
-		copy(lines, m.lines[top:bottom])
+		bottom := clamp(pos+maxHeight, top, m.realLineCount())
+		lines = m.linesBetween(top, bottom)
 		lines = m.styleLines(lines, top)
 		lines = m.highlightLines(lines, top)
 	}
@@ -543,7 +556,7 @@ func (m *Model) HalfViewUp() {
 
 // LineDown moves the view down by the given number of lines.
 func (m *Model) LineDown(n int) {
-	if m.AtBottom() || n == 0 || len(m.lines) == 0 {
+	if m.AtBottom() || n == 0 || m.realLineCount() == 0 {
 		return
 	}
 
@@ -558,7 +571,7 @@ func (m *Model) LineDown(n int) {
 // LineUp moves the view down by the given number of lines. Returns the new
 // lines to show.
 func (m *Model) LineUp(n int) {
-	if m.AtTop() || n == 0 || len(m.lines) == 0 {
+	if m.AtTop() || n == 0 || m.realLineCount() == 0 {
 		return
 	}
 
@@ -645,7 +658,7 @@ func (m *Model) ResetIndent() {
 // [Model.HighlightNext] and [Model.HighlightPrevious] to navigate.
 // Use [Model.ClearHighlights] to remove all highlights.
 func (m *Model) SetHighlights(matches [][]int) {
-	if len(matches) == 0 || len(m.lines) == 0 {
+	if len(matches) == 0 || m.realLineCount() == 0 {
 		return
 	}
 	m.highlights = parseMatches(m.GetContent(), matches)
@@ -692,6 +705,27 @@ func (m *Model) HighlightPrevious() {
 	m.memo.Invalidate()
 }
 
+// HighlightCount returns the number of highlight ranges.
+func (m Model) HighlightCount() int {
+	return len(m.highlights)
+}
+
+// HighlightIndex returns the index of the focused highlight, or -1 if none is
+// focused.
+func (m Model) HighlightIndex() int {
+	return m.hiIdx
+}
+
+// SetHighlightIndex focuses the highlight at the given index without scrolling
+// to it.
+func (m *Model) SetHighlightIndex(index int) {
+	if len(m.highlights) == 0 {
+		return
+	}
+	m.hiIdx = max(-1, min(index, len(m.highlights)-1))
+	m.memo.Invalidate()
+}
+
 func (m Model) findNearedtMatch() int {
 	for i, match := range m.highlights {
 		if match.lineStart >= m.YOffset {
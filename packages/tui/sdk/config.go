@@ -50,6 +50,9 @@ type Config struct {
 	Autoshare bool `json:"autoshare"`
 	// Automatically update to the latest version
 	Autoupdate bool `json:"autoupdate"`
+	// Custom slash commands, keyed by their trigger, see
+	// https://opencode.ai/docs/commands
+	Commands map[string]ConfigCommand `json:"commands"`
 	// Disable providers that are loaded automatically
 	DisabledProviders []string           `json:"disabled_providers"`
 	Experimental      ConfigExperimental `json:"experimental"`
@@ -86,6 +89,7 @@ type configJSON struct {
 	Agent             apijson.Field
 	Autoshare         apijson.Field
 	Autoupdate        apijson.Field
+	Commands          apijson.Field
 	DisabledProviders apijson.Field
 	Experimental      apijson.Field
 	Instructions      apijson.Field
@@ -155,6 +159,37 @@ func (r configAgentGeneralJSON) RawJSON() string {
 	return r.raw
 }
 
+type ConfigCommand struct {
+	// Built-in commands to run one after another
+	Commands    []string `json:"commands"`
+	Description string   `json:"description"`
+	Keybind     string   `json:"keybind"`
+	// Prompt to send, {{args}} is replaced with the arguments
+	Prompt string `json:"prompt"`
+	// Shell command to run, its output is attached to the prompt
+	Shell string            `json:"shell"`
+	JSON  configCommandJSON `json:"-"`
+}
+
+// configCommandJSON contains the JSON metadata for the struct [ConfigCommand]
+type configCommandJSON struct {
+	Commands    apijson.Field
+	Description apijson.Field
+	Keybind     apijson.Field
+	Prompt      apijson.Field
+	Shell       apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *ConfigCommand) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r configCommandJSON) RawJSON() string {
+	return r.raw
+}
+
 type ConfigExperimental struct {
 	Hook ConfigExperimentalHook `json:"hook"`
 	JSON configExperimentalJSON `json:"-"`
@@ -541,28 +576,50 @@ type KeybindsConfig struct {
 	AppExit string `json:"app_exit,required"`
 	// Show help dialog
 	AppHelp string `json:"app_help,required"`
+	// Toggle changed files
+	ChangesToggle string `json:"changes_toggle,required"`
+	// Toggle diagnostics
+	DiagnosticsToggle string `json:"diagnostics_toggle,required"`
 	// Open external editor
 	EditorOpen string `json:"editor_open,required"`
 	// Close file
 	FileClose string `json:"file_close,required"`
 	// Split/unified diff
 	FileDiffToggle string `json:"file_diff_toggle,required"`
+	// Search project files
+	FileGrep string `json:"file_grep,required"`
 	// List files
 	FileList string `json:"file_list,required"`
 	// Search file
 	FileSearch string `json:"file_search,required"`
 	// Clear input field
 	InputClear string `json:"input_clear,required"`
+	// Search prompt history
+	InputHistory string `json:"input_history,required"`
 	// Insert newline in input
 	InputNewline string `json:"input_newline,required"`
 	// Paste from clipboard
 	InputPaste string `json:"input_paste,required"`
+	// Redo the last undone edit in input
+	InputRedo string `json:"input_redo,required"`
 	// Submit input
 	InputSubmit string `json:"input_submit,required"`
+	// Undo the last edit in input
+	InputUndo string `json:"input_undo,required"`
+	// Toggle vim mode in input
+	InputVimToggle string `json:"input_vim_toggle"`
+	// List and edit keybinds
+	KeybindsList string `json:"keybinds_list"`
 	// Leader key for keybind combinations
 	Leader string `json:"leader,required"`
+	// Show message actions
+	MessagesActions string `json:"messages_actions,required"`
+	// Expand or collapse tool output
+	MessagesBlockToggle string `json:"messages_block_toggle,required"`
 	// Copy message
 	MessagesCopy string `json:"messages_copy,required"`
+	// Copy code block
+	MessagesCopyCode string `json:"messages_copy_code,required"`
 	// Navigate to first message
 	MessagesFirst string `json:"messages_first,required"`
 	// Scroll messages down by half page
@@ -591,6 +648,8 @@ type KeybindsConfig struct {
 	ModelList string `json:"model_list,required"`
 	// Create/update AGENTS.md
 	ProjectInit string `json:"project_init,required"`
+	// Use a prompt template
+	PromptTemplate string `json:"prompt_template"`
 	// Compact the session
 	SessionCompact string `json:"session_compact,required"`
 	// Export session to editor
@@ -603,6 +662,12 @@ type KeybindsConfig struct {
 	SessionNew string `json:"session_new,required"`
 	// Share current session
 	SessionShare string `json:"session_share,required"`
+	// Close the current session tab
+	SessionTabClose string `json:"session_tab_close,required"`
+	// Switch to the next session tab
+	SessionTabNext string `json:"session_tab_next,required"`
+	// Switch to the previous session tab
+	SessionTabPrevious string `json:"session_tab_previous,required"`
 	// Unshare current session
 	SessionUnshare string `json:"session_unshare,required"`
 	// Next mode
@@ -620,17 +685,28 @@ type KeybindsConfig struct {
 type keybindsConfigJSON struct {
 	AppExit              apijson.Field
 	AppHelp              apijson.Field
+	ChangesToggle        apijson.Field
+	DiagnosticsToggle    apijson.Field
 	EditorOpen           apijson.Field
 	FileClose            apijson.Field
 	FileDiffToggle       apijson.Field
+	FileGrep             apijson.Field
 	FileList             apijson.Field
 	FileSearch           apijson.Field
 	InputClear           apijson.Field
+	InputHistory         apijson.Field
 	InputNewline         apijson.Field
 	InputPaste           apijson.Field
+	InputRedo            apijson.Field
 	InputSubmit          apijson.Field
+	InputUndo            apijson.Field
+	InputVimToggle       apijson.Field
+	KeybindsList         apijson.Field
 	Leader               apijson.Field
+	MessagesActions      apijson.Field
+	MessagesBlockToggle  apijson.Field
 	MessagesCopy         apijson.Field
+	MessagesCopyCode     apijson.Field
 	MessagesFirst        apijson.Field
 	MessagesHalfPageDown apijson.Field
 	MessagesHalfPageUp   apijson.Field
@@ -645,12 +721,16 @@ type keybindsConfigJSON struct {
 	MessagesUndo         apijson.Field
 	ModelList            apijson.Field
 	ProjectInit          apijson.Field
+	PromptTemplate       apijson.Field
 	SessionCompact       apijson.Field
 	SessionExport        apijson.Field
 	SessionInterrupt     apijson.Field
 	SessionList          apijson.Field
 	SessionNew           apijson.Field
 	SessionShare         apijson.Field
+	SessionTabClose      apijson.Field
+	SessionTabNext       apijson.Field
+	SessionTabPrevious   apijson.Field
 	SessionUnshare       apijson.Field
 	SwitchMode           apijson.Field
 	SwitchModeReverse    apijson.Field
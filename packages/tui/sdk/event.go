@@ -298,14 +298,16 @@ func (r eventListResponseEventLspClientDiagnosticsJSON) RawJSON() string {
 func (r EventListResponseEventLspClientDiagnostics) implementsEventListResponse() {}
 
 type EventListResponseEventLspClientDiagnosticsProperties struct {
-	Path     string                                                   `json:"path,required"`
-	ServerID string                                                   `json:"serverID,required"`
-	JSON     eventListResponseEventLspClientDiagnosticsPropertiesJSON `json:"-"`
+	Diagnostics []interface{}                                            `json:"diagnostics,required"`
+	Path        string                                                   `json:"path,required"`
+	ServerID    string                                                   `json:"serverID,required"`
+	JSON        eventListResponseEventLspClientDiagnosticsPropertiesJSON `json:"-"`
 }
 
 // eventListResponseEventLspClientDiagnosticsPropertiesJSON contains the JSON
 // metadata for the struct [EventListResponseEventLspClientDiagnosticsProperties]
 type eventListResponseEventLspClientDiagnosticsPropertiesJSON struct {
+	Diagnostics apijson.Field
 	Path        apijson.Field
 	ServerID    apijson.Field
 	raw         string
@@ -673,6 +675,7 @@ type EventListResponseEventPermissionUpdatedProperties struct {
 	SessionID string                                                `json:"sessionID,required"`
 	Time      EventListResponseEventPermissionUpdatedPropertiesTime `json:"time,required"`
 	Title     string                                                `json:"title,required"`
+	Type      string                                                `json:"type,required"`
 	JSON      eventListResponseEventPermissionUpdatedPropertiesJSON `json:"-"`
 }
 
@@ -684,6 +687,7 @@ type eventListResponseEventPermissionUpdatedPropertiesJSON struct {
 	SessionID   apijson.Field
 	Time        apijson.Field
 	Title       apijson.Field
+	Type        apijson.Field
 	raw         string
 	ExtraFields map[string]apijson.Field
 }
@@ -44,6 +44,18 @@ func (r *SessionService) New(ctx context.Context, opts ...option.RequestOption)
 	return
 }
 
+// Update session properties
+func (r *SessionService) Update(ctx context.Context, id string, body SessionUpdateParams, opts ...option.RequestOption) (res *Session, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPatch, path, body, &res, opts...)
+	return
+}
+
 // List all sessions
 func (r *SessionService) List(ctx context.Context, opts ...option.RequestOption) (res *[]Session, err error) {
 	opts = append(r.Options[:], opts...)
@@ -88,6 +100,18 @@ func (r *SessionService) Chat(ctx context.Context, id string, body SessionChatPa
 	return
 }
 
+// Fork a session at a message into a new session
+func (r *SessionService) Fork(ctx context.Context, id string, body SessionForkParams, opts ...option.RequestOption) (res *Session, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/fork", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
 // Analyze the app and create an AGENTS.md file
 func (r *SessionService) Init(ctx context.Context, id string, body SessionInitParams, opts ...option.RequestOption) (res *bool, err error) {
 	opts = append(r.Options[:], opts...)
@@ -124,6 +148,22 @@ func (r *SessionService) Revert(ctx context.Context, id string, body SessionReve
 	return
 }
 
+// Respond to a pending permission request
+func (r *SessionService) RespondPermission(ctx context.Context, id string, permissionID string, body SessionRespondPermissionParams, opts ...option.RequestOption) (res *bool, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	if permissionID == "" {
+		err = errors.New("missing required permissionID parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/permissions/%s", id, permissionID)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
 // Share a session
 func (r *SessionService) Share(ctx context.Context, id string, opts ...option.RequestOption) (res *Session, err error) {
 	opts = append(r.Options[:], opts...)
@@ -2088,6 +2128,14 @@ func (r SessionChatParamsPartsType) IsKnown() bool {
 	return false
 }
 
+type SessionForkParams struct {
+	MessageID param.Field[string] `json:"messageID,required"`
+}
+
+func (r SessionForkParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
 type SessionInitParams struct {
 	MessageID  param.Field[string] `json:"messageID,required"`
 	ModelID    param.Field[string] `json:"modelID,required"`
@@ -2107,6 +2155,30 @@ func (r SessionRevertParams) MarshalJSON() (data []byte, err error) {
 	return apijson.MarshalRoot(r)
 }
 
+type SessionRespondPermissionParams struct {
+	Response param.Field[SessionRespondPermissionParamsResponse] `json:"response,required"`
+}
+
+func (r SessionRespondPermissionParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
+type SessionRespondPermissionParamsResponse string
+
+const (
+	SessionRespondPermissionParamsResponseOnce   SessionRespondPermissionParamsResponse = "once"
+	SessionRespondPermissionParamsResponseAlways SessionRespondPermissionParamsResponse = "always"
+	SessionRespondPermissionParamsResponseReject SessionRespondPermissionParamsResponse = "reject"
+)
+
+func (r SessionRespondPermissionParamsResponse) IsKnown() bool {
+	switch r {
+	case SessionRespondPermissionParamsResponseOnce, SessionRespondPermissionParamsResponseAlways, SessionRespondPermissionParamsResponseReject:
+		return true
+	}
+	return false
+}
+
 type SessionSummarizeParams struct {
 	ModelID    param.Field[string] `json:"modelID,required"`
 	ProviderID param.Field[string] `json:"providerID,required"`
@@ -2115,3 +2187,11 @@ type SessionSummarizeParams struct {
 func (r SessionSummarizeParams) MarshalJSON() (data []byte, err error) {
 	return apijson.MarshalRoot(r)
 }
+
+type SessionUpdateParams struct {
+	Title param.Field[string] `json:"title"`
+}
+
+func (r SessionUpdateParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}